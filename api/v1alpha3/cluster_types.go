@@ -106,10 +106,31 @@ type ClusterStatus struct {
 	// ControlPlaneInitialized defines if the control plane has been initialized.
 	// +optional
 	ControlPlaneInitialized bool `json:"controlPlaneInitialized"`
+
+	// CertificatesExpiry reports when the certificates used to access the cluster expire.
+	// +optional
+	CertificatesExpiry *CertificatesExpiry `json:"certificatesExpiry,omitempty"`
 }
 
 // ANCHOR_END: ClusterStatus
 
+// ANCHOR: CertificatesExpiry
+
+// CertificatesExpiry holds the expiration times of the certificates used to access a Cluster.
+type CertificatesExpiry struct {
+	// ClusterCA is the expiration time of the cluster certificate authority
+	// stored in the <cluster>-ca secret.
+	// +optional
+	ClusterCA *metav1.Time `json:"clusterCA,omitempty"`
+
+	// Kubeconfig is the expiration time of the client certificate
+	// stored in the <cluster>-kubeconfig secret.
+	// +optional
+	Kubeconfig *metav1.Time `json:"kubeconfig,omitempty"`
+}
+
+// ANCHOR_END: CertificatesExpiry
+
 // SetTypedPhase sets the Phase field to the string representation of ClusterPhase.
 func (c *ClusterStatus) SetTypedPhase(p ClusterPhase) {
 	c.Phase = string(p)
@@ -126,3 +126,50 @@ type ObjectMeta struct {
 	// +patchStrategy=merge
 	OwnerReferences []metav1.OwnerReference `json:"ownerReferences,omitempty" patchStrategy:"merge" patchMergeKey:"uid" protobuf:"bytes,13,rep,name=ownerReferences"`
 }
+
+const (
+	// TemplateClonedFromNameAnnotation is set on the objects cloned from an infrastructure or bootstrap template
+	// to the name of the template.
+	TemplateClonedFromNameAnnotation = "cluster.x-k8s.io/cloned-from-name"
+
+	// TemplateClonedFromGenerationAnnotation is set on the objects cloned from an infrastructure or bootstrap
+	// template to the generation of the template they were cloned from.
+	TemplateClonedFromGenerationAnnotation = "cluster.x-k8s.io/cloned-from-generation"
+
+	// TemplateClonedFromHashAnnotation is set on the objects cloned from an infrastructure or bootstrap template
+	// to the hash of the content of the template they were cloned from.
+	TemplateClonedFromHashAnnotation = "cluster.x-k8s.io/cloned-from-hash"
+)
+
+// Annotations of MachineSets and MachineDeployments read by the cluster autoscaler.
+const (
+	// AutoscalerMinSizeAnnotation is the minimum number of replicas the cluster autoscaler may scale
+	// a MachineSet or MachineDeployment down to.
+	AutoscalerMinSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size"
+
+	// AutoscalerMaxSizeAnnotation is the maximum number of replicas the cluster autoscaler may scale
+	// a MachineSet or MachineDeployment up to.
+	AutoscalerMaxSizeAnnotation = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"
+
+	// CapacityCPUAnnotation is the CPU capacity of the Nodes of a MachineSet or MachineDeployment,
+	// which lets the cluster autoscaler scale it up from zero replicas.
+	// It is set by the controllers from the status.capacity field of the infrastructure template.
+	CapacityCPUAnnotation = "capacity.cluster-autoscaler.kubernetes.io/cpu"
+
+	// CapacityMemoryAnnotation is the memory capacity of the Nodes of a MachineSet or MachineDeployment.
+	// It is set by the controllers from the status.capacity field of the infrastructure template.
+	CapacityMemoryAnnotation = "capacity.cluster-autoscaler.kubernetes.io/memory"
+
+	// CapacityGPUCountAnnotation is the number of GPUs of the Nodes of a MachineSet or MachineDeployment.
+	// It is set by the controllers from the resources of the status.capacity field of the infrastructure
+	// template whose name ends with "gpu", such as nvidia.com/gpu.
+	CapacityGPUCountAnnotation = "capacity.cluster-autoscaler.kubernetes.io/gpu-count"
+
+	// CapacityLabelsAnnotation lists the labels of the Nodes of a MachineSet or MachineDeployment,
+	// as comma separated key=value pairs.
+	CapacityLabelsAnnotation = "capacity.cluster-autoscaler.kubernetes.io/labels"
+
+	// CapacityTaintsAnnotation lists the taints of the Nodes of a MachineSet or MachineDeployment,
+	// as comma separated key=value:Effect entries.
+	CapacityTaintsAnnotation = "capacity.cluster-autoscaler.kubernetes.io/taints"
+)
@@ -34,6 +34,26 @@ const (
 
 	// ExcludeNodeDrainingAnnotation annotation explicitly skips node draining if set
 	ExcludeNodeDrainingAnnotation = "machine.cluster.x-k8s.io.io/exclude-node-draining"
+
+	// ManagedLabelsAnnotation is set on Machines to the comma separated keys of the labels
+	// set from the template of their MachineSet.
+	ManagedLabelsAnnotation = "cluster.x-k8s.io/managed-labels"
+
+	// ManagedAnnotationsAnnotation is set on Machines to the comma separated keys of the annotations
+	// set from the template of their MachineSet.
+	ManagedAnnotationsAnnotation = "cluster.x-k8s.io/managed-annotations"
+
+	// ManagedNodeLabelsAnnotation is set on Nodes to the comma separated keys of the labels
+	// synced from the NodeLabels of their Machine.
+	ManagedNodeLabelsAnnotation = "cluster.x-k8s.io/managed-node-labels"
+
+	// ManagedNodeAnnotationsAnnotation is set on Nodes to the comma separated keys of the annotations
+	// synced from the NodeAnnotations of their Machine.
+	ManagedNodeAnnotationsAnnotation = "cluster.x-k8s.io/managed-node-annotations"
+
+	// ManagedNodeTaintsAnnotation is set on Nodes to the comma separated key:Effect pairs of the taints
+	// synced from the NodeTaints of their Machine.
+	ManagedNodeTaintsAnnotation = "cluster.x-k8s.io/managed-node-taints"
 )
 
 // ANCHOR: MachineSpec
@@ -66,6 +86,25 @@ type MachineSpec struct {
 	// be interfacing with cluster-api as generic provider.
 	// +optional
 	ProviderID *string `json:"providerID,omitempty"`
+
+	// NodeLabels are labels kept in sync on the Node of the Machine once it has joined the cluster.
+	// Labels removed from this field are removed from the Node, other labels of the Node are left untouched.
+	// Changing this field on a MachineDeployment or MachineSet updates existing Machines in place.
+	// +optional
+	NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+
+	// NodeAnnotations are annotations kept in sync on the Node of the Machine once it has joined the cluster.
+	// Annotations removed from this field are removed from the Node, other annotations of the Node are left untouched.
+	// Changing this field on a MachineDeployment or MachineSet updates existing Machines in place.
+	// +optional
+	NodeAnnotations map[string]string `json:"nodeAnnotations,omitempty"`
+
+	// NodeTaints are taints kept in sync on the Node of the Machine once it has joined the cluster.
+	// Taints are identified by their key and effect. Taints removed from this field are removed from the Node,
+	// other taints of the Node are left untouched.
+	// Changing this field on a MachineDeployment or MachineSet updates existing Machines in place.
+	// +optional
+	NodeTaints []corev1.Taint `json:"nodeTaints,omitempty"`
 }
 
 // ANCHOR_END: MachineSpec
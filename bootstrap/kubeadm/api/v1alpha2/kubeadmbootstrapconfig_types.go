@@ -60,8 +60,24 @@ type KubeadmConfigSpec struct {
 	// Format specifies the output format of the bootstrap data
 	// +optional
 	Format Format `json:"format,omitempty"`
+	// CertificatesKeyAlgorithm specifies the key algorithm of the certificate authorities generated for the cluster.
+	// Defaults to RSA. It is only used by the first control plane machine, when the certificate authorities are generated.
+	// +optional
+	CertificatesKeyAlgorithm KeyAlgorithm `json:"certificatesKeyAlgorithm,omitempty"`
 }
 
+// KeyAlgorithm specifies the algorithm of a generated private key.
+// +kubebuilder:validation:Enum=RSA;ECDSA-P256
+type KeyAlgorithm string
+
+const (
+	// RSA generates 2048 bit RSA keys.
+	RSA KeyAlgorithm = "RSA"
+
+	// ECDSAP256 generates ECDSA keys on the NIST P-256 curve.
+	ECDSAP256 KeyAlgorithm = "ECDSA-P256"
+)
+
 // KubeadmConfigStatus defines the observed state of KubeadmConfig
 type KubeadmConfigStatus struct {
 	// Ready indicates the BootstrapData field is ready to be consumed
@@ -138,7 +154,30 @@ type File struct {
 	Encoding Encoding `json:"encoding,omitempty"`
 
 	// Content is the actual content of the file.
-	Content string `json:"content"`
+	// +optional
+	Content string `json:"content,omitempty"`
+
+	// ContentFrom is a referenced source of content to populate the file.
+	// Content and ContentFrom are mutually exclusive.
+	// +optional
+	ContentFrom *FileSource `json:"contentFrom,omitempty"`
+}
+
+// FileSource is a union of all possible external source types for file data.
+// Only one field may be populated in any given instance.
+type FileSource struct {
+	// Secret represents a secret that should populate this file.
+	Secret SecretFileSource `json:"secret"`
+}
+
+// SecretFileSource adapts a Secret into a FileSource.
+// The value stored under Key in the Secret's Data is used as the file content.
+type SecretFileSource struct {
+	// Name of the secret in the KubeadmConfig's namespace to use.
+	Name string `json:"name"`
+
+	// Key is the key in the secret's data map for this value.
+	Key string `json:"key"`
 }
 
 // User defines the input for a generated user in cloud-init.
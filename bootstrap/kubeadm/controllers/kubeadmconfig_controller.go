@@ -24,12 +24,14 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha2"
 	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/cloudinit"
 	internalcluster "sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/cluster"
+	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/tracing"
 	kubeadmv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/kubeadm/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	capierrors "sigs.k8s.io/cluster-api/errors"
@@ -82,6 +84,12 @@ func (r *KubeadmConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				ToRequests: handler.ToRequestsFunc(r.ClusterToKubeadmConfigs),
 			},
 		).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(r.SecretToKubeadmConfigs),
+			},
+		).
 		Complete(r)
 }
 
@@ -100,6 +108,10 @@ func (r *KubeadmConfigReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 		return ctrl.Result{}, err
 	}
 
+	// The span is linked to the reconcile of the Machine which claimed the config, if it was traced.
+	ctx, span := tracing.Link(ctx, "KubeadmConfigReconciler.Reconcile", config)
+	defer func() { tracing.End(ctx, span, rerr) }()
+
 	// Look up the Machine that owns this KubeConfig if there is one
 	machine, err := util.GetOwnerMachine(ctx, r.Client, config.ObjectMeta)
 	if err != nil {
@@ -250,15 +262,30 @@ func (r *KubeadmConfigReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 			return ctrl.Result{}, err
 		}
 
+		if err := internalcluster.ValidateExternalEtcd(config.Spec.ClusterConfiguration.Etcd.External); err != nil {
+			log.Error(err, "invalid external etcd configuration")
+			return ctrl.Result{}, err
+		}
+
 		certificates := internalcluster.NewCertificatesForInitialControlPlane(config.Spec.ClusterConfiguration)
 		if err := certificates.LookupOrGenerate(ctx, r.Client, cluster, config); err != nil {
 			log.Error(err, "unable to lookup or create cluster certificates")
 			return ctrl.Result{}, err
 		}
+		if err := certificates.Validate(); err != nil {
+			log.Error(err, "invalid cluster certificates")
+			return ctrl.Result{}, err
+		}
+
+		files, err := r.resolveFiles(ctx, config)
+		if err != nil {
+			log.Error(err, "failed to resolve files")
+			return ctrl.Result{}, err
+		}
 
 		cloudInitData, err := cloudinit.NewInitControlPlane(&cloudinit.ControlPlaneInput{
 			BaseUserData: cloudinit.BaseUserData{
-				AdditionalFiles:     config.Spec.Files,
+				AdditionalFiles:     files,
 				NTP:                 config.Spec.NTP,
 				PreKubeadmCommands:  config.Spec.PreKubeadmCommands,
 				PostKubeadmCommands: config.Spec.PostKubeadmCommands,
@@ -305,6 +332,10 @@ func (r *KubeadmConfigReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 		if err := certificates.EnsureAllExist(); err != nil {
 			return ctrl.Result{}, err
 		}
+		if err := certificates.Validate(); err != nil {
+			log.Error(err, "invalid cluster certificates")
+			return ctrl.Result{}, err
+		}
 
 		// ensure that joinConfiguration.Discovery is properly set for joining node on the current cluster
 		if err := r.reconcileDiscovery(cluster, config, certificates); err != nil {
@@ -321,12 +352,18 @@ func (r *KubeadmConfigReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 			return ctrl.Result{}, err
 		}
 
+		files, err := r.resolveFiles(ctx, config)
+		if err != nil {
+			log.Error(err, "failed to resolve files")
+			return ctrl.Result{}, err
+		}
+
 		log.Info("Creating BootstrapData for the join control plane")
 		cloudJoinData, err := cloudinit.NewJoinControlPlane(&cloudinit.ControlPlaneJoinInput{
 			JoinConfiguration: joinData,
 			Certificates:      certificates,
 			BaseUserData: cloudinit.BaseUserData{
-				AdditionalFiles:     config.Spec.Files,
+				AdditionalFiles:     files,
 				NTP:                 config.Spec.NTP,
 				PreKubeadmCommands:  config.Spec.PreKubeadmCommands,
 				PostKubeadmCommands: config.Spec.PostKubeadmCommands,
@@ -353,6 +390,10 @@ func (r *KubeadmConfigReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 		log.Error(err, "Missing certificates")
 		return ctrl.Result{}, err
 	}
+	if err := certificates.Validate(); err != nil {
+		log.Error(err, "invalid cluster certificates")
+		return ctrl.Result{}, err
+	}
 
 	// ensure that joinConfiguration.Discovery is properly set for joining node on the current cluster
 	if err := r.reconcileDiscovery(cluster, config, certificates); err != nil {
@@ -373,11 +414,17 @@ func (r *KubeadmConfigReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 		return ctrl.Result{}, errors.New("Machine is a Worker, but JoinConfiguration.ControlPlane is set in the KubeadmConfig object")
 	}
 
+	files, err := r.resolveFiles(ctx, config)
+	if err != nil {
+		log.Error(err, "failed to resolve files")
+		return ctrl.Result{}, err
+	}
+
 	log.Info("Creating BootstrapData for the worker node")
 
 	cloudJoinData, err := cloudinit.NewNode(&cloudinit.NodeInput{
 		BaseUserData: cloudinit.BaseUserData{
-			AdditionalFiles:     config.Spec.Files,
+			AdditionalFiles:     files,
 			NTP:                 config.Spec.NTP,
 			PreKubeadmCommands:  config.Spec.PreKubeadmCommands,
 			PostKubeadmCommands: config.Spec.PostKubeadmCommands,
@@ -445,6 +492,76 @@ func (r *KubeadmConfigReconciler) MachineToBootstrapMapFunc(o handler.MapObject)
 	return result
 }
 
+// SecretToKubeadmConfigs is a handler.ToRequestsFunc to be used to enqeue
+// requests for reconciliation of KubeadmConfigs with files sourced from the Secret.
+func (r *KubeadmConfigReconciler) SecretToKubeadmConfigs(o handler.MapObject) []ctrl.Request {
+	result := []ctrl.Request{}
+
+	s, ok := o.Object.(*corev1.Secret)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a Secret but got a %T", o.Object), "failed to get KubeadmConfigs for Secret")
+		return nil
+	}
+
+	configList := &bootstrapv1.KubeadmConfigList{}
+	if err := r.List(context.Background(), configList, client.InNamespace(s.Namespace)); err != nil {
+		r.Log.Error(err, "failed to list KubeadmConfigs", "Secret", s.Name, "Namespace", s.Namespace)
+		return nil
+	}
+
+	for _, c := range configList.Items {
+		for _, f := range c.Spec.Files {
+			if f.ContentFrom != nil && f.ContentFrom.Secret.Name == s.Name {
+				name := client.ObjectKey{Namespace: c.Namespace, Name: c.Name}
+				result = append(result, ctrl.Request{NamespacedName: name})
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// resolveFiles returns the files of the KubeadmConfig with any content sourced from Secrets
+// resolved inline, ready to be rendered into the bootstrap data.
+// The KubeadmConfig itself is left untouched, so secret values never end up in its spec.
+func (r *KubeadmConfigReconciler) resolveFiles(ctx context.Context, config *bootstrapv1.KubeadmConfig) ([]bootstrapv1.File, error) {
+	files := make([]bootstrapv1.File, 0, len(config.Spec.Files))
+	for i := range config.Spec.Files {
+		file := config.Spec.Files[i]
+		if file.ContentFrom != nil {
+			if file.Content != "" {
+				return nil, errors.Errorf("file %q must specify only one of content or contentFrom", file.Path)
+			}
+			data, err := r.resolveSecretFileContent(ctx, config.Namespace, file)
+			if err != nil {
+				return nil, err
+			}
+			file.Content = string(data)
+			file.ContentFrom = nil
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// resolveSecretFileContent returns the content of the Secret key referenced by the file.
+func (r *KubeadmConfigReconciler) resolveSecretFileContent(ctx context.Context, ns string, source bootstrapv1.File) ([]byte, error) {
+	s := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: ns, Name: source.ContentFrom.Secret.Name}
+	if err := r.Get(ctx, key, s); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "secret not found for file %q: %s", source.Path, key)
+		}
+		return nil, errors.Wrapf(err, "failed to retrieve Secret %s for file %q", key, source.Path)
+	}
+	data, ok := s.Data[source.ContentFrom.Secret.Key]
+	if !ok {
+		return nil, errors.Errorf("secret %s references non-existent key %q for file %q", key, source.ContentFrom.Secret.Key, source.Path)
+	}
+	return data, nil
+}
+
 // reconcileDiscovery ensures that config.JoinConfiguration.Discovery is properly set for the joining node.
 // The implementation func respect user provided discovery configurations, but in case some of them are missing, a valid BootstrapToken object
 // is automatically injected into config.JoinConfiguration.Discovery.
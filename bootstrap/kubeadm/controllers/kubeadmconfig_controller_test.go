@@ -1236,16 +1236,12 @@ func TestKubeadmConfigReconciler_Reconcile_DoesNotFailIfCASecretsAlreadyExist(t
 	m := newControlPlaneMachine(cluster, "control-plane-machine")
 	configName := "my-config"
 	c := newControlPlaneInitKubeadmConfig(m, configName)
-	scrt := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-%s", cluster.Name, internalcluster.EtcdCA),
-			Namespace: "default",
-		},
-		Data: map[string][]byte{
-			"tls.crt": []byte("hello world"),
-			"tls.key": []byte("hello world"),
-		},
+	certificates := internalcluster.Certificates{&internalcluster.Certificate{Purpose: internalcluster.EtcdCA}}
+	if err := certificates.Generate(); err != nil {
+		t.Fatal(err)
 	}
+	scrt := certificates.GetByPurpose(internalcluster.EtcdCA).AsSecret(cluster, c)
+	scrt.OwnerReferences = nil
 	fakec := fake.NewFakeClientWithScheme(setupScheme(), []runtime.Object{cluster, m, c, scrt}...)
 	reconciler := &KubeadmConfigReconciler{
 		Log:             log.Log,
@@ -1382,6 +1378,157 @@ func TestKubeadmConfigReconciler_Reconcile_DoNotPatchWhenErrorOccurred(t *testin
 	}
 }
 
+// Files sourced from Secrets are resolved inline without modifying the KubeadmConfig
+func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
+	testSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "source",
+		},
+		Data: map[string][]byte{
+			"key": []byte("foo"),
+		},
+	}
+
+	cases := map[string]struct {
+		files     []bootstrapv1.File
+		objects   []runtime.Object
+		expectErr bool
+		expect    []bootstrapv1.File
+	}{
+		"content only": {
+			files:  []bootstrapv1.File{{Path: "/tmp/inline", Content: "bar"}},
+			expect: []bootstrapv1.File{{Path: "/tmp/inline", Content: "bar"}},
+		},
+		"content from secret": {
+			files: []bootstrapv1.File{
+				{Path: "/tmp/inline", Content: "bar"},
+				{
+					Path:        "/tmp/secret",
+					Permissions: "0600",
+					ContentFrom: &bootstrapv1.FileSource{
+						Secret: bootstrapv1.SecretFileSource{Name: "source", Key: "key"},
+					},
+				},
+			},
+			objects: []runtime.Object{testSecret},
+			expect: []bootstrapv1.File{
+				{Path: "/tmp/inline", Content: "bar"},
+				{Path: "/tmp/secret", Permissions: "0600", Content: "foo"},
+			},
+		},
+		"missing secret": {
+			files: []bootstrapv1.File{
+				{
+					Path: "/tmp/secret",
+					ContentFrom: &bootstrapv1.FileSource{
+						Secret: bootstrapv1.SecretFileSource{Name: "source", Key: "key"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"missing key": {
+			files: []bootstrapv1.File{
+				{
+					Path: "/tmp/secret",
+					ContentFrom: &bootstrapv1.FileSource{
+						Secret: bootstrapv1.SecretFileSource{Name: "source", Key: "nope"},
+					},
+				},
+			},
+			objects:   []runtime.Object{testSecret},
+			expectErr: true,
+		},
+		"both content and content from": {
+			files: []bootstrapv1.File{
+				{
+					Path:    "/tmp/secret",
+					Content: "bar",
+					ContentFrom: &bootstrapv1.FileSource{
+						Secret: bootstrapv1.SecretFileSource{Name: "source", Key: "key"},
+					},
+				},
+			},
+			objects:   []runtime.Object{testSecret},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			config := &bootstrapv1.KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cfg"},
+				Spec:       bootstrapv1.KubeadmConfigSpec{Files: tc.files},
+			}
+			original := config.DeepCopy()
+
+			k := &KubeadmConfigReconciler{
+				Log:    log.Log,
+				Client: fake.NewFakeClientWithScheme(setupScheme(), tc.objects...),
+			}
+
+			files, err := k.resolveFiles(context.Background(), config)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to resolve files:\n %+v", err)
+			}
+			if !reflect.DeepEqual(files, tc.expect) {
+				t.Fatalf("expected files %v, got %v", tc.expect, files)
+			}
+			if !reflect.DeepEqual(config, original) {
+				t.Fatal("did not expect resolving files to modify the KubeadmConfig")
+			}
+		})
+	}
+}
+
+// SecretToKubeadmConfigs returns only the configs with files sourced from the Secret
+func TestKubeadmConfigReconciler_SecretToKubeadmConfigs(t *testing.T) {
+	referencing := &bootstrapv1.KubeadmConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "referencing"},
+		Spec: bootstrapv1.KubeadmConfigSpec{
+			Files: []bootstrapv1.File{
+				{
+					Path: "/tmp/secret",
+					ContentFrom: &bootstrapv1.FileSource{
+						Secret: bootstrapv1.SecretFileSource{Name: "source", Key: "key"},
+					},
+				},
+			},
+		},
+	}
+	other := &bootstrapv1.KubeadmConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"},
+		Spec: bootstrapv1.KubeadmConfigSpec{
+			Files: []bootstrapv1.File{{Path: "/tmp/inline", Content: "bar"}},
+		},
+	}
+	fakeClient := fake.NewFakeClientWithScheme(setupScheme(), referencing, other)
+	reconciler := &KubeadmConfigReconciler{
+		Log:    log.Log,
+		Client: fakeClient,
+	}
+
+	o := handler.MapObject{
+		Object: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "source"},
+		},
+	}
+	configs := reconciler.SecretToKubeadmConfigs(o)
+	if len(configs) != 1 {
+		t.Fatalf("expected 1 config, got %d", len(configs))
+	}
+	if configs[0].Name != referencing.Name {
+		t.Fatalf("expected config %q, got %q", referencing.Name, configs[0].Name)
+	}
+}
+
 // test utils
 
 // newCluster return a CAPI cluster object
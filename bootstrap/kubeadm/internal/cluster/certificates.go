@@ -18,12 +18,17 @@ package cluster
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/hex"
+	"encoding/pem"
 	"math/big"
 	"path/filepath"
 	"strings"
@@ -34,6 +39,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha2"
 	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/kubeadm/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
@@ -69,6 +75,15 @@ var (
 
 	// ErrMissingKey is an error indicating the key file is missing from the certificate
 	ErrMissingKey = errors.New("missing key data")
+
+	// ErrCertificateExpired is an error indicating the certificate is expired or not yet valid
+	ErrCertificateExpired = errors.New("certificate is expired or not yet valid")
+
+	// ErrNotCA is an error indicating a certificate is not a certificate authority
+	ErrNotCA = errors.New("certificate is not a certificate authority")
+
+	// ErrKeyMismatch is an error indicating the private key does not match the certificate
+	ErrKeyMismatch = errors.New("private key does not match certificate")
 )
 
 // Certificates are the certificates necessary to bootstrap a cluster.
@@ -104,16 +119,18 @@ func NewCertificatesForInitialControlPlane(config *v1beta1.ClusterConfiguration)
 		KeyFile:  filepath.Join(config.CertificatesDir, "etcd", "ca.key"),
 	}
 
-	// TODO make sure all the fields are actually defined and return an error if not
+	// the external etcd configuration is checked by ValidateExternalEtcd
 	if config.Etcd.External != nil {
 		etcdCert = &Certificate{
 			Purpose:  EtcdCA,
 			CertFile: config.Etcd.External.CAFile,
+			External: true,
 		}
 		apiserverEtcdClientCert := &Certificate{
 			Purpose:  APIServerEtcdClient,
 			CertFile: config.Etcd.External.CertFile,
 			KeyFile:  config.Etcd.External.KeyFile,
+			External: true,
 		}
 		certificates = append(certificates, apiserverEtcdClientCert)
 	}
@@ -162,6 +179,27 @@ func NewCertificatesForWorker(caCertPath string) Certificates {
 	}
 }
 
+// ValidateExternalEtcd checks that the external etcd configuration defines everything required
+// to write the user supplied certificates to disk and connect to the etcd cluster.
+func ValidateExternalEtcd(external *v1beta1.ExternalEtcd) error {
+	if external == nil {
+		return nil
+	}
+	if len(external.Endpoints) == 0 {
+		return errors.New("external etcd must define at least one endpoint")
+	}
+	if external.CAFile == "" {
+		return errors.New("external etcd must define caFile")
+	}
+	if external.CertFile == "" {
+		return errors.New("external etcd must define certFile")
+	}
+	if external.KeyFile == "" {
+		return errors.New("external etcd must define keyFile")
+	}
+	return nil
+}
+
 // GetByPurpose returns a certificate by the given name.
 // This could be removed if we use a map instead of a slice to hold certificates, however other code becomes more complex.
 func (c Certificates) GetByPurpose(purpose secret.Purpose) *Certificate {
@@ -214,24 +252,45 @@ func (c Certificates) EnsureAllExist() error {
 	return nil
 }
 
+// Validate checks every certificate that was not generated by CABPK, i.e. the ones provided by the user as secrets.
+// Certificates that must be supplied by the user are required to exist.
+func (c Certificates) Validate() error {
+	for _, certificate := range c {
+		if certificate.KeyPair == nil {
+			if certificate.External {
+				return errors.Wrapf(ErrMissingCertificate, "for user supplied certificate: %s", certificate.Purpose)
+			}
+			continue
+		}
+		if certificate.Generated {
+			continue
+		}
+		if err := certificate.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid certificate: %s", certificate.Purpose)
+		}
+	}
+	return nil
+}
+
 // TODO: consider moving a generating function into the Certificate object itself?
-type certGenerator func() (*certs.KeyPair, error)
+type certGenerator func(algorithm bootstrapv1.KeyAlgorithm) (*certs.KeyPair, error)
 
 // Generate will generate any certificates that do not have KeyPair data.
 func (c Certificates) Generate() error {
 	for _, certificate := range c {
 		if certificate.KeyPair == nil {
 			var generator certGenerator
-			switch certificate.Purpose {
-			case APIServerEtcdClient: // Do not generate the APIServerEtcdClient key pair. It is user supplied
+			if certificate.External { // Do not generate key pairs for external etcd. They are user supplied
 				continue
+			}
+			switch certificate.Purpose {
 			case ServiceAccount:
 				generator = generateServiceAccountKeys
 			default:
 				generator = generateCACert
 			}
 
-			kp, err := generator()
+			kp, err := generator(certificate.KeyAlgorithm)
 			if err != nil {
 				return err
 			}
@@ -263,6 +322,13 @@ func (c Certificates) LookupOrGenerate(ctx context.Context, ctrlclient client.Cl
 		return err
 	}
 
+	// Use the key algorithm requested by the config for any certificate that does not specify one
+	for _, certificate := range c {
+		if certificate.KeyAlgorithm == "" {
+			certificate.KeyAlgorithm = config.Spec.CertificatesKeyAlgorithm
+		}
+	}
+
 	// Generate the certificates that don't exist
 	if err := c.Generate(); err != nil {
 		return err
@@ -279,11 +345,74 @@ func (c Certificates) LookupOrGenerate(ctx context.Context, ctrlclient client.Cl
 // Certificate represents a single certificate CA.
 type Certificate struct {
 	Generated         bool
+	External          bool
 	Purpose           secret.Purpose
 	KeyPair           *certs.KeyPair
+	KeyAlgorithm      bootstrapv1.KeyAlgorithm
 	CertFile, KeyFile string
 }
 
+// Validate checks that the certificate is currently valid, that CA certificates can sign other certificates
+// and that the private key, when present, matches the certificate.
+func (c *Certificate) Validate() error {
+	if c.Purpose == ServiceAccount {
+		return validateServiceAccountKeys(c.KeyPair)
+	}
+
+	certificates, err := cert.ParseCertsPEM(c.KeyPair.Cert)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse certificate")
+	}
+	leaf := certificates[0]
+
+	now := time.Now()
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return errors.Wrapf(ErrCertificateExpired, "valid from %s until %s", leaf.NotBefore, leaf.NotAfter)
+	}
+
+	// APIServerEtcdClient is the only client certificate, all the others are CAs
+	if c.Purpose != APIServerEtcdClient {
+		if !leaf.BasicConstraintsValid || !leaf.IsCA {
+			return ErrNotCA
+		}
+		if leaf.KeyUsage&x509.KeyUsageCertSign == 0 {
+			return errors.Wrap(ErrNotCA, "missing cert sign key usage")
+		}
+	}
+
+	if len(c.KeyPair.Key) == 0 {
+		if c.Purpose == EtcdCA && c.External {
+			return nil
+		}
+		return ErrMissingKey
+	}
+	if _, err := tls.X509KeyPair(c.KeyPair.Cert, c.KeyPair.Key); err != nil {
+		return errors.Wrap(ErrKeyMismatch, err.Error())
+	}
+	return nil
+}
+
+// validateServiceAccountKeys checks that the service account private key matches the public key.
+func validateServiceAccountKeys(kp *certs.KeyPair) error {
+	pubs, err := keyutil.ParsePublicKeysPEM(kp.Cert)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse public key")
+	}
+	priv, err := keyutil.ParsePrivateKeyPEM(kp.Key)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse private key")
+	}
+	rsaPriv, ok := priv.(*rsa.PrivateKey)
+	if !ok {
+		return errors.Errorf("unsupported private key type %T", priv)
+	}
+	rsaPub, ok := pubs[0].(*rsa.PublicKey)
+	if !ok || rsaPub.N.Cmp(rsaPriv.N) != 0 || rsaPub.E != rsaPriv.E {
+		return ErrKeyMismatch
+	}
+	return nil
+}
+
 // Hashes hashes all the certificates stored in a CA certificate.
 func (c *Certificate) Hashes() ([]string, error) {
 	certificates, err := cert.ParseCertsPEM(c.KeyPair.Cert)
@@ -403,18 +532,23 @@ func secretToKeyPair(s *corev1.Secret) (*certs.KeyPair, error) {
 	}, nil
 }
 
-func generateCACert() (*certs.KeyPair, error) {
-	x509Cert, privKey, err := newCertificateAuthority()
+func generateCACert(algorithm bootstrapv1.KeyAlgorithm) (*certs.KeyPair, error) {
+	x509Cert, privKey, err := newCertificateAuthority(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	key, err := encodePrivateKeyPEM(privKey)
 	if err != nil {
 		return nil, err
 	}
 	return &certs.KeyPair{
 		Cert: certs.EncodeCertPEM(x509Cert),
-		Key:  certs.EncodePrivateKeyPEM(privKey),
+		Key:  key,
 	}, nil
 }
 
-func generateServiceAccountKeys() (*certs.KeyPair, error) {
+// generateServiceAccountKeys always generates RSA keys, the only ones supported by kubeadm for service accounts.
+func generateServiceAccountKeys(_ bootstrapv1.KeyAlgorithm) (*certs.KeyPair, error) {
 	saCreds, err := certs.NewPrivateKey()
 	if err != nil {
 		return nil, err
@@ -429,9 +563,38 @@ func generateServiceAccountKeys() (*certs.KeyPair, error) {
 	}, nil
 }
 
+// newPrivateKey creates a private key using the given algorithm, defaulting to RSA.
+func newPrivateKey(algorithm bootstrapv1.KeyAlgorithm) (crypto.Signer, error) {
+	switch algorithm {
+	case "", bootstrapv1.RSA:
+		return certs.NewPrivateKey()
+	case bootstrapv1.ECDSAP256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		return key, errors.WithStack(err)
+	default:
+		return nil, errors.Errorf("unsupported key algorithm %q", algorithm)
+	}
+}
+
+// encodePrivateKeyPEM returns PEM-encoded private key data, using PKCS#1 for RSA and SEC 1 for ECDSA keys.
+func encodePrivateKeyPEM(key crypto.Signer) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return certs.EncodePrivateKeyPEM(k), nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+	default:
+		return nil, errors.Errorf("unsupported private key type %T", key)
+	}
+}
+
 // newCertificateAuthority creates new certificate and private key for the certificate authority
-func newCertificateAuthority() (*x509.Certificate, *rsa.PrivateKey, error) {
-	key, err := certs.NewPrivateKey()
+func newCertificateAuthority(algorithm bootstrapv1.KeyAlgorithm) (*x509.Certificate, crypto.Signer, error) {
+	key, err := newPrivateKey(algorithm)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -445,7 +608,7 @@ func newCertificateAuthority() (*x509.Certificate, *rsa.PrivateKey, error) {
 }
 
 // newSelfSignedCACert creates a CA certificate.
-func newSelfSignedCACert(key *rsa.PrivateKey) (*x509.Certificate, error) {
+func newSelfSignedCACert(key crypto.Signer) (*x509.Certificate, error) {
 	cfg := certs.Config{
 		CommonName: "kubernetes",
 	}
@@ -460,13 +623,18 @@ func newSelfSignedCACert(key *rsa.PrivateKey) (*x509.Certificate, error) {
 		},
 		NotBefore:             now.Add(time.Minute * -5),
 		NotAfter:              now.Add(time.Hour * 24 * 365 * 10), // 10 years
-		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
 		MaxPathLenZero:        true,
 		BasicConstraintsValid: true,
 		MaxPathLen:            0,
 		IsCA:                  true,
 	}
 
+	// Key encipherment is only meaningful for RSA keys.
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
 	b, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, key.Public(), key)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create self signed CA certificate: %+v", tmpl)
@@ -17,9 +17,18 @@ limitations under the License.
 package cluster
 
 import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha2"
 	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/kubeadm/v1beta1"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/secret"
 )
 
 func TestNewCertificatesForControlPlane_Stacked(t *testing.T) {
@@ -42,3 +51,183 @@ func TestNewCertificatesForControlPlane_External(t *testing.T) {
 		t.Fatal("control planes with external etcd must *not* define the etcd key file")
 	}
 }
+
+func TestNewCertificatesForControlPlane_ExternalIsNotGenerated(t *testing.T) {
+	config := &v1beta1.ClusterConfiguration{
+		Etcd: v1beta1.Etcd{
+			External: &v1beta1.ExternalEtcd{},
+		},
+	}
+
+	certificates := NewCertificatesForInitialControlPlane(config)
+	if err := certificates.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if certificates.GetByPurpose(EtcdCA).KeyPair != nil {
+		t.Fatal("the external etcd CA must *not* be generated")
+	}
+	if err := certificates.Validate(); errors.Cause(err) != ErrMissingCertificate {
+		t.Fatalf("expected missing certificate error, got %v", err)
+	}
+}
+
+func TestValidateExternalEtcd(t *testing.T) {
+	valid := v1beta1.ExternalEtcd{
+		Endpoints: []string{"https://10.0.0.230:2379"},
+		CAFile:    "/etc/kubernetes/pki/etcd/ca.crt",
+		CertFile:  "/etc/kubernetes/pki/apiserver-etcd-client.crt",
+		KeyFile:   "/etc/kubernetes/pki/apiserver-etcd-client.key",
+	}
+
+	cases := map[string]struct {
+		mutate    func(*v1beta1.ExternalEtcd)
+		expectErr bool
+	}{
+		"valid":            {mutate: func(*v1beta1.ExternalEtcd) {}},
+		"missing endpoint": {mutate: func(e *v1beta1.ExternalEtcd) { e.Endpoints = nil }, expectErr: true},
+		"missing ca file":  {mutate: func(e *v1beta1.ExternalEtcd) { e.CAFile = "" }, expectErr: true},
+		"missing cert":     {mutate: func(e *v1beta1.ExternalEtcd) { e.CertFile = "" }, expectErr: true},
+		"missing key":      {mutate: func(e *v1beta1.ExternalEtcd) { e.KeyFile = "" }, expectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			external := valid
+			tc.mutate(&external)
+			err := ValidateExternalEtcd(&external)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestCertificateValidate(t *testing.T) {
+	ca, err := generateCACert(bootstrapv1.RSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCA, err := generateCACert(bootstrapv1.RSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sa, err := generateServiceAccountKeys(bootstrapv1.RSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSA, err := generateServiceAccountKeys(bootstrapv1.RSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	expiredCA := newTestKeyPair(t, now.Add(-48*time.Hour), now.Add(-24*time.Hour), true)
+	client := newTestKeyPair(t, now.Add(-time.Hour), now.Add(24*time.Hour), false)
+
+	cases := map[string]struct {
+		certificate *Certificate
+		expectErr   error
+	}{
+		"valid CA": {
+			certificate: &Certificate{Purpose: secret.ClusterCA, KeyPair: ca},
+		},
+		"CA with mismatched key": {
+			certificate: &Certificate{Purpose: secret.ClusterCA, KeyPair: &certs.KeyPair{Cert: ca.Cert, Key: otherCA.Key}},
+			expectErr:   ErrKeyMismatch,
+		},
+		"CA without key": {
+			certificate: &Certificate{Purpose: FrontProxyCA, KeyPair: &certs.KeyPair{Cert: ca.Cert}},
+			expectErr:   ErrMissingKey,
+		},
+		"external etcd CA without key": {
+			certificate: &Certificate{Purpose: EtcdCA, External: true, KeyPair: &certs.KeyPair{Cert: ca.Cert}},
+		},
+		"expired CA": {
+			certificate: &Certificate{Purpose: secret.ClusterCA, KeyPair: expiredCA},
+			expectErr:   ErrCertificateExpired,
+		},
+		"client certificate used as CA": {
+			certificate: &Certificate{Purpose: secret.ClusterCA, KeyPair: client},
+			expectErr:   ErrNotCA,
+		},
+		"apiserver etcd client certificate": {
+			certificate: &Certificate{Purpose: APIServerEtcdClient, External: true, KeyPair: client},
+		},
+		"valid service account keys": {
+			certificate: &Certificate{Purpose: ServiceAccount, KeyPair: sa},
+		},
+		"mismatched service account keys": {
+			certificate: &Certificate{Purpose: ServiceAccount, KeyPair: &certs.KeyPair{Cert: sa.Cert, Key: otherSA.Key}},
+			expectErr:   ErrKeyMismatch,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.certificate.Validate()
+			if errors.Cause(err) != tc.expectErr {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestGenerate_KeyAlgorithm(t *testing.T) {
+	for _, algorithm := range []bootstrapv1.KeyAlgorithm{bootstrapv1.RSA, bootstrapv1.ECDSAP256} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			certificates := NewCertificatesForInitialControlPlane(&v1beta1.ClusterConfiguration{})
+			for _, certificate := range certificates {
+				certificate.KeyAlgorithm = algorithm
+			}
+			if err := certificates.Generate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := certificates.Validate(); err != nil {
+				t.Fatalf("expected generated certificates to be valid, got %v", err)
+			}
+
+			ca, err := certs.DecodeCertPEM(certificates.GetByPurpose(secret.ClusterCA).KeyPair.Cert)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := x509.RSA
+			if algorithm == bootstrapv1.ECDSAP256 {
+				expected = x509.ECDSA
+			}
+			if ca.PublicKeyAlgorithm != expected {
+				t.Fatalf("expected CA public key algorithm %s, got %s", expected, ca.PublicKeyAlgorithm)
+			}
+		})
+	}
+}
+
+func newTestKeyPair(t *testing.T, notBefore, notAfter time.Time, isCA bool) *certs.KeyPair {
+	key, err := certs.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber:          new(big.Int).SetInt64(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	}
+	b, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &certs.KeyPair{
+		Cert: certs.EncodeCertPEM(c),
+		Key:  certs.EncodePrivateKeyPEM(key),
+	}
+}
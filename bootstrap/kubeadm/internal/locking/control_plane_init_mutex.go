@@ -20,12 +20,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	apicorev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -34,15 +36,24 @@ const semaphoreInformationKey = "lock-information"
 
 // ControlPlaneInitMutex uses a ConfigMap to synchronize cluster initialization.
 type ControlPlaneInitMutex struct {
-	log    logr.Logger
-	client client.Client
+	log      logr.Logger
+	client   client.Client
+	recorder record.EventRecorder
+	timeout  time.Duration
+	now      func() time.Time
 }
 
 // NewControlPlaneInitMutex returns a lock that can be held by a control plane node before init.
-func NewControlPlaneInitMutex(log logr.Logger, client client.Client) *ControlPlaneInitMutex {
+// A lock whose holder Machine has been deleted or has failed is released to the next caller,
+// as is a lock that has been held for longer than a non-zero timeout. Taking over a lock after a timeout
+// risks two machines running kubeadm init if the holder is slow rather than stuck, hence it is opt-in.
+func NewControlPlaneInitMutex(log logr.Logger, client client.Client, recorder record.EventRecorder, timeout time.Duration) *ControlPlaneInitMutex {
 	return &ControlPlaneInitMutex{
-		log:    log,
-		client: client,
+		log:      log,
+		client:   client,
+		recorder: recorder,
+		timeout:  timeout,
+		now:      time.Now,
 	}
 }
 
@@ -71,14 +82,22 @@ func (c *ControlPlaneInitMutex) Lock(ctx context.Context, cluster *clusterv1.Clu
 		if info.MachineName == machine.Name {
 			return true
 		}
-		log.Info("Waiting on on another machine to initialize", "init-machine", info.MachineName)
-		return false
+		reason, err := c.staleReason(ctx, cluster, info, sema.CreationTimestamp)
+		if err != nil {
+			log.Error(err, "Failed to check the holder of the existing lock", "init-machine", info.MachineName)
+			return false
+		}
+		if reason == "" {
+			log.Info("Waiting on on another machine to initialize", "init-machine", info.MachineName)
+			return false
+		}
+		return c.steal(ctx, cluster, machine, sema, info.MachineName, reason)
 	}
 
 	// Adds owner reference, namespace and name
 	sema.setMetadata(cluster)
 	// Adds the additional information
-	if err := sema.setInformation(&information{MachineName: machine.Name}); err != nil {
+	if err := sema.setInformation(c.newInformation(machine)); err != nil {
 		log.Error(err, "Failed to acquire lock while setting semaphore information")
 		return false
 	}
@@ -125,8 +144,80 @@ func (c *ControlPlaneInitMutex) Unlock(ctx context.Context, cluster *clusterv1.C
 	}
 }
 
+// staleReason returns a human readable explanation of why the lock described by info should be
+// taken over, or an empty string if its holder should be left alone.
+func (c *ControlPlaneInitMutex) staleReason(ctx context.Context, cluster *clusterv1.Cluster, info *information, created metav1.Time) (string, error) {
+	holder := &clusterv1.Machine{}
+	err := c.client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: info.MachineName}, holder)
+	switch {
+	case apierrors.IsNotFound(err):
+		return fmt.Sprintf("holder Machine %q no longer exists", info.MachineName), nil
+	case err != nil:
+		return "", errors.Wrapf(err, "failed to get holder Machine %q", info.MachineName)
+	case !holder.DeletionTimestamp.IsZero():
+		return fmt.Sprintf("holder Machine %q is being deleted", info.MachineName), nil
+	case holder.Status.ErrorReason != nil || holder.Status.ErrorMessage != nil:
+		return fmt.Sprintf("holder Machine %q has failed", info.MachineName), nil
+	}
+
+	if c.timeout <= 0 {
+		return "", nil
+	}
+	// Locks created before the acquisition time was recorded fall back to the ConfigMap's creation time.
+	acquiredAt := info.AcquiredAt
+	if acquiredAt.IsZero() {
+		acquiredAt = created
+	}
+	if acquiredAt.IsZero() {
+		return "", nil
+	}
+	if held := c.now().Sub(acquiredAt.Time); held > c.timeout {
+		return fmt.Sprintf("holder Machine %q has held the lock for %s, longer than the %s timeout", info.MachineName, held.Round(time.Second), c.timeout), nil
+	}
+	return "", nil
+}
+
+// steal hands an existing lock over to machine. The update is guarded by the ConfigMap's
+// resourceVersion so that only one of several competing machines can succeed.
+func (c *ControlPlaneInitMutex) steal(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine, sema *semaphore, previous, reason string) bool {
+	log := c.log.WithValues("namespace", cluster.Namespace, "cluster-name", cluster.Name, "configmap-name", sema.Name)
+	log.Info("Attempting to take over the lock", "init-machine", previous, "reason", reason)
+
+	if err := sema.setInformation(c.newInformation(machine)); err != nil {
+		log.Error(err, "Failed to take over lock while setting semaphore information")
+		return false
+	}
+	err := c.client.Update(ctx, &sema.ConfigMap)
+	switch {
+	case apierrors.IsConflict(err), apierrors.IsNotFound(err):
+		log.Info("Cannot take over the lock. The lock has been acquired or released by someone else")
+		return false
+	case err != nil:
+		log.Error(err, "Error taking over the lock")
+		c.recordEventf(cluster, apicorev1.EventTypeWarning, "FailedTakeOverInitLock", "Machine %q failed to take over the control plane init lock: %v", machine.Name, err)
+		return false
+	}
+	c.recordEventf(cluster, apicorev1.EventTypeWarning, "InitLockTakenOver", "Machine %q took over the control plane init lock: %s", machine.Name, reason)
+	return true
+}
+
+func (c *ControlPlaneInitMutex) newInformation(machine *clusterv1.Machine) *information {
+	return &information{
+		MachineName: machine.Name,
+		AcquiredAt:  metav1.NewTime(c.now()),
+	}
+}
+
+func (c *ControlPlaneInitMutex) recordEventf(cluster *clusterv1.Cluster, eventType, reason, messageFmt string, args ...interface{}) {
+	if c.recorder == nil {
+		return
+	}
+	c.recorder.Eventf(cluster, eventType, reason, messageFmt, args...)
+}
+
 type information struct {
-	MachineName string `json:"machineName"`
+	MachineName string      `json:"machineName"`
+	AcquiredAt  metav1.Time `json:"acquiredAt,omitempty"`
 }
 
 type semaphore struct {
@@ -149,7 +240,7 @@ func (s semaphore) information() (*information, error) {
 	return li, nil
 }
 
-func (s semaphore) setInformation(information *information) error {
+func (s *semaphore) setInformation(information *information) error {
 	b, err := json.Marshal(information)
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal semaphore information")
@@ -21,7 +21,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -30,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,6 +43,7 @@ import (
 const (
 	clusterName      = "test-cluster"
 	clusterNamespace = "test-namespace"
+	testTimeout      = 20 * time.Minute
 )
 
 func init() {
@@ -109,10 +113,7 @@ func TestControlPlaneInitMutex_Lock(t *testing.T) {
 	for _, tc := range tests {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
-			l := &ControlPlaneInitMutex{
-				log:    log.Log,
-				client: tc.client,
-			}
+			l := NewControlPlaneInitMutex(log.Log, tc.client, nil, testTimeout)
 
 			cluster := &clusterv1.Cluster{
 				ObjectMeta: metav1.ObjectMeta{
@@ -195,10 +196,7 @@ func TestControlPlaneInitMutex_UnLock(t *testing.T) {
 	for _, tc := range tests {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
-			l := &ControlPlaneInitMutex{
-				log:    log.Log,
-				client: tc.client,
-			}
+			l := NewControlPlaneInitMutex(log.Log, tc.client, nil, testTimeout)
 
 			cluster := &clusterv1.Cluster{
 				ObjectMeta: metav1.ObjectMeta{
@@ -238,16 +236,18 @@ func TestInfoLines_Lock(t *testing.T) {
 				Namespace: clusterNamespace,
 			},
 			Data: map[string]string{semaphoreInformationKey: string(b)},
+		}, &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-control-plane",
+				Namespace: clusterNamespace,
+			},
 		}),
 	}
 
 	logtester := &logtests{
 		InfoLog: make([]line, 0),
 	}
-	l := &ControlPlaneInitMutex{
-		log:    logtester,
-		client: c,
-	}
+	l := NewControlPlaneInitMutex(logtester, c, nil, testTimeout)
 
 	cluster := &clusterv1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -278,6 +278,143 @@ func TestInfoLines_Lock(t *testing.T) {
 	}
 }
 
+func TestControlPlaneInitMutex_LockStaleHolder(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2019, 11, 1, 12, 0, 0, 0, time.UTC)
+	errorMessage := "instance terminated"
+	lockHeldBy := func(holder string, acquiredAt time.Time) *corev1.ConfigMap {
+		b, err := json.Marshal(information{MachineName: holder, AcquiredAt: metav1.NewTime(acquiredAt)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      configMapName(clusterName),
+				Namespace: clusterNamespace,
+			},
+			Data: map[string]string{semaphoreInformationKey: string(b)},
+		}
+	}
+	holder := func(status clusterv1.MachineStatus) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "holder",
+				Namespace: clusterNamespace,
+			},
+			Status: status,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		objects       []runtime.Object
+		timeout       time.Duration
+		shouldAcquire bool
+	}{
+		{
+			name:          "should not take over the lock from a healthy holder within the timeout",
+			objects:       []runtime.Object{lockHeldBy("holder", now.Add(-time.Minute)), holder(clusterv1.MachineStatus{})},
+			timeout:       testTimeout,
+			shouldAcquire: false,
+		},
+		{
+			name:          "should take over the lock if the holder no longer exists",
+			objects:       []runtime.Object{lockHeldBy("holder", now.Add(-time.Minute))},
+			timeout:       testTimeout,
+			shouldAcquire: true,
+		},
+		{
+			name:          "should take over the lock if the holder has failed",
+			objects:       []runtime.Object{lockHeldBy("holder", now.Add(-time.Minute)), holder(clusterv1.MachineStatus{ErrorMessage: &errorMessage})},
+			timeout:       testTimeout,
+			shouldAcquire: true,
+		},
+		{
+			name:          "should take over the lock if it has been held for longer than the timeout",
+			objects:       []runtime.Object{lockHeldBy("holder", now.Add(-testTimeout-time.Minute)), holder(clusterv1.MachineStatus{})},
+			timeout:       testTimeout,
+			shouldAcquire: true,
+		},
+		{
+			name:          "should not take over the lock after the timeout if timeouts are disabled",
+			objects:       []runtime.Object{lockHeldBy("holder", now.Add(-testTimeout-time.Minute)), holder(clusterv1.MachineStatus{})},
+			timeout:       0,
+			shouldAcquire: false,
+		},
+		{
+			name:          "should not take over a lock without an acquisition time from a healthy holder",
+			objects:       []runtime.Object{lockHeldBy("holder", time.Time{}), holder(clusterv1.MachineStatus{})},
+			timeout:       testTimeout,
+			shouldAcquire: false,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewFakeClientWithScheme(scheme, tc.objects...)
+			recorder := record.NewFakeRecorder(1)
+			l := NewControlPlaneInitMutex(log.Log, c, recorder, tc.timeout)
+			l.now = func() time.Time { return now }
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: clusterNamespace,
+					Name:      clusterName,
+				},
+			}
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: clusterNamespace,
+					Name:      "new-holder",
+				},
+			}
+
+			actual := l.Lock(context.Background(), cluster, machine)
+			if actual != tc.shouldAcquire {
+				t.Fatalf("acquired was %v, but it should be %v", actual, tc.shouldAcquire)
+			}
+
+			sema := newSemaphore()
+			if err := c.Get(context.Background(), client.ObjectKey{Namespace: clusterNamespace, Name: configMapName(clusterName)}, &sema.ConfigMap); err != nil {
+				t.Fatal(err)
+			}
+			info, err := sema.information()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tc.shouldAcquire {
+				if info.MachineName != "holder" {
+					t.Fatalf("expected the lock to still be held by %q, got %q", "holder", info.MachineName)
+				}
+				if len(recorder.Events) != 0 {
+					t.Fatalf("expected no events, got %q", <-recorder.Events)
+				}
+				return
+			}
+			if info.MachineName != machine.Name {
+				t.Fatalf("expected the lock to be held by %q, got %q", machine.Name, info.MachineName)
+			}
+			if !info.AcquiredAt.Time.Equal(now) {
+				t.Fatalf("expected the lock to be acquired at %v, got %v", now, info.AcquiredAt)
+			}
+			if len(recorder.Events) != 1 {
+				t.Fatalf("expected an event for the take over, got %d", len(recorder.Events))
+			}
+			if event := <-recorder.Events; !strings.Contains(event, "InitLockTakenOver") {
+				t.Fatalf("expected an InitLockTakenOver event, got %q", event)
+			}
+		})
+	}
+}
+
 type fakeClient struct {
 	client.Client
 	getError    error
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing traces the reconciles of the kubeadm bootstrap provider with OpenTelemetry.
+// It follows sigs.k8s.io/cluster-api/util/tracing, which this module cannot import until it
+// depends on a Cluster API release providing it, and reads the trace context recorded by the
+// Machine controller with the same annotation.
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/trace/stdout"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/codes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TracerName is the name of the tracer used by the kubeadm bootstrap provider.
+	TracerName = "sigs.k8s.io/cluster-api/bootstrap/kubeadm"
+
+	// TraceParentAnnotation holds the W3C traceparent of the Machine reconcile that first claimed an object.
+	TraceParentAnnotation = "cluster.x-k8s.io/traceparent"
+)
+
+// Exporters supported by Setup.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Setup installs the global trace provider, sending every span to the given exporter.
+// The endpoint is the address of the collector for the otlp exporter, and is ignored otherwise.
+// The returned function flushes the pending spans and must be called before exiting.
+func Setup(exporter, endpoint, serviceName string) (func(), error) {
+	var (
+		processor sdktrace.SpanProcessor
+		stop      = func() {}
+	)
+	switch exporter {
+	case ExporterNone:
+		return stop, nil
+	case ExporterStdout:
+		exp, err := stdout.NewExporter(stdout.Options{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create stdout trace exporter")
+		}
+		processor = sdktrace.NewSimpleSpanProcessor(exp)
+	case ExporterOTLP:
+		exp, err := otlp.NewExporter(otlp.WithInsecure(), otlp.WithAddress(endpoint))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create otlp trace exporter for %q", endpoint)
+		}
+		bsp, err := sdktrace.NewBatchSpanProcessor(exp)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create batch span processor")
+		}
+		processor = bsp
+		stop = func() {
+			_ = exp.Stop()
+		}
+	default:
+		return nil, errors.Errorf("unknown trace exporter %q, must be one of %s, %s or %s", exporter, ExporterNone, ExporterStdout, ExporterOTLP)
+	}
+
+	provider, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithResourceAttributes(key.String("service.name", serviceName)),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create trace provider")
+	}
+	provider.RegisterSpanProcessor(processor)
+	global.SetTraceProvider(provider)
+
+	return func() {
+		// Unregistering the processor flushes the spans it has queued.
+		provider.UnregisterSpanProcessor(processor)
+		stop()
+	}, nil
+}
+
+// Link starts a span named after the operation, as a child of the span carried by ctx if any,
+// and linked to the span recorded in the annotations of obj by the Machine controller, if any.
+func Link(ctx context.Context, name string, obj metav1.Object) (context.Context, trace.Span) {
+	opts := []trace.StartOption{trace.WithAttributes(
+		key.String("namespace", obj.GetNamespace()),
+		key.String("name", obj.GetName()),
+	)}
+	sc := trace.TraceContext{}.Extract(context.Background(), annotationSupplier{obj})
+	if remote := trace.RemoteSpanContextFromContext(sc); remote.IsValid() {
+		opts = append(opts, trace.LinkedTo(remote))
+	}
+	return global.Tracer(TracerName).Start(ctx, name, opts...)
+}
+
+// End records err on the span, if not nil, and ends it.
+func End(ctx context.Context, span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Unknown))
+	}
+	span.End()
+}
+
+// annotationSupplier reads the W3C trace context headers from the annotations of an object.
+type annotationSupplier struct {
+	obj metav1.Object
+}
+
+func (s annotationSupplier) Get(k string) string {
+	if !strings.EqualFold(k, "traceparent") {
+		return ""
+	}
+	return s.obj.GetAnnotations()[TraceParentAnnotation]
+}
+
+func (s annotationSupplier) Set(string, string) {}
@@ -29,6 +29,7 @@ import (
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha2"
 	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/controllers"
 	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/locking"
+	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/tracing"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	// +kubebuilder:scaffold:imports
@@ -54,6 +55,9 @@ func main() {
 		enableLeaderElection bool
 		syncPeriod           time.Duration
 		watchNamespace       string
+		initLockTimeout      time.Duration
+		tracingExporter      string
+		tracingEndpoint      string
 	)
 
 	flag.StringVar(
@@ -84,6 +88,14 @@ func main() {
 		"The amount of time the bootstrap token will be valid",
 	)
 
+	flag.DurationVar(
+		&initLockTimeout,
+		"init-lock-timeout",
+		0,
+		"If set, the amount of time a control plane machine may hold the init lock before another control plane machine can take it over. "+
+			"Locks held by deleted or failed machines are always taken over. A timeout shorter than the machine provisioning time risks two machines running kubeadm init, which breaks the cluster.",
+	)
+
 	flag.StringVar(
 		&watchNamespace,
 		"namespace",
@@ -91,10 +103,31 @@ func main() {
 		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.",
 	)
 
+	flag.StringVar(
+		&tracingExporter,
+		"tracing-exporter",
+		tracing.ExporterNone,
+		"Where to export the spans of the reconcile loops: none, stdout or otlp",
+	)
+
+	flag.StringVar(
+		&tracingEndpoint,
+		"tracing-endpoint",
+		"localhost:55680",
+		"The address of the OpenTelemetry collector receiving spans when --tracing-exporter is otlp",
+	)
+
 	flag.Parse()
 
 	ctrl.SetLogger(klogr.New())
 
+	shutdownTracing, err := tracing.Setup(tracingExporter, tracingEndpoint, "cluster-api-kubeadm-bootstrap-controller")
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer shutdownTracing()
+
 	if controllers.DefaultTokenTTL-syncPeriod < 1*time.Minute {
 		setupLog.Info("warning: the sync interval is close to the configured token TTL, tokens may expire temporarily before being refreshed")
 	}
@@ -116,7 +149,7 @@ func main() {
 		Client:               mgr.GetClient(),
 		SecretsClientFactory: controllers.ClusterSecretsClientFactory{},
 		Log:                  ctrl.Log.WithName("KubeadmConfigReconciler"),
-		KubeadmInitLock:      locking.NewControlPlaneInitMutex(ctrl.Log.WithName("init-locker"), mgr.GetClient()),
+		KubeadmInitLock:      locking.NewControlPlaneInitMutex(ctrl.Log.WithName("init-locker"), mgr.GetClient(), mgr.GetEventRecorderFor("init-locker"), initLockTimeout),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KubeadmConfigReconciler")
 		os.Exit(1)
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ProviderLabelName is the label set by clusterctl on the objects of the components of a provider.
+	ProviderLabelName = "clusterctl.cluster.x-k8s.io/provider"
+)
+
+// ProviderType is the type of a Cluster API provider.
+type ProviderType string
+
+const (
+	// CoreProviderType is the type of the Cluster API core provider.
+	CoreProviderType = ProviderType("CoreProvider")
+
+	// BootstrapProviderType is the type of the bootstrap providers.
+	BootstrapProviderType = ProviderType("BootstrapProvider")
+
+	// InfrastructureProviderType is the type of the infrastructure providers.
+	InfrastructureProviderType = ProviderType("InfrastructureProvider")
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=providers,scope=Namespaced
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".type"
+// +kubebuilder:printcolumn:name="Provider",type="string",JSONPath=".providerName"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".version"
+// +kubebuilder:printcolumn:name="Watch Namespace",type="string",JSONPath=".watchedNamespace"
+
+// Provider is the Schema for the providers API, recording a provider installed by clusterctl.
+// The Provider object is created in the namespace of the provider components.
+type Provider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// ProviderName is the name of the provider, e.g. cluster-api or docker.
+	ProviderName string `json:"providerName,omitempty"`
+
+	// Type is the type of the provider.
+	Type string `json:"type,omitempty"`
+
+	// Version is the version of the provider components.
+	Version string `json:"version,omitempty"`
+
+	// WatchedNamespace is the namespace watched by the provider controllers.
+	// An empty value means all the namespaces.
+	WatchedNamespace string `json:"watchedNamespace,omitempty"`
+}
+
+// GetProviderType returns the type of the provider.
+func (p *Provider) GetProviderType() ProviderType {
+	return ProviderType(p.Type)
+}
+
+// InstanceName returns the name of the Provider objects for a provider, which is unique
+// across the provider types.
+func InstanceName(providerType ProviderType, providerName string) string {
+	switch providerType {
+	case BootstrapProviderType:
+		return "bootstrap-" + providerName
+	case InfrastructureProviderType:
+		return "infrastructure-" + providerName
+	}
+	return providerName
+}
+
+// +kubebuilder:object:root=true
+
+// ProviderList contains a list of Provider
+type ProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Provider `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Provider{}, &ProviderList{})
+}
@@ -19,6 +19,8 @@ limitations under the License.
 package clientcmd
 
 import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -66,6 +68,30 @@ func NewControllerRuntimeClient(kubeconfigPath string, overrides clientcmd.Confi
 	})
 }
 
+// NewDynamicClientForDefaultSearchPath creates a dynamic client, together with the RESTMapper
+// resolving the resources of the objects it is used for.
+// If the kubeconfigPath is specified then the configuration is loaded from that path,
+// otherwise the default kubeconfig search path is used.
+// The overrides parameter is used to select a specific context of the config, for example,
+// select the context with a given cluster name or namespace.
+func NewDynamicClientForDefaultSearchPath(kubeconfigPath string, overrides clientcmd.ConfigOverrides) (dynamic.Interface, meta.RESTMapper, error) {
+	config, err := newRestConfigForDefaultSearchPath(kubeconfigPath, overrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mapper, err := restmapper.NewCached(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dynamicClient, mapper, nil
+}
+
 // newRestConfig creates a rest.Config for the given apiConfig
 // The overrides parameter is used to select a specific context of the config, for example,
 // select the context with a given cluster name or namespace.
@@ -17,126 +17,182 @@ limitations under the License.
 package kind
 
 import (
-	"fmt"
 	"io/ioutil"
-	"os/exec"
+	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/create"
 )
 
 const (
 	kindClusterNamePrefix = "clusterapi-"
-)
 
-var (
-	// ignoredOptions lists the options not supported by delete and kubeconfig-path.
-	ignoredOptions = []string{"config", "image", "retain", "wait"}
+	// defaultWaitForReady is the time waited for the control plane of the cluster to be ready,
+	// unless overridden with the wait option.
+	defaultWaitForReady = 5 * time.Minute
 )
 
+// kindCluster is the subset of the kind cluster.Context used by the provisioner.
+type kindCluster interface {
+	Create(options ...create.ClusterOption) error
+	Delete() error
+	KubeConfigPath() string
+}
+
+// Kind provisions a bootstrap cluster using kind as a library. The cluster is configured
+// with options in the same format as the flags of `kind create cluster`:
+//
+//	name=<cluster name>, defaults to a random name with the prefix "clusterapi-".
+//	image=<node image>, defaults to the node image of the kind release.
+//	config=<path to a kind cluster config file>.
+//	retain[=true|false], to retain the nodes for debugging if the creation fails.
+//	wait=<duration>, the time to wait for the control plane to be ready, defaults to 5m.
 type Kind struct {
-	options []string
-	// execFunc implemented as function variable for testing hooks
-	execFunc func(args ...string) (string, error)
+	name         string
+	nodeImage    string
+	configFile   string
+	retain       bool
+	waitForReady time.Duration
+
+	cluster kindCluster
+	// notifySignals implemented as function variable for testing hooks
+	notifySignals func(c chan<- os.Signal)
 }
 
 func New() *Kind {
-	return WithOptions([]string{})
+	k, _ := WithOptions([]string{})
+	return k
 }
 
-func WithOptions(options []string) *Kind {
-	// Set name if it is not provided.
-	if func() bool {
-		for _, opt := range options {
-			if strings.HasPrefix(opt, "name=") {
-				return false
+func WithOptions(options []string) (*Kind, error) {
+	k := &Kind{
+		name:         kindClusterNamePrefix + util.RandomString(5),
+		waitForReady: defaultWaitForReady,
+		notifySignals: func(c chan<- os.Signal) {
+			signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+		},
+	}
+
+	for _, opt := range options {
+		key, value := opt, ""
+		if i := strings.Index(opt, "="); i >= 0 {
+			key, value = opt[:i], opt[i+1:]
+		}
+		var err error
+		switch key {
+		case "name":
+			k.name = value
+		case "image":
+			k.nodeImage = value
+		case "config":
+			k.configFile = value
+		case "retain":
+			k.retain = true
+			if value != "" {
+				k.retain, err = strconv.ParseBool(value)
 			}
+		case "wait":
+			k.waitForReady, err = time.ParseDuration(value)
+		default:
+			return nil, errors.Errorf("unknown kind option %q, supported options are name, image, config, retain and wait", key)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for the kind option %q", key)
 		}
-		return true
-	}() {
-		options = append(options, fmt.Sprintf("name=%s", kindClusterNamePrefix+util.RandomString(5)))
 	}
 
-	return &Kind{
-		execFunc: execFunc,
-		options:  options,
-	}
+	k.cluster = cluster.NewContext(k.name)
+	return k, nil
 }
 
-var execFunc = func(args ...string) (string, error) {
-	const executable = "kind"
-	klog.V(3).Infof("Running: %v %v", executable, args)
-	cmd := exec.Command(executable, args...)
-	cmdOut, err := cmd.CombinedOutput()
-	klog.V(2).Infof("Ran: %v %v Output: %v", executable, args, string(cmdOut))
-	if err != nil {
-		err = errors.Wrapf(err, "error running command '%v %v'", executable, strings.Join(args, " "))
+// ResumeFlags returns the options of the provisioner, including the generated name of the cluster.
+func (k *Kind) ResumeFlags() []string {
+	flags := []string{
+		"name=" + k.name,
+		"retain=" + strconv.FormatBool(k.retain),
+		"wait=" + k.waitForReady.String(),
+	}
+	if k.nodeImage != "" {
+		flags = append(flags, "image="+k.nodeImage)
 	}
-	return string(cmdOut), err
+	if k.configFile != "" {
+		flags = append(flags, "config="+k.configFile)
+	}
+	return flags
 }
 
+// Create creates the kind cluster and waits for its control plane to be ready. If the process is
+// interrupted in the meantime, the cluster is deleted once the creation has stopped.
 func (k *Kind) Create() error {
-	args := []string{"create", "cluster"}
+	options := []create.ClusterOption{
+		create.Retain(k.retain),
+		create.WaitForReady(k.waitForReady),
+	}
+	if k.configFile != "" {
+		options = append(options, create.WithConfigFile(k.configFile))
+	}
+	if k.nodeImage != "" {
+		options = append(options, create.WithNodeImage(k.nodeImage))
+	}
+
+	signals := make(chan os.Signal, 1)
+	k.notifySignals(signals)
+	defer signal.Stop(signals)
 
-	args = k.appendOptions(args)
+	klog.V(2).Infof("Creating kind cluster %q", k.name)
+	done := make(chan error, 1)
+	go func() {
+		done <- k.cluster.Create(options...)
+	}()
 
-	_, err := k.exec(args...)
-	return err
+	select {
+	case err := <-done:
+		if err != nil {
+			return errors.Wrapf(err, "error creating kind cluster %q", k.name)
+		}
+		return nil
+	case sig := <-signals:
+		klog.Warningf("Received %v while creating kind cluster %q, deleting it", sig, k.name)
+		// Deleting the nodes created so far makes the creation fail early, but the creation may still
+		// create nodes until it returns: wait for it, then delete the cluster again.
+		if err := k.Delete(); err != nil {
+			klog.Warningf("Error deleting the nodes of kind cluster %q while it is being created: %v", k.name, err)
+		}
+		<-done
+		if err := k.Delete(); err != nil {
+			return err
+		}
+		return errors.Errorf("creation of kind cluster %q interrupted by %v", k.name, sig)
+	}
 }
 
+// Delete deletes the kind cluster. Interrupts received while deleting the cluster are ignored,
+// so it isn't left partially deleted.
 func (k *Kind) Delete() error {
-	args := []string{"delete", "cluster"}
-
-	args = k.appendOptions(args, ignoredOptions...)
+	signals := make(chan os.Signal, 1)
+	k.notifySignals(signals)
+	defer signal.Stop(signals)
 
-	_, err := k.exec(args...)
-	return err
+	klog.V(2).Infof("Deleting kind cluster %q", k.name)
+	if err := k.cluster.Delete(); err != nil {
+		return errors.Wrapf(err, "error deleting kind cluster %q", k.name)
+	}
+	return nil
 }
 
 func (k *Kind) GetKubeconfig() (string, error) {
-	path, err := k.getKubeConfigPath()
+	b, err := ioutil.ReadFile(k.cluster.KubeConfigPath())
 	if err != nil {
-		return "", err
-	}
-
-	b, err := ioutil.ReadFile(path)
-	if err != nil {
-		return "", err
+		return "", errors.Wrapf(err, "error reading the kubeconfig of kind cluster %q", k.name)
 	}
 
 	return string(b), nil
 }
-
-func (k *Kind) getKubeConfigPath() (string, error) {
-	args := []string{"get", "kubeconfig-path"}
-
-	args = k.appendOptions(args, ignoredOptions...)
-
-	out, err := k.exec(args...)
-	if err != nil {
-		return "", err
-	}
-
-	return strings.TrimSpace(out), nil
-}
-
-func (k *Kind) exec(args ...string) (string, error) {
-	return k.execFunc(args...)
-}
-
-// appendOptions enriches the args with all options but the ignored ones
-func (k *Kind) appendOptions(args []string, ignoredOptions ...string) []string {
-outer:
-	for _, opt := range k.options {
-		for _, ignoredOption := range ignoredOptions {
-			if strings.HasPrefix(opt, ignoredOption) {
-				continue outer
-			}
-		}
-		args = append(args, fmt.Sprintf("--%v", opt))
-	}
-
-	return args
-}
@@ -19,83 +19,255 @@ package kind
 import (
 	"io/ioutil"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
+	"sigs.k8s.io/kind/pkg/cluster/create"
 )
 
-func TestCreate(t *testing.T) {
+type fakeCluster struct {
+	createOptions  []create.ClusterOption
+	createErr      error
+	deleted        bool
+	deleteErr      error
+	kubeconfigPath string
+
+	// When set, Create creates a node, then waits until the cluster is deleted before creating
+	// another node, like a creation still running when interrupted.
+	createRunning bool
+	// lock guards nodes and released
+	lock     sync.Mutex
+	nodes    int
+	released chan struct{}
+}
+
+func (c *fakeCluster) Create(options ...create.ClusterOption) error {
+	c.createOptions = options
+	if c.createRunning {
+		c.lock.Lock()
+		c.nodes++
+		c.lock.Unlock()
+		<-c.released
+		c.lock.Lock()
+		c.nodes++
+		c.lock.Unlock()
+	}
+	return c.createErr
+}
+
+func (c *fakeCluster) Delete() error {
+	c.deleted = true
+	if c.createRunning {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		c.nodes = 0
+		select {
+		case <-c.released:
+		default:
+			close(c.released)
+		}
+	}
+	return c.deleteErr
+}
+
+func (c *fakeCluster) KubeConfigPath() string {
+	return c.kubeconfigPath
+}
+
+func newWithFakeCluster(t *testing.T, c *fakeCluster, options ...string) (*Kind, chan<- os.Signal) {
+	k, err := WithOptions(options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	signals := make(chan os.Signal, 1)
+	k.cluster = c
+	k.notifySignals = func(c chan<- os.Signal) {
+		go func() {
+			if sig, ok := <-signals; ok {
+				c <- sig
+			}
+		}()
+	}
+	return k, signals
+}
+
+func TestWithOptions(t *testing.T) {
 	var testcases = []struct {
 		name      string
-		execError error
+		options   []string
+		expected  Kind
 		expectErr bool
 	}{
 		{
-			name: "success",
+			name:     "defaults",
+			expected: Kind{waitForReady: defaultWaitForReady},
 		},
 		{
-			name:      "exec fail",
-			execError: errors.New("test error"),
+			name:    "all options",
+			options: []string{"name=test", "image=kindest/node:v1.15.3", "config=kind.yaml", "retain", "wait=1m"},
+			expected: Kind{
+				name:         "test",
+				nodeImage:    "kindest/node:v1.15.3",
+				configFile:   "kind.yaml",
+				retain:       true,
+				waitForReady: time.Minute,
+			},
+		},
+		{
+			name:     "retain disabled",
+			options:  []string{"retain=false"},
+			expected: Kind{waitForReady: defaultWaitForReady},
+		},
+		{
+			name:      "invalid wait",
+			options:   []string{"wait=forever"},
+			expectErr: true,
+		},
+		{
+			name:      "unknown option",
+			options:   []string{"loglevel=debug"},
 			expectErr: true,
 		},
 	}
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
-			m := New()
-			m.execFunc = func(args ...string) (string, error) {
-				return "", testcase.execError
+			k, err := WithOptions(testcase.options)
+			if (testcase.expectErr && err == nil) || (!testcase.expectErr && err != nil) {
+				t.Fatalf("Unexpected returned error. Got: %v, Want Err: %v", err, testcase.expectErr)
+			}
+			if err != nil {
+				return
 			}
-			err := m.Create()
+			if testcase.expected.name == "" {
+				if !strings.HasPrefix(k.name, kindClusterNamePrefix) {
+					t.Errorf("Expected a generated name with prefix %q, got %q", kindClusterNamePrefix, k.name)
+				}
+				testcase.expected.name = k.name
+			}
+			got := Kind{name: k.name, nodeImage: k.nodeImage, configFile: k.configFile, retain: k.retain, waitForReady: k.waitForReady}
+			if !reflect.DeepEqual(got, testcase.expected) {
+				t.Errorf("Unexpected options. Got: %+v, Want: %+v", got, testcase.expected)
+			}
+		})
+	}
+}
+
+func TestResumeFlags(t *testing.T) {
+	k, err := WithOptions([]string{"image=kindest/node:v1.15.3", "retain"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resumed, err := WithOptions(k.ResumeFlags())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resumed.name != k.name || resumed.nodeImage != k.nodeImage || resumed.retain != k.retain || resumed.waitForReady != k.waitForReady {
+		t.Errorf("Unexpected options. Got: %+v, Want: %+v", resumed, k)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var testcases = []struct {
+		name            string
+		options         []string
+		createError     error
+		expectedOptions int
+		expectErr       bool
+	}{
+		{
+			name:            "success",
+			expectedOptions: 2,
+		},
+		{
+			name:            "success with node image and config",
+			options:         []string{"image=kindest/node:v1.15.3", "config=kind.yaml"},
+			expectedOptions: 4,
+		},
+		{
+			name:            "create fail",
+			createError:     errors.New("test error"),
+			expectedOptions: 2,
+			expectErr:       true,
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			c := &fakeCluster{createErr: testcase.createError}
+			k, _ := newWithFakeCluster(t, c, testcase.options...)
+			err := k.Create()
 			if (testcase.expectErr && err == nil) || (!testcase.expectErr && err != nil) {
 				t.Fatalf("Unexpected returned error. Got: %v, Want Err: %v", err, testcase.expectErr)
 			}
+			if len(c.createOptions) != testcase.expectedOptions {
+				t.Errorf("Unexpected number of create options. Got: %d, Want: %d", len(c.createOptions), testcase.expectedOptions)
+			}
 		})
 	}
 }
 
+func TestCreateDeletesClusterOnInterrupt(t *testing.T) {
+	c := &fakeCluster{createRunning: true, released: make(chan struct{})}
+	k, signals := newWithFakeCluster(t, c)
+	signals <- os.Interrupt
+
+	if err := k.Create(); err == nil {
+		t.Fatal("Expected an error when interrupted")
+	}
+	if !c.deleted {
+		t.Error("Expected the cluster to be deleted when interrupted")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.nodes != 0 {
+		t.Errorf("Expected the nodes created after the interrupt to be deleted, got %d nodes", c.nodes)
+	}
+}
+
 func TestDelete(t *testing.T) {
 	var testcases = []struct {
-		name      string
-		execError error
-		expectErr bool
+		name        string
+		deleteError error
+		expectErr   bool
 	}{
 		{
 			name: "success",
 		},
 		{
-			name:      "exec fail",
-			execError: errors.New("test error"),
-			expectErr: true,
+			name:        "delete fail",
+			deleteError: errors.New("test error"),
+			expectErr:   true,
 		},
 	}
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
-			m := New()
-			m.execFunc = func(args ...string) (string, error) {
-				return "", testcase.execError
-			}
-			err := m.Delete()
+			c := &fakeCluster{deleteErr: testcase.deleteError}
+			k, _ := newWithFakeCluster(t, c)
+			err := k.Delete()
 			if (testcase.expectErr && err == nil) || (!testcase.expectErr && err != nil) {
 				t.Fatalf("Unexpected returned error. Got: %v, Want Err: %v", err, testcase.expectErr)
 			}
+			if !c.deleted {
+				t.Error("Expected the cluster to be deleted")
+			}
 		})
 	}
 }
 
 func TestGetKubeconfig(t *testing.T) {
 	const contents = "dfserfafaew"
-	m := New()
 	f, err := createTempFile(contents)
 	if err != nil {
 		t.Fatal("Unable to create test file")
 	}
 	defer os.Remove(f)
 	t.Run("file does not exist", func(t *testing.T) {
-		m.execFunc = func(args ...string) (string, error) {
-			return "/tmp/nope", nil
-		}
+		k, _ := newWithFakeCluster(t, &fakeCluster{kubeconfigPath: "/tmp/nope"})
 
-		c, err := m.GetKubeconfig()
+		c, err := k.GetKubeconfig()
 		if err == nil {
 			t.Fatal("Able to read a file that does not exist")
 		}
@@ -104,25 +276,9 @@ func TestGetKubeconfig(t *testing.T) {
 		}
 	})
 	t.Run("file exists", func(t *testing.T) {
-		m.execFunc = func(args ...string) (string, error) {
-			return f, nil
-		}
-
-		c, err := m.GetKubeconfig()
-		if err != nil {
-			t.Fatalf("Unexpected err, got: %v", err)
-			return
-		}
-		if c != contents {
-			t.Fatalf("Unexpected contents, got: %v, want: %v", c, contents)
-		}
-	})
-	t.Run("file exists, output with newline", func(t *testing.T) {
-		m.execFunc = func(args ...string) (string, error) {
-			return f + "\n", nil
-		}
+		k, _ := newWithFakeCluster(t, &fakeCluster{kubeconfigPath: f})
 
-		c, err := m.GetKubeconfig()
+		c, err := k.GetKubeconfig()
 		if err != nil {
 			t.Fatalf("Unexpected err, got: %v", err)
 			return
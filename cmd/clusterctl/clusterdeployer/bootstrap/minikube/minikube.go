@@ -85,6 +85,11 @@ var minikubeExec = func(env []string, args ...string) (string, error) {
 	return string(cmdOut), err
 }
 
+// ResumeFlags returns the options of the provisioner, including the generated profile of the cluster.
+func (m *Minikube) ResumeFlags() []string {
+	return m.options
+}
+
 func (m *Minikube) Create() error {
 	args := []string{"start", "--bootstrapper=kubeadm"}
 	for _, opt := range m.options {
@@ -19,10 +19,10 @@ package bootstrap
 import "github.com/spf13/pflag"
 
 type Options struct {
-	Type       string
-	Cleanup    bool
-	ExtraFlags []string
-	KubeConfig string
+	Type       string   `json:"type,omitempty"`
+	Cleanup    bool     `json:"cleanup"`
+	ExtraFlags []string `json:"extraFlags,omitempty"`
+	KubeConfig string   `json:"kubeConfig,omitempty"`
 }
 
 func (o *Options) AddFlags(fs *pflag.FlagSet) {
@@ -17,6 +17,10 @@ limitations under the License.
 package bootstrap
 
 import (
+	"sort"
+	"strings"
+	"sync"
+
 	"github.com/pkg/errors"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/bootstrap/existing"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/bootstrap/kind"
@@ -30,17 +34,84 @@ type ClusterProvisioner interface {
 	GetKubeconfig() (string, error)
 }
 
-func Get(o Options) (ClusterProvisioner, error) {
-	switch o.Type {
-	case "kind":
-		return kind.WithOptions(o.ExtraFlags), nil
-	case "minikube":
+// ResumableProvisioner is implemented by the provisioners generating a part of the options of the
+// cluster they create, e.g. a random name, so that it can be provisioned again by a later run of clusterctl.
+type ResumableProvisioner interface {
+	ClusterProvisioner
+	// ResumeFlags returns the extra flags getting a provisioner for the same cluster.
+	ResumeFlags() []string
+}
+
+// ResumeOptions returns the options getting a provisioner for the same cluster as the one
+// created by the provisioner with the given options.
+func ResumeOptions(o Options, provisioner ClusterProvisioner) Options {
+	if r, ok := provisioner.(ResumableProvisioner); ok {
+		o.ExtraFlags = r.ResumeFlags()
+	}
+	return o
+}
+
+// ProvisionerFactory creates a ClusterProvisioner from the bootstrap options.
+type ProvisionerFactory func(o Options) (ClusterProvisioner, error)
+
+var (
+	// provisionersLock guards provisioners
+	provisionersLock sync.Mutex
+	provisioners     = map[string]ProvisionerFactory{}
+)
+
+func init() {
+	Register("kind", func(o Options) (ClusterProvisioner, error) {
+		return kind.WithOptions(o.ExtraFlags)
+	})
+	Register("minikube", func(o Options) (ClusterProvisioner, error) {
 		return minikube.WithOptions(o.ExtraFlags), nil
-	default:
-		if o.KubeConfig != "" {
-			return existing.New(o.KubeConfig)
-		}
+	})
+}
+
+// Register makes a ClusterProvisioner available as a `--bootstrap-type`. It is meant to be
+// called from the init function of the package implementing the provisioner, and panics if
+// the factory is nil or a provisioner is already registered with the same type.
+func Register(bootstrapType string, factory ProvisionerFactory) {
+	provisionersLock.Lock()
+	defer provisionersLock.Unlock()
 
-		return nil, errors.New("no bootstrap provisioner specified, you can specify `--bootstrap-cluster-kubeconfig` to use an existing Kubernetes cluster or `--bootstrap-type` to use a built-in ephemeral cluster")
+	if factory == nil {
+		panic("bootstrap: Register factory is nil for type " + bootstrapType)
+	}
+	if _, ok := provisioners[bootstrapType]; ok {
+		panic("bootstrap: Register called twice for type " + bootstrapType)
 	}
+	provisioners[bootstrapType] = factory
+}
+
+// Types returns the sorted list of the registered bootstrap types.
+func Types() []string {
+	provisionersLock.Lock()
+	defer provisionersLock.Unlock()
+
+	types := make([]string, 0, len(provisioners))
+	for t := range provisioners {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func Get(o Options) (ClusterProvisioner, error) {
+	provisionersLock.Lock()
+	factory, ok := provisioners[o.Type]
+	provisionersLock.Unlock()
+	if ok {
+		return factory(o)
+	}
+
+	if o.KubeConfig != "" {
+		return existing.New(o.KubeConfig)
+	}
+	if o.Type != "" && o.Type != "none" {
+		return nil, errors.Errorf("unknown bootstrap type %q, supported types are: %s", o.Type, strings.Join(Types(), ", "))
+	}
+
+	return nil, errors.New("no bootstrap provisioner specified, you can specify `--bootstrap-cluster-kubeconfig` to use an existing Kubernetes cluster or `--bootstrap-type` to use a built-in ephemeral cluster")
 }
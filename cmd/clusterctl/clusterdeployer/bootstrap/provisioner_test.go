@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"testing"
+)
+
+type fakeProvisioner struct {
+	ClusterProvisioner
+	options Options
+}
+
+func TestRegister(t *testing.T) {
+	Register("test", func(o Options) (ClusterProvisioner, error) {
+		return &fakeProvisioner{options: o}, nil
+	})
+	defer func() {
+		provisionersLock.Lock()
+		delete(provisioners, "test")
+		provisionersLock.Unlock()
+	}()
+
+	p, err := Get(Options{Type: "test", ExtraFlags: []string{"flag"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fake, ok := p.(*fakeProvisioner)
+	if !ok {
+		t.Fatalf("Expected the registered provisioner, got %T", p)
+	}
+	if len(fake.options.ExtraFlags) != 1 {
+		t.Errorf("Expected the options to be passed to the factory, got %+v", fake.options)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering the same type twice to panic")
+		}
+	}()
+	Register("test", func(o Options) (ClusterProvisioner, error) {
+		return nil, nil
+	})
+}
+
+func TestGet(t *testing.T) {
+	var testcases = []struct {
+		name      string
+		options   Options
+		expectErr bool
+	}{
+		{
+			name:    "kind",
+			options: Options{Type: "kind"},
+		},
+		{
+			name:    "minikube",
+			options: Options{Type: "minikube"},
+		},
+		{
+			name:      "unknown type",
+			options:   Options{Type: "unknown"},
+			expectErr: true,
+		},
+		{
+			name:      "no type",
+			options:   Options{Type: "none"},
+			expectErr: true,
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			_, err := Get(testcase.options)
+			if (testcase.expectErr && err == nil) || (!testcase.expectErr && err != nil) {
+				t.Fatalf("Unexpected returned error. Got: %v, Want Err: %v", err, testcase.expectErr)
+			}
+		})
+	}
+}
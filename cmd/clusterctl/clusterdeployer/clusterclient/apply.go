@@ -0,0 +1,224 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterclient
+
+import (
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/yaml"
+)
+
+// fieldManager is the name recorded as the manager of the fields set by clusterctl.
+const fieldManager = "clusterctl"
+
+// Apply creates or updates the objects of a multi-document YAML manifest.
+// Namespaces and CRDs are applied first; objects failing with an error that is expected to go away,
+// e.g. because the API server or a webhook is not available yet, are retried until timeoutApply.
+func (c *client) Apply(manifest string) error {
+	objs, err := decodeManifest(manifest)
+	if err != nil {
+		return err
+	}
+	sortObjectsForApply(objs)
+
+	var errs []error
+	err = util.PollImmediate(retryIntervalApply, timeoutApply, func() (bool, error) {
+		var pending []*unstructured.Unstructured
+		errs = nil
+		for _, obj := range objs {
+			err := c.applyObject(obj)
+			if err == nil {
+				continue
+			}
+			errs = append(errs, errors.Wrapf(err, "error applying %s", describeObject(obj)))
+			if isRetryableApplyError(err) {
+				klog.V(4).Infof("Waiting to apply %s: %v", describeObject(obj), err)
+				pending = append(pending, obj)
+			}
+		}
+		if len(errs) > len(pending) {
+			// At least one object failed with an error that won't go away by retrying.
+			return false, kerrors.NewAggregate(errs)
+		}
+		objs = pending
+		return len(pending) == 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Wrap(kerrors.NewAggregate(errs), "timed out applying the manifest")
+	}
+	return err
+}
+
+// Delete deletes the objects of a multi-document YAML manifest, in the reverse order they would be applied.
+// Objects that don't exist are ignored.
+func (c *client) Delete(manifest string) error {
+	objs, err := decodeManifest(manifest)
+	if err != nil {
+		return err
+	}
+	sortObjectsForApply(objs)
+
+	var errs []error
+	for i := len(objs) - 1; i >= 0; i-- {
+		obj := objs[i]
+		resource, err := c.resourceFor(obj)
+		if meta.IsNoMatchError(err) {
+			// The CRD defining the object has already been deleted, and the object with it.
+			continue
+		}
+		if err == nil {
+			err = resource.Delete(obj.GetName(), &metav1.DeleteOptions{})
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "error deleting %s", describeObject(obj)))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// applyObject creates or updates a single object using server-side apply, falling back to
+// create or update if the API server doesn't support it.
+func (c *client) applyObject(obj *unstructured.Unstructured) error {
+	resource, err := c.resourceFor(obj)
+	if err != nil {
+		return err
+	}
+
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, obj)
+	if err != nil {
+		return err
+	}
+	force := true
+	_, err = resource.Patch(obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: fieldManager,
+		Force:        &force,
+	})
+	if !apierrors.IsUnsupportedMediaType(err) {
+		return err
+	}
+
+	existing, err := resource.Get(obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = resource.Create(obj, metav1.CreateOptions{FieldManager: fieldManager})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	obj = obj.DeepCopy()
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	_, err = resource.Update(obj, metav1.UpdateOptions{FieldManager: fieldManager})
+	return err
+}
+
+// resourceFor returns the dynamic client for the resource of an object. Namespaced objects
+// without a namespace are defaulted to the namespace of the current context.
+func (c *client) resourceFor(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return c.dynamicClient.Resource(mapping.Resource), nil
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(c.GetContextNamespace())
+	}
+	return c.dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+// isRetryableApplyError returns true if an error applying an object is expected to go away,
+// e.g. when the API server is starting or the CRD of the object has not been established yet.
+func isRetryableApplyError(err error) bool {
+	if meta.IsNoMatchError(err) {
+		return true
+	}
+	switch {
+	case apierrors.IsNotFound(err),
+		apierrors.IsInternalError(err),
+		apierrors.IsServiceUnavailable(err),
+		apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err),
+		apierrors.IsTooManyRequests(err):
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, io.EOF.Error()) ||
+		strings.Contains(msg, "refused") ||
+		strings.Contains(msg, "no such host") ||
+		strings.Contains(msg, "i/o timeout")
+}
+
+// decodeManifest decodes the objects of a multi-document YAML manifest.
+func decodeManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLDecoder(ioutil.NopCloser(strings.NewReader(manifest)))
+	defer decoder.Close()
+
+	var objs []*unstructured.Unstructured
+	for {
+		obj := &unstructured.Unstructured{}
+		_, _, err := decoder.Decode(nil, obj)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "error decoding object #%d of the manifest", len(objs)+1)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// sortObjectsForApply sorts Namespaces first, then CRDs, so they exist before the objects
+// depending on them. The order of the other objects is preserved.
+func sortObjectsForApply(objs []*unstructured.Unstructured) {
+	priority := func(obj *unstructured.Unstructured) int {
+		switch obj.GroupVersionKind().GroupKind().String() {
+		case "Namespace":
+			return 0
+		case "CustomResourceDefinition.apiextensions.k8s.io":
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		return priority(objs[i]) < priority(objs[j])
+	})
+}
+
+func describeObject(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetKind() + " " + obj.GetName()
+	}
+	return obj.GetKind() + " " + obj.GetNamespace() + "/" + obj.GetName()
+}
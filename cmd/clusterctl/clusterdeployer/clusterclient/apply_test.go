@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+const applyTestManifest = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+apiVersion: cluster.x-k8s.io/v1alpha3
+kind: Cluster
+metadata:
+  name: cluster
+  namespace: test
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: clusters.cluster.x-k8s.io
+---
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: test
+`
+
+func TestDecodeManifestSortsNamespacesAndCRDsFirst(t *testing.T) {
+	objs, err := decodeManifest(applyTestManifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sortObjectsForApply(objs)
+
+	var got []string
+	for _, obj := range objs {
+		got = append(got, obj.GetKind())
+	}
+	want := "Namespace,CustomResourceDefinition,ConfigMap,Cluster"
+	if strings.Join(got, ",") != want {
+		t.Errorf("got objects %v, want %s", got, want)
+	}
+}
+
+func TestApplyFallsBackToCreateOrUpdate(t *testing.T) {
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamicClient.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewGenericServerResponse(http.StatusUnsupportedMediaType, "patch", schema.GroupResource{}, "", "", 0, false)
+	})
+	c := &client{dynamicClient: dynamicClient, mapper: newApplyTestMapper()}
+
+	manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\ndata:\n  key: value\n"
+	if err := c.Apply(manifest); err != nil {
+		t.Fatalf("unexpected error creating the objects: %v", err)
+	}
+	if err := c.Apply(strings.Replace(manifest, "key: value", "key: updated", 1)); err != nil {
+		t.Fatalf("unexpected error updating the objects: %v", err)
+	}
+
+	configMaps := dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"})
+	cm, err := configMaps.Namespace("default").Get("config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the ConfigMap to be created in the namespace of the context: %v", err)
+	}
+	if got := cm.Object["data"].(map[string]interface{})["key"]; got != "updated" {
+		t.Errorf("got data %v, want updated", got)
+	}
+}
+
+func TestApplyReportsErrorsPerObject(t *testing.T) {
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamicClient.PrependReactor("patch", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		name := action.(clienttesting.PatchAction).GetName()
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, name, nil)
+	})
+	c := &client{dynamicClient: dynamicClient, mapper: newApplyTestMapper()}
+
+	manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: first\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: second\n"
+	err := c.Apply(manifest)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, name := range []string{"ConfigMap default/first", "ConfigMap default/second"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("expected error %q to report %s", err, name)
+		}
+	}
+}
+
+func TestIsRetryableApplyError(t *testing.T) {
+	testcases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "kind not yet served",
+			err:  &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "cluster.x-k8s.io", Kind: "Cluster"}},
+			want: true,
+		},
+		{
+			name: "namespace not yet created",
+			err:  apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "test"),
+			want: true,
+		},
+		{
+			name: "webhook not yet available",
+			err:  apierrors.NewInternalError(errors.New("failed calling webhook")),
+			want: true,
+		},
+		{
+			name: "server not yet available",
+			err:  errors.New("dial tcp 127.0.0.1:6443: connect: connection refused"),
+			want: true,
+		},
+		{
+			name: "invalid object",
+			err:  apierrors.NewBadRequest("invalid object"),
+			want: false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isRetryableApplyError(tc.err); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func newApplyTestMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	return mapper
+}
@@ -21,7 +21,6 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"reflect"
 	"strconv"
 	"strings"
@@ -29,6 +28,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -36,11 +36,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 	_ "k8s.io/client-go/plugin/pkg/client/auth" // nolint
 	tcmd "k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/clientcmd"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/inventory"
 	"sigs.k8s.io/cluster-api/util"
 	kcfg "sigs.k8s.io/cluster-api/util/kubeconfig"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -48,10 +51,10 @@ import (
 
 const (
 	retryAcquireClient         = 10 * time.Second
-	retryIntervalKubectlApply  = 10 * time.Second
+	retryIntervalApply         = 10 * time.Second
 	retryIntervalResourceReady = 10 * time.Second
 	timeoutAcquireClient       = 10 * time.Minute
-	timeoutKubectlApply        = 15 * time.Minute
+	timeoutApply               = 15 * time.Minute
 	timeoutResourceReady       = 15 * time.Minute
 	timeoutMachineReady        = 30 * time.Minute
 )
@@ -88,6 +91,7 @@ type Client interface {
 	ForceDeleteMachineDeployment(namespace, name string) error
 	ForceDeleteUnstructuredObject(*unstructured.Unstructured) error
 	EnsureNamespace(string) error
+	EnsureProviderInventory() error
 	GetKubeconfigFromSecret(namespace, clusterName string) (string, error)
 	GetClusterSecrets(*clusterv1.Cluster) ([]*corev1.Secret, error)
 	GetClusters(string) ([]*clusterv1.Cluster, error)
@@ -103,9 +107,12 @@ type Client interface {
 	GetMachines(namespace string) ([]*clusterv1.Machine, error)
 	GetMachinesForCluster(*clusterv1.Cluster) ([]*clusterv1.Machine, error)
 	GetMachinesForMachineSet(*clusterv1.MachineSet) ([]*clusterv1.Machine, error)
+	GetProviders() ([]*clusterctlv1.Provider, error)
 	GetUnstructuredObject(*unstructured.Unstructured) error
 	ScaleDeployment(namespace, name string, scale int32) error
+	SaveProvider(*clusterctlv1.Provider) error
 	WaitForClusterV1alpha2Ready() error
+	WaitForDeploymentsReady(namespace string, labels map[string]string) error
 	WaitForResourceStatuses() error
 	WaitForCertManagerReady() error
 	SetClusterOwnerRef(runtime.Object, *clusterv1.Cluster) error
@@ -113,6 +120,8 @@ type Client interface {
 
 type client struct {
 	clientSet       ctrlclient.Client
+	dynamicClient   dynamic.Interface
+	mapper          meta.RESTMapper
 	kubeconfigFile  string
 	configOverrides tcmd.ConfigOverrides
 	closeFn         func() error
@@ -234,9 +243,16 @@ func (c *client) DeleteNamespace(namespaceName string) error {
 // NewFromDefaultSearchPath creates and returns a Client.  The kubeconfigFile argument is expected to be the path to a
 // valid kubeconfig file.
 func NewFromDefaultSearchPath(kubeconfigFile string, overrides tcmd.ConfigOverrides) (*client, error) { //nolint
-	var c ctrlclient.Client
+	var (
+		c             ctrlclient.Client
+		dynamicClient dynamic.Interface
+		mapper        meta.RESTMapper
+	)
 	if err := util.PollImmediate(retryAcquireClient, timeoutAcquireClient, func() (_ bool, err error) {
 		c, err = clientcmd.NewControllerRuntimeClient(kubeconfigFile, overrides)
+		if err == nil {
+			dynamicClient, mapper, err = clientcmd.NewDynamicClientForDefaultSearchPath(kubeconfigFile, overrides)
+		}
 		if err != nil {
 			if strings.Contains(err.Error(), io.EOF.Error()) || strings.Contains(err.Error(), "refused") || strings.Contains(err.Error(), "no such host") || strings.Contains(err.Error(), "i/o timeout") {
 				// Connection was refused, probably because the API server is not ready yet.
@@ -258,6 +274,8 @@ func NewFromDefaultSearchPath(kubeconfigFile string, overrides tcmd.ConfigOverri
 	return &client{
 		kubeconfigFile:  kubeconfigFile,
 		clientSet:       c,
+		dynamicClient:   dynamicClient,
+		mapper:          mapper,
 		configOverrides: overrides,
 	}, nil
 }
@@ -270,14 +288,6 @@ func (c *client) Close() error {
 	return nil
 }
 
-func (c *client) Delete(manifest string) error {
-	return c.kubectlDelete(manifest)
-}
-
-func (c *client) Apply(manifest string) error {
-	return c.waitForKubectlApply(manifest)
-}
-
 func (c *client) GetContextNamespace() string {
 	if c.configOverrides.Context.Namespace == "" {
 		return corev1.NamespaceDefault
@@ -520,7 +530,8 @@ func (c *client) CreateMachines(machines []*clusterv1.Machine, namespace string)
 		go func(machine *clusterv1.Machine) {
 			defer wg.Done()
 
-			if err := c.clientSet.Create(ctx, machine); err != nil {
+			// A Machine that already exists, e.g. created by an interrupted run of clusterctl, is only waited for.
+			if err := c.clientSet.Create(ctx, machine); err != nil && !apierrors.IsAlreadyExists(err) {
 				errOnce.Do(func() {
 					gerr = errors.Wrapf(err, "error creating a machine object in namespace %v", namespace)
 				})
@@ -617,8 +628,31 @@ func (c *client) WaitForClusterV1alpha2Ready() error {
 	return waitForClusterResourceReady(c.clientSet)
 }
 
+// WaitForDeploymentsReady waits for the Deployments matching labels in a namespace to complete their rollout.
+func (c *client) WaitForDeploymentsReady(namespace string, labels map[string]string) error {
+	return util.PollImmediate(retryIntervalResourceReady, timeoutResourceReady, func() (bool, error) {
+		deployments := &appsv1.DeploymentList{}
+		if err := c.clientSet.List(ctx, deployments, ctrlclient.InNamespace(namespace), ctrlclient.MatchingLabels(labels)); err != nil {
+			klog.V(10).Infof("retrying: failed to list deployments: %v", err)
+			return false, nil
+		}
+		for _, d := range deployments.Items {
+			replicas := int32(1)
+			if d.Spec.Replicas != nil {
+				replicas = *d.Spec.Replicas
+			}
+			if d.Status.ObservedGeneration < d.Generation || d.Status.UpdatedReplicas != replicas ||
+				d.Status.AvailableReplicas != replicas || d.Status.Replicas != replicas {
+				klog.V(10).Infof("retrying: deployment %s/%s is not rolled out", d.Namespace, d.Name)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
 func (c *client) WaitForCertManagerReady() error {
-	return util.PollImmediate(retryIntervalResourceReady, timeoutKubectlApply, func() (bool, error) {
+	return util.PollImmediate(retryIntervalResourceReady, timeoutApply, func() (bool, error) {
 		pods := &corev1.PodList{}
 		if err := c.clientSet.List(ctx, pods, ctrlclient.ListOption(ctrlclient.InNamespace("cert-manager"))); err != nil {
 			klog.V(10).Infof("retrying: failed to list pods: %v", err)
@@ -789,67 +823,51 @@ func (c *client) ForceDeleteUnstructuredObject(u *unstructured.Unstructured) err
 	return nil
 }
 
-func (c *client) kubectlDelete(manifest string) error {
-	return c.kubectlManifestCmd("delete", manifest)
-}
-
-func (c *client) kubectlApply(manifest string) error {
-	return c.kubectlManifestCmd("apply", manifest)
-}
-
-func (c *client) kubectlManifestCmd(commandName, manifest string) error {
-	cmd := exec.Command("kubectl", c.buildKubectlArgs(commandName)...)
-	cmd.Stdin = strings.NewReader(manifest)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return errors.Wrapf(err, "couldn't kubectl apply, output: %s", string(out))
+// EnsureProviderInventory installs the CRD of the Provider objects recording the providers
+// installed by clusterctl, and waits for it to be served.
+func (c *client) EnsureProviderInventory() error {
+	if err := c.Apply(inventory.CRD); err != nil {
+		return errors.Wrap(err, "error installing the provider inventory CRD")
 	}
-	return nil
+	return util.PollImmediate(retryIntervalResourceReady, timeoutResourceReady, func() (bool, error) {
+		if err := c.clientSet.List(ctx, &clusterctlv1.ProviderList{}); err != nil {
+			klog.V(10).Infof("retrying: failed to list providers: %v", err)
+			return false, nil
+		}
+		return true, nil
+	})
 }
 
-func (c *client) buildKubectlArgs(commandName string) []string {
-	args := []string{commandName}
-	if c.kubeconfigFile != "" {
-		args = append(args, "--kubeconfig", c.kubeconfigFile)
-	}
-	if c.configOverrides.Context.Cluster != "" {
-		args = append(args, "--cluster", c.configOverrides.Context.Cluster)
-	}
-	if c.configOverrides.Context.Namespace != "" {
-		args = append(args, "--namespace", c.configOverrides.Context.Namespace)
+func (c *client) GetProviders() ([]*clusterctlv1.Provider, error) {
+	list := &clusterctlv1.ProviderList{}
+	if err := c.clientSet.List(ctx, list); err != nil {
+		return nil, errors.Wrap(err, "error listing Providers")
 	}
-	if c.configOverrides.Context.AuthInfo != "" {
-		args = append(args, "--user", c.configOverrides.Context.AuthInfo)
+	providers := make([]*clusterctlv1.Provider, 0, len(list.Items))
+	for i := range list.Items {
+		providers = append(providers, &list.Items[i])
 	}
-	return append(args, "-f", "-")
+	return providers, nil
 }
 
-func (c *client) waitForKubectlApply(manifest string) error {
-	err := util.PollImmediate(retryIntervalKubectlApply, timeoutKubectlApply, func() (bool, error) {
-		klog.V(2).Infof("Waiting for kubectl apply...")
-		err := c.kubectlApply(manifest)
-		if err != nil {
-			if strings.Contains(err.Error(), io.EOF.Error()) || strings.Contains(err.Error(), "refused") || strings.Contains(err.Error(), "no such host") {
-				// Connection was refused, probably because the API server is not ready yet.
-				klog.V(4).Infof("Waiting for kubectl apply... server not yet available: %v", err)
-				return false, nil
-			}
-			if strings.Contains(err.Error(), "unable to recognize") {
-				klog.V(4).Infof("Waiting for kubectl apply... api not yet available: %v", err)
-				return false, nil
-			}
-			if strings.Contains(err.Error(), "namespaces \"default\" not found") {
-				klog.V(4).Infof("Waiting for kubectl apply... default namespace not yet available: %v", err)
-				return false, nil
-			}
-			klog.Warningf("Waiting for kubectl apply... unknown error %v", err)
-			return false, err
+// SaveProvider creates a Provider object, or updates it if it already exists.
+func (c *client) SaveProvider(provider *clusterctlv1.Provider) error {
+	existing := &clusterctlv1.Provider{}
+	err := c.clientSet.Get(ctx, ctrlclient.ObjectKey{Namespace: provider.Namespace, Name: provider.Name}, existing)
+	if apierrors.IsNotFound(err) {
+		if err := c.clientSet.Create(ctx, provider); err != nil {
+			return errors.Wrapf(err, "error creating Provider %s/%s", provider.Namespace, provider.Name)
 		}
-
-		return true, nil
-	})
-
-	return err
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error getting Provider %s/%s", provider.Namespace, provider.Name)
+	}
+	provider.ResourceVersion = existing.ResourceVersion
+	if err := c.clientSet.Update(ctx, provider); err != nil {
+		return errors.Wrapf(err, "error updating Provider %s/%s", provider.Namespace, provider.Name)
+	}
+	return nil
 }
 
 func waitForClusterResourceReady(cs ctrlclient.Client) error {
@@ -18,7 +18,11 @@ package clusterdeployer
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -59,6 +63,14 @@ func New(
 
 // Create the cluster from the provided cluster definition and machine list.
 func (d *ClusterDeployer) Create(resources *yaml.ParseOutput, kubeconfigOutput string, providerComponentsStoreFactory provider.ComponentsStoreFactory) error {
+	return d.CreateWithState(resources, kubeconfigOutput, providerComponentsStoreFactory, NewState(""))
+}
+
+// CreateWithState creates the cluster like Create, skipping the phases recorded as completed in the state
+// and recording the phases it completes. An interrupt stops the creation once the current phase is completed.
+// If the state is persisted, the bootstrap cluster is kept when the creation is interrupted or fails, so that
+// it can be resumed.
+func (d *ClusterDeployer) CreateWithState(resources *yaml.ParseOutput, kubeconfigOutput string, providerComponentsStoreFactory provider.ComponentsStoreFactory, state *State) (err error) {
 	cluster := resources.Clusters[0]
 	machines := resources.Machines
 
@@ -67,29 +79,57 @@ func (d *ClusterDeployer) Create(resources *yaml.ParseOutput, kubeconfigOutput s
 		return errors.Wrap(err, "unable to separate control plane machines from node machines")
 	}
 
-	bootstrapClient, cleanupBootstrapCluster, err := phases.CreateBootstrapCluster(d.bootstrapProvisioner, d.cleanupBootstrapCluster, d.clientFactory)
-	defer cleanupBootstrapCluster()
+	r := newPhaseRunner(state)
+	defer r.stop()
+
+	var (
+		bootstrapClient         clusterclient.Client
+		cleanupBootstrapCluster = func() {}
+	)
+	defer func() {
+		if err != nil && state.IsPersisted() && state.IsCompleted(phaseBootstrapCluster) {
+			klog.Info("Keeping the bootstrap cluster to resume the creation of the cluster.")
+			return
+		}
+		cleanupBootstrapCluster()
+	}()
+
+	if state.IsCompleted(phaseBootstrapCluster) {
+		klog.Info("Connecting to the bootstrap cluster created by a previous run")
+		bootstrapClient, cleanupBootstrapCluster, err = phases.ConnectBootstrapCluster(d.bootstrapProvisioner, d.cleanupBootstrapCluster, d.clientFactory)
+	} else {
+		bootstrapClient, cleanupBootstrapCluster, err = phases.CreateBootstrapCluster(d.bootstrapProvisioner, d.cleanupBootstrapCluster, d.clientFactory)
+	}
 	if err != nil {
 		return errors.Wrap(err, "could not create bootstrap cluster")
 	}
 	defer closeClient(bootstrapClient, "bootstrap")
+	if err := r.complete(phaseBootstrapCluster); err != nil {
+		return err
+	}
 
 	if d.bootstrapComponents != "" {
-		if err := phases.ApplyBootstrapComponents(bootstrapClient, d.bootstrapComponents); err != nil {
+		if err := r.run(phaseBootstrapComponents, func() error {
+			return phases.ApplyBootstrapComponents(bootstrapClient, d.bootstrapComponents)
+		}); err != nil {
 			return errors.Wrap(err, "unable to apply bootstrap components to bootstrap cluster")
 		}
 	}
 
-	klog.Info("Applying Cluster API stack to bootstrap cluster")
-	if err := phases.ApplyClusterAPIComponents(bootstrapClient, d.providerComponents); err != nil {
+	if err := r.run(phaseClusterAPIComponents, func() error {
+		klog.Info("Applying Cluster API stack to bootstrap cluster")
+		return phases.ApplyClusterAPIComponents(bootstrapClient, d.providerComponents)
+	}); err != nil {
 		return errors.Wrap(err, "unable to apply cluster api stack to bootstrap cluster")
 	}
 
-	klog.Info("Provisioning target cluster via bootstrap cluster")
-	if err := phases.ApplyCluster(
-		bootstrapClient,
-		cluster,
-		yaml.ExtractClusterReferences(resources, cluster)...); err != nil {
+	if err := r.run(phaseCluster, func() error {
+		klog.Info("Provisioning target cluster via bootstrap cluster")
+		return phases.ApplyCluster(
+			bootstrapClient,
+			cluster,
+			yaml.ExtractClusterReferences(resources, cluster)...)
+	}); err != nil {
 		return errors.Wrapf(err, "unable to create cluster %q in bootstrap cluster", cluster.Name)
 	}
 
@@ -98,20 +138,33 @@ func (d *ClusterDeployer) Create(resources *yaml.ParseOutput, kubeconfigOutput s
 	}
 
 	firstControlPlane := controlPlaneMachines[0]
-	klog.Infof("Creating control plane machine %q in namespace %q", firstControlPlane.Name, cluster.Namespace)
-	if err := phases.ApplyMachines(
-		bootstrapClient,
-		cluster.Namespace,
-		[]*clusterv1.Machine{firstControlPlane},
-		yaml.ExtractMachineReferences(resources, firstControlPlane)...); err != nil {
+	if err := r.run(phaseControlPlaneMachine, func() error {
+		klog.Infof("Creating control plane machine %q in namespace %q", firstControlPlane.Name, cluster.Namespace)
+		return phases.ApplyMachines(
+			bootstrapClient,
+			cluster.Namespace,
+			[]*clusterv1.Machine{firstControlPlane},
+			yaml.ExtractMachineReferences(resources, firstControlPlane)...)
+	}); err != nil {
 		return errors.Wrap(err, "unable to create control plane machine")
 	}
 
-	klog.Info("Creating target cluster")
-	targetKubeconfig, err := phases.GetKubeconfig(bootstrapClient, kubeconfigOutput, cluster.Name, cluster.Namespace)
-	if err != nil {
+	var targetKubeconfig string
+	if err := r.run(phaseKubeconfig, func() (err error) {
+		klog.Info("Creating target cluster")
+		targetKubeconfig, err = phases.GetKubeconfig(bootstrapClient, kubeconfigOutput, cluster.Name, cluster.Namespace)
+		return err
+	}); err != nil {
 		return fmt.Errorf("unable to create target cluster kubeconfig: %v", err)
 	}
+	if targetKubeconfig == "" {
+		// The kubeconfig was written by a previous run.
+		kubeconfig, err := ioutil.ReadFile(kubeconfigOutput)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read target cluster kubeconfig %q", kubeconfigOutput)
+		}
+		targetKubeconfig = string(kubeconfig)
+	}
 
 	targetClient, err := d.clientFactory.NewClientFromKubeconfig(targetKubeconfig)
 	if err != nil {
@@ -120,49 +173,61 @@ func (d *ClusterDeployer) Create(resources *yaml.ParseOutput, kubeconfigOutput s
 	defer closeClient(targetClient, "target")
 
 	if d.addonComponents != "" {
-		if err := phases.ApplyAddons(targetClient, d.addonComponents); err != nil {
+		if err := r.run(phaseAddons, func() error {
+			return phases.ApplyAddons(targetClient, d.addonComponents)
+		}); err != nil {
 			return errors.Wrap(err, "unable to apply addons to target cluster")
 		}
 	}
 
-	klog.Info("Pivoting Cluster API stack to target cluster")
-	if err := phases.Pivot(bootstrapClient, targetClient, d.providerComponents); err != nil {
+	if err := r.run(phasePivot, func() error {
+		klog.Info("Pivoting Cluster API stack to target cluster")
+		return phases.Pivot(bootstrapClient, targetClient, d.providerComponents)
+	}); err != nil {
 		return errors.Wrap(err, "unable to pivot cluster api stack to target cluster")
 	}
 
-	klog.Info("Saving provider components to the target cluster")
-	err = d.saveProviderComponentsToCluster(providerComponentsStoreFactory, kubeconfigOutput)
-	if err != nil {
+	if err := r.run(phaseSaveProviderComponents, func() error {
+		klog.Info("Saving provider components to the target cluster")
+		return d.saveProviderComponentsToCluster(providerComponentsStoreFactory, kubeconfigOutput)
+	}); err != nil {
 		return errors.Wrap(err, "unable to save provider components to target cluster")
 	}
 
 	if len(controlPlaneMachines) > 1 {
-		// TODO(h0tbird) Done serially until kubernetes/kubeadm#1097 is resolved and all
-		// supported versions of k8s we are deploying (using kubeadm) have the fix.
-		klog.Info("Creating additional control plane machines in target cluster.")
-		for _, controlPlaneMachine := range controlPlaneMachines[1:] {
-			if err := phases.ApplyMachines(
-				targetClient,
-				cluster.Namespace,
-				[]*clusterv1.Machine{controlPlaneMachine},
-				yaml.ExtractMachineReferences(resources, controlPlaneMachine)...,
-			); err != nil {
-				return errors.Wrap(err, "unable to create additional control plane machines")
+		if err := r.run(phaseAdditionalControlPlane, func() error {
+			// TODO(h0tbird) Done serially until kubernetes/kubeadm#1097 is resolved and all
+			// supported versions of k8s we are deploying (using kubeadm) have the fix.
+			klog.Info("Creating additional control plane machines in target cluster.")
+			for _, controlPlaneMachine := range controlPlaneMachines[1:] {
+				if err := phases.ApplyMachines(
+					targetClient,
+					cluster.Namespace,
+					[]*clusterv1.Machine{controlPlaneMachine},
+					yaml.ExtractMachineReferences(resources, controlPlaneMachine)...,
+				); err != nil {
+					return err
+				}
 			}
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "unable to create additional control plane machines")
 		}
 	}
 
-	klog.Info("Creating node machines in target cluster.")
-	extraMachineResources := []*unstructured.Unstructured{}
-	for _, m := range nodes {
-		extraMachineResources = append(extraMachineResources, yaml.ExtractMachineReferences(resources, m)...)
-	}
-	if err := phases.ApplyMachines(
-		targetClient,
-		cluster.Namespace,
-		nodes,
-		extraMachineResources...,
-	); err != nil {
+	if err := r.run(phaseNodeMachines, func() error {
+		klog.Info("Creating node machines in target cluster.")
+		extraMachineResources := []*unstructured.Unstructured{}
+		for _, m := range nodes {
+			extraMachineResources = append(extraMachineResources, yaml.ExtractMachineReferences(resources, m)...)
+		}
+		return phases.ApplyMachines(
+			targetClient,
+			cluster.Namespace,
+			nodes,
+			extraMachineResources...,
+		)
+	}); err != nil {
 		return errors.Wrap(err, "unable to create node machines")
 	}
 
@@ -243,6 +308,71 @@ func deleteClusterAPIObjectsInAllNamespaces(client clusterclient.Client) error {
 	return nil
 }
 
+// phaseRunner runs the phases of ClusterDeployer.CreateWithState, skipping the phases completed by a
+// previous run and stopping once the current phase is completed when clusterctl is interrupted.
+type phaseRunner struct {
+	state       *State
+	interrupted chan os.Signal
+	stop        func()
+}
+
+// notifyInterrupt calls onInterrupt when clusterctl is interrupted, until the returned function is called.
+// It is implemented as function variable for testing hooks.
+var notifyInterrupt = func(onInterrupt func(os.Signal)) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig, ok := <-signals
+		if !ok {
+			return
+		}
+		// Restore the default behavior, so that a second interrupt exits immediately.
+		signal.Stop(signals)
+		onInterrupt(sig)
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(signals)
+	}
+}
+
+func newPhaseRunner(state *State) *phaseRunner {
+	r := &phaseRunner{
+		state:       state,
+		interrupted: make(chan os.Signal, 1),
+	}
+	r.stop = notifyInterrupt(func(sig os.Signal) {
+		klog.Warningf("Received %v, stopping once the current phase is completed. Interrupt again to exit immediately.", sig)
+		r.interrupted <- sig
+	})
+	return r
+}
+
+// run runs the phase, unless it was completed by a previous run, and records it as completed.
+func (r *phaseRunner) run(phase string, fn func() error) error {
+	if r.state.IsCompleted(phase) {
+		klog.Infof("Skipping phase %q, completed by a previous run", phase)
+		return nil
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return r.complete(phase)
+}
+
+// complete records the phase as completed, and returns an error if clusterctl has been interrupted.
+func (r *phaseRunner) complete(phase string) error {
+	if err := r.state.Complete(phase); err != nil {
+		return err
+	}
+	select {
+	case sig := <-r.interrupted:
+		return errors.Errorf("interrupted by %v after completing phase %q", sig, phase)
+	default:
+		return nil
+	}
+}
+
 func closeClient(client clusterclient.Client, name string) {
 	if client != nil {
 		if err := client.Close(); err != nil {
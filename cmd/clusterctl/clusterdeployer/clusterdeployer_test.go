@@ -30,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/clusterclient"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/provider"
 	"sigs.k8s.io/cluster-api/util/secret"
@@ -135,6 +136,7 @@ type testClusterClient struct {
 	machines            map[string][]*clusterv1.Machine
 	unstructuredObjects map[string][]*unstructured.Unstructured
 	secrets             []*corev1.Secret
+	providers           []*clusterctlv1.Provider
 	namespaces          []string
 	contextNamespace    string
 }
@@ -557,6 +559,29 @@ func (c *testClusterClient) GetMachinesForMachineSet(ms *clusterv1.MachineSet) (
 	return results, nil
 }
 
+func (c *testClusterClient) EnsureProviderInventory() error {
+	return nil
+}
+
+func (c *testClusterClient) GetProviders() ([]*clusterctlv1.Provider, error) {
+	return c.providers, nil
+}
+
+func (c *testClusterClient) SaveProvider(provider *clusterctlv1.Provider) error {
+	for i, p := range c.providers {
+		if p.Namespace == provider.Namespace && p.Name == provider.Name {
+			c.providers[i] = provider
+			return nil
+		}
+	}
+	c.providers = append(c.providers, provider)
+	return nil
+}
+
+func (c *testClusterClient) WaitForDeploymentsReady(namespace string, labels map[string]string) error {
+	return nil
+}
+
 func (c *testClusterClient) WaitForCertManagerReady() error {
 	return nil
 }
@@ -1075,6 +1100,171 @@ func TestCreateProviderComponentsScenarios(t *testing.T) {
 	}
 }
 
+func newResumeTestScenario(t *testing.T) (*testClusterProvisioner, *testClusterClientFactory, *yaml.ParseOutput) {
+	const bootstrapKubeconfig = "bootstrap"
+	const targetKubeconfig = "target"
+	p := &testClusterProvisioner{
+		kubeconfig: bootstrapKubeconfig,
+	}
+
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-kubeconfig",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: []byte(targetKubeconfig),
+		},
+	}
+	f := newTestClusterClientFactory()
+	f.clusterClients[bootstrapKubeconfig] = &testClusterClient{
+		secrets: []*corev1.Secret{kubeconfigSecret},
+	}
+	f.clusterClients[targetKubeconfig] = &testClusterClient{}
+
+	inputCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: metav1.NamespaceDefault,
+		},
+	}
+	resources := &yaml.ParseOutput{
+		Clusters: []*clusterv1.Cluster{inputCluster},
+		Machines: generateMachines(inputCluster, metav1.NamespaceDefault),
+	}
+	return p, f, resources
+}
+
+func TestCreateWithStateSkipsCompletedPhases(t *testing.T) {
+	p, f, resources := newResumeTestScenario(t)
+	// The bootstrap cluster fails any apply, the phases applying objects to it must be skipped.
+	f.clusterClients["bootstrap"].ApplyErr = errors.New("unexpected apply to the bootstrap cluster")
+
+	kubeconfigOut := newTempFile(t)
+	defer os.Remove(kubeconfigOut)
+	if err := ioutil.WriteFile(kubeconfigOut, []byte("target"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stateFile := newTempFile(t)
+	defer os.Remove(stateFile)
+	state := NewState(stateFile)
+	state.CompletedPhases = []string{
+		phaseBootstrapCluster,
+		phaseClusterAPIComponents,
+		phaseCluster,
+		phaseControlPlaneMachine,
+		phaseKubeconfig,
+		phasePivot,
+		phaseSaveProviderComponents,
+	}
+
+	pcStore := mockProviderComponentsStore{}
+	pcFactory := mockProviderComponentsStoreFactory{NewFromCoreclientsetPCStore: &pcStore}
+	d := New(p, f, "---\nyaml: definition", "", "", true)
+	if err := d.CreateWithState(resources, kubeconfigOut, &pcFactory, state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if p.clusterCreated {
+		t.Error("expected the existing bootstrap cluster to be used")
+	}
+	if p.clusterExists {
+		t.Error("expected the bootstrap cluster to be cleaned up")
+	}
+	if pcStore.SaveCapturedProviderComponents != "" {
+		t.Error("expected the provider components not to be saved again")
+	}
+	if len(f.clusterClients["target"].machines[metav1.NamespaceDefault]) != 1 {
+		t.Errorf("expected the node machine to be created in the target cluster, got %v", f.clusterClients["target"].machines)
+	}
+
+	saved, err := LoadState(stateFile)
+	if err != nil {
+		t.Fatalf("unexpected error loading the state: %v", err)
+	}
+	if !saved.IsCompleted(phaseNodeMachines) {
+		t.Errorf("expected phase %q to be recorded as completed, got %v", phaseNodeMachines, saved.CompletedPhases)
+	}
+}
+
+func TestCreateWithStateCleansUpOnFailure(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		persisted             bool
+		expectBootstrapExists bool
+	}{
+		{"in-memory state cleans up the bootstrap cluster", false, false},
+		{"persisted state keeps the bootstrap cluster", true, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, f, resources := newResumeTestScenario(t)
+			f.clusterClients["bootstrap"].ApplyErr = errors.New("apply error")
+			kubeconfigOut := newTempFile(t)
+			defer os.Remove(kubeconfigOut)
+			state := NewState("")
+			if tc.persisted {
+				stateFile := newTempFile(t)
+				defer os.Remove(stateFile)
+				state = NewState(stateFile)
+			}
+
+			d := New(p, f, "---\nyaml: definition", "", "", true)
+			if err := d.CreateWithState(resources, kubeconfigOut, &mockProviderComponentsStoreFactory{}, state); err == nil {
+				t.Fatal("expected an error")
+			}
+			if !p.clusterCreated {
+				t.Fatal("expected the bootstrap cluster to be created")
+			}
+			if p.clusterExists != tc.expectBootstrapExists {
+				t.Errorf("bootstrap cluster exists: got %v, want %v", p.clusterExists, tc.expectBootstrapExists)
+			}
+		})
+	}
+}
+
+func TestCreateWithStateStopsWhenInterrupted(t *testing.T) {
+	defer func(f func(func(os.Signal)) func()) { notifyInterrupt = f }(notifyInterrupt)
+	notifyInterrupt = func(onInterrupt func(os.Signal)) func() {
+		onInterrupt(os.Interrupt)
+		return func() {}
+	}
+
+	testCases := []struct {
+		name                  string
+		persisted             bool
+		expectBootstrapExists bool
+	}{
+		{"persisted state keeps the bootstrap cluster", true, true},
+		{"in-memory state cleans up the bootstrap cluster", false, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, f, resources := newResumeTestScenario(t)
+			kubeconfigOut := newTempFile(t)
+			defer os.Remove(kubeconfigOut)
+			state := NewState("")
+			if tc.persisted {
+				stateFile := newTempFile(t)
+				defer os.Remove(stateFile)
+				state = NewState(stateFile)
+			}
+
+			d := New(p, f, "---\nyaml: definition", "", "", true)
+			err := d.CreateWithState(resources, kubeconfigOut, &mockProviderComponentsStoreFactory{}, state)
+			if err == nil || !strings.Contains(err.Error(), "interrupted") {
+				t.Fatalf("expected an interrupted error, got %v", err)
+			}
+			if len(state.CompletedPhases) != 1 || state.CompletedPhases[0] != phaseBootstrapCluster {
+				t.Errorf("expected only phase %q to be completed, got %v", phaseBootstrapCluster, state.CompletedPhases)
+			}
+			if p.clusterExists != tc.expectBootstrapExists {
+				t.Errorf("bootstrap cluster exists: got %v, want %v", p.clusterExists, tc.expectBootstrapExists)
+			}
+		})
+	}
+}
+
 func TestExtractControlPlaneMachine(t *testing.T) {
 	const singleControlPlaneName = "test-control-plane"
 	multipleControlPlaneNames := []string{"test-control-plane-1", "test-control-plane-2"}
@@ -0,0 +1,141 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterdeployer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/bootstrap"
+	"sigs.k8s.io/yaml"
+)
+
+// The phases of ClusterDeployer.Create, in the order they are run.
+const (
+	phaseBootstrapCluster       = "bootstrap-cluster"
+	phaseBootstrapComponents    = "bootstrap-components"
+	phaseClusterAPIComponents   = "cluster-api-components"
+	phaseCluster                = "cluster"
+	phaseControlPlaneMachine    = "control-plane-machine"
+	phaseKubeconfig             = "kubeconfig"
+	phaseAddons                 = "addons"
+	phasePivot                  = "pivot"
+	phaseSaveProviderComponents = "save-provider-components"
+	phaseAdditionalControlPlane = "additional-control-plane-machines"
+	phaseNodeMachines           = "node-machines"
+)
+
+// State records the progress of ClusterDeployer.Create, so that a creation that was interrupted
+// or failed can be resumed without running the completed phases again.
+type State struct {
+	// Bootstrap are the options getting the provisioner of the bootstrap cluster.
+	Bootstrap bootstrap.Options `json:"bootstrap"`
+
+	// ClusterName and ClusterNamespace identify the cluster being created.
+	ClusterName      string `json:"clusterName"`
+	ClusterNamespace string `json:"clusterNamespace,omitempty"`
+
+	// CompletedPhases lists the phases completed so far.
+	CompletedPhases []string `json:"completedPhases,omitempty"`
+
+	// path is the file the state is saved to. The state is not saved if empty.
+	path string
+}
+
+// NewState returns an empty state, saved to the file at path. The state is kept in memory only if path is empty.
+func NewState(path string) *State {
+	return &State{path: path}
+}
+
+// LoadState reads the state saved to the file at path.
+func LoadState(path string) (*State, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading state file %q", path)
+	}
+	s := &State{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, errors.Wrapf(err, "error parsing state file %q", path)
+	}
+	s.path = path
+	return s, nil
+}
+
+// IsPersisted returns true if the state is saved to a file.
+func (s *State) IsPersisted() bool {
+	return s.path != ""
+}
+
+// IsCompleted returns true if the phase has been completed.
+func (s *State) IsCompleted(phase string) bool {
+	for _, p := range s.CompletedPhases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete records the phase as completed, and saves the state.
+func (s *State) Complete(phase string) error {
+	if s.IsCompleted(phase) {
+		return nil
+	}
+	s.CompletedPhases = append(s.CompletedPhases, phase)
+	return s.Save()
+}
+
+// Save writes the state to its file. The file is replaced atomically, so that it is not left
+// truncated if clusterctl is killed while writing it.
+func (s *State) Save() error {
+	if !s.IsPersisted() {
+		return nil
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling state")
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path))
+	if err != nil {
+		return errors.Wrapf(err, "error saving state file %q", s.path)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "error saving state file %q", s.path)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrapf(err, "error saving state file %q", s.path)
+	}
+	if err := os.Rename(f.Name(), s.path); err != nil {
+		return errors.Wrapf(err, "error saving state file %q", s.path)
+	}
+	return nil
+}
+
+// Remove deletes the file of the state, once the creation is complete.
+func (s *State) Remove() error {
+	if !s.IsPersisted() {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "error removing state file %q", s.path)
+	}
+	return nil
+}
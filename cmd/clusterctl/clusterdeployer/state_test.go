@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterdeployer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/bootstrap"
+)
+
+func TestStateSaveAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.yaml")
+
+	state := NewState(path)
+	state.Bootstrap = bootstrap.Options{Type: "kind", Cleanup: true, ExtraFlags: []string{"name=clusterapi-abcde"}}
+	state.ClusterName = "test-cluster"
+	if err := state.Complete(phaseBootstrapCluster); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := state.Complete(phaseBootstrapCluster); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, state) {
+		t.Errorf("got state %+v, want %+v", loaded, state)
+	}
+	if !loaded.IsCompleted(phaseBootstrapCluster) || loaded.IsCompleted(phasePivot) {
+		t.Errorf("unexpected completed phases %v", loaded.CompletedPhases)
+	}
+
+	if err := loaded.Remove(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the state file to be removed, got %v", err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("expected no file left in the state directory, got %d", len(files))
+	}
+}
+
+func TestStateInMemory(t *testing.T) {
+	state := NewState("")
+	if err := state.Complete(phaseBootstrapCluster); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.IsPersisted() {
+		t.Error("expected the state not to be persisted")
+	}
+	if !state.IsCompleted(phaseBootstrapCluster) {
+		t.Errorf("expected phase %q to be completed", phaseBootstrapCluster)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/template"
+)
+
+var configCmd = &cobra.Command{
+	Use:     "config",
+	Aliases: []string{"generate"},
+	Short:   "Generate cluster API manifests",
+	Long:    `Generate cluster API manifests from provider templates`,
+}
+
+// defaultConfigFile returns the clusterctl config file to use, or an empty string if the
+// default config file doesn't exist.
+func defaultConfigFile(configFile string) string {
+	if configFile != "" {
+		return configFile
+	}
+	configFile = filepath.Join(homedir.HomeDir(), ".cluster-api", "clusterctl.yaml")
+	if _, err := os.Stat(configFile); err != nil {
+		return ""
+	}
+	return configFile
+}
+
+// loadProvidersConfig returns the providers and the variables defined by the clusterctl config file.
+func loadProvidersConfig(configFile string) ([]repository.Provider, *template.Variables, error) {
+	configFile = defaultConfigFile(configFile)
+	providers, err := repository.LoadProviders(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	variables := &template.Variables{}
+	if configFile != "" {
+		if variables.ConfigFile, err = template.LoadConfigFile(configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	return providers, variables, nil
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/template"
+)
+
+type ConfigClusterOptions struct {
+	Template                 string
+	ConfigFile               string
+	Namespace                string
+	KubernetesVersion        string
+	ControlPlaneMachineCount int
+	WorkerMachineCount       int
+	Variables                []string
+	ListVariables            bool
+	Output                   string
+}
+
+var cco = &ConfigClusterOptions{}
+
+var configClusterCmd = &cobra.Command{
+	Use:   "cluster NAME",
+	Short: "Generate the manifests of a kubernetes cluster",
+	Long: `Generate the manifests of a kubernetes cluster from a provider template.
+
+The ${VAR} placeholders of the template are replaced with the values set with --set,
+read from the environment or from the config file, in this order of precedence.
+Placeholders can set a default value with ${VAR:=default}.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if cco.Template == "" {
+			exitWithHelp(cmd, "Please provide the cluster template to generate the manifests from.\n")
+		}
+		fmt.Fprintln(os.Stderr, deprecationMsg)
+		if err := RunConfigCluster(cmd, args, cco); err != nil {
+			klog.Exit(err)
+		}
+	},
+}
+
+func RunConfigCluster(cmd *cobra.Command, args []string, cco *ConfigClusterOptions) error {
+	tmpl, err := ioutil.ReadFile(cco.Template)
+	if err != nil {
+		return errors.Wrapf(err, "error loading cluster template %q", cco.Template)
+	}
+	if cco.ListVariables {
+		for _, name := range template.VariableNames(tmpl) {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	variables, err := configClusterVariables(cmd, args, cco)
+	if err != nil {
+		return err
+	}
+	out, err := template.GenerateCluster(tmpl, variables)
+	if err != nil {
+		return err
+	}
+	if cco.Output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return errors.Wrapf(ioutil.WriteFile(cco.Output, out, 0644), "error writing manifests to %q", cco.Output)
+}
+
+// configClusterVariables returns the variables set by the flags, the environment and the config file.
+// The flags with a default value only take precedence over the other sources when they are set.
+func configClusterVariables(cmd *cobra.Command, args []string, cco *ConfigClusterOptions) (*template.Variables, error) {
+	variables := &template.Variables{}
+
+	if configFile := defaultConfigFile(cco.ConfigFile); configFile != "" {
+		values, err := template.LoadConfigFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		variables.ConfigFile = values
+	}
+
+	for _, v := range cco.Variables {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid variable %q, must be NAME=VALUE", v)
+		}
+		variables.Set(parts[0], parts[1])
+	}
+
+	if len(args) > 0 {
+		variables.Set(template.ClusterNameVariable, args[0])
+	}
+	if cco.KubernetesVersion != "" {
+		variables.Set(template.KubernetesVersionVariable, cco.KubernetesVersion)
+	}
+	if _, ok := variables.Get(template.NamespaceVariable); !ok || cmd.Flags().Changed("target-namespace") {
+		variables.Set(template.NamespaceVariable, cco.Namespace)
+	}
+	if _, ok := variables.Get(template.ControlPlaneMachineCountVariable); !ok || cmd.Flags().Changed("control-plane-machine-count") {
+		variables.Set(template.ControlPlaneMachineCountVariable, strconv.Itoa(cco.ControlPlaneMachineCount))
+	}
+	if _, ok := variables.Get(template.WorkerMachineCountVariable); !ok || cmd.Flags().Changed("worker-machine-count") {
+		variables.Set(template.WorkerMachineCountVariable, strconv.Itoa(cco.WorkerMachineCount))
+	}
+	return variables, nil
+}
+
+func init() {
+	// Required flags
+	configClusterCmd.Flags().StringVarP(&cco.Template, "from", "f", "", "A yaml file containing the cluster template of the provider. Required.")
+	configClusterCmd.MarkFlagRequired("from")
+
+	// Optional flags
+	configClusterCmd.Flags().StringVarP(&cco.ConfigFile, "config", "", "", "A yaml file containing the values of the template variables (default $HOME/.cluster-api/clusterctl.yaml)")
+	configClusterCmd.Flags().StringVarP(&cco.Namespace, "target-namespace", "n", "default", "The namespace of the cluster objects")
+	configClusterCmd.Flags().StringVarP(&cco.KubernetesVersion, "kubernetes-version", "", "", "The Kubernetes version of the machines")
+	configClusterCmd.Flags().IntVarP(&cco.ControlPlaneMachineCount, "control-plane-machine-count", "", 1, "The number of control plane machines")
+	configClusterCmd.Flags().IntVarP(&cco.WorkerMachineCount, "worker-machine-count", "", 0, "The number of worker machines")
+	configClusterCmd.Flags().StringArrayVarP(&cco.Variables, "set", "", nil, "Set the value of a template variable, as NAME=VALUE")
+	configClusterCmd.Flags().BoolVarP(&cco.ListVariables, "list-variables", "", false, "List the variables of the template and exit")
+	configClusterCmd.Flags().StringVarP(&cco.Output, "output", "o", "", "Where to write the manifests instead of stdout")
+
+	configCmd.AddCommand(configClusterCmd)
+}
@@ -19,6 +19,7 @@ package cmd
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -38,6 +39,8 @@ type CreateOptions struct {
 	Provider                string
 	KubeconfigOutput        string
 	BootstrapFlags          bootstrap.Options
+	StateFile               string
+	Resume                  bool
 }
 
 var co = &CreateOptions{}
@@ -50,9 +53,6 @@ var createClusterCmd = &cobra.Command{
 		if co.Cluster == "" {
 			exitWithHelp(cmd, "Please provide yaml file for cluster definition.\n")
 		}
-		if co.Machine == "" {
-			exitWithHelp(cmd, "Please provide yaml file for machine definition.\n")
-		}
 		if co.ProviderComponents == "" {
 			exitWithHelp(cmd, "Please provide yaml file for provider component definition.\n")
 		}
@@ -71,15 +71,50 @@ func RunCreate(co *CreateOptions) error {
 	if len(clusterOut.Clusters) == 0 {
 		return errors.Errorf("no Cluster object found in file %q", paco.Cluster)
 	}
-	machineOut, err := yaml.Parse(yaml.ParseInput{File: co.Machine})
-	if err != nil {
-		return err
+	// The machines can be defined in the cluster file, e.g. when generated by clusterctl config cluster.
+	if co.Machine != "" {
+		machineOut, err := yaml.Parse(yaml.ParseInput{File: co.Machine})
+		if err != nil {
+			return err
+		}
+		clusterOut.Add(machineOut)
+	}
+
+	cluster := clusterOut.Clusters[0]
+
+	// The progress is only recorded when asked for, otherwise the bootstrap cluster is cleaned up on failure.
+	state := clusterdeployer.NewState("")
+	bootstrapOptions := co.BootstrapFlags
+	switch {
+	case co.Resume:
+		if co.StateFile == "" {
+			return errors.New("--resume requires the --state-file recording the creation to resume")
+		}
+		if state, err = clusterdeployer.LoadState(co.StateFile); err != nil {
+			return err
+		}
+		if state.ClusterName != cluster.Name || state.ClusterNamespace != cluster.Namespace {
+			return errors.Errorf("state file %q records the creation of cluster %q in namespace %q, not %q in namespace %q",
+				co.StateFile, state.ClusterName, state.ClusterNamespace, cluster.Name, cluster.Namespace)
+		}
+		// The bootstrap cluster is the one created by the previous run.
+		bootstrapOptions = state.Bootstrap
+	case co.StateFile != "":
+		if _, err := os.Stat(co.StateFile); err == nil {
+			return errors.Errorf("found the state of a previous creation in %q, use --resume to resume it or delete the file to start over", co.StateFile)
+		}
+		state = clusterdeployer.NewState(co.StateFile)
 	}
 
-	bootstrapProvider, err := bootstrap.Get(co.BootstrapFlags)
+	bootstrapProvider, err := bootstrap.Get(bootstrapOptions)
 	if err != nil {
 		return err
 	}
+	if !co.Resume {
+		state.Bootstrap = bootstrap.ResumeOptions(bootstrapOptions, bootstrapProvider)
+		state.ClusterName = cluster.Name
+		state.ClusterNamespace = cluster.Namespace
+	}
 
 	pc, err := ioutil.ReadFile(co.ProviderComponents)
 	if err != nil {
@@ -106,24 +141,31 @@ func RunCreate(co *CreateOptions) error {
 		string(pc),
 		string(ac),
 		string(bc),
-		co.BootstrapFlags.Cleanup)
+		bootstrapOptions.Cleanup)
 
-	return d.Create(clusterOut.Add(machineOut), co.KubeconfigOutput, pcsFactory)
+	if err := d.CreateWithState(clusterOut, co.KubeconfigOutput, pcsFactory, state); err != nil {
+		if state.IsPersisted() && len(state.CompletedPhases) > 0 {
+			return errors.Wrapf(err, "the creation did not complete, run the same command with --resume to resume it")
+		}
+		return err
+	}
+	return state.Remove()
 }
 
 func init() {
 	// Required flags
 	createClusterCmd.Flags().StringVarP(&co.Cluster, "cluster", "c", "", "A yaml file containing cluster object definition. Required.")
 	createClusterCmd.MarkFlagRequired("cluster")
-	createClusterCmd.Flags().StringVarP(&co.Machine, "machines", "m", "", "A yaml file containing machine object definition(s). Required.")
-	createClusterCmd.MarkFlagRequired("machines")
 	createClusterCmd.Flags().StringVarP(&co.ProviderComponents, "provider-components", "p", "", "A yaml file containing cluster api provider controllers and supporting objects. Required.")
 	createClusterCmd.MarkFlagRequired("provider-components")
 
 	// Optional flags
+	createClusterCmd.Flags().StringVarP(&co.Machine, "machines", "m", "", "A yaml file containing machine object definition(s), if not defined in the cluster file")
 	createClusterCmd.Flags().StringVarP(&co.AddonComponents, "addon-components", "a", "", "A yaml file containing cluster addons to apply to the internal cluster")
 	createClusterCmd.Flags().StringVarP(&co.BootstrapOnlyComponents, "bootstrap-only-components", "", "", "A yaml file containing components to apply only on the bootstrap cluster (before the provider components are applied) but not the provisioned cluster")
 	createClusterCmd.Flags().StringVarP(&co.KubeconfigOutput, "kubeconfig-out", "", "kubeconfig", "Where to output the kubeconfig for the provisioned cluster")
+	createClusterCmd.Flags().StringVarP(&co.StateFile, "state-file", "", "", "A file to record the progress of the creation to, so that it can be resumed with --resume if it fails or is interrupted. The bootstrap cluster is then kept on failure. The file is removed once the creation is complete")
+	createClusterCmd.Flags().BoolVarP(&co.Resume, "resume", "", false, "Resume the creation recorded in --state-file, skipping the completed phases")
 
 	co.BootstrapFlags.AddFlags(createClusterCmd.Flags())
 	createCmd.AddCommand(createClusterCmd)
@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/clientcmd"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/clusterclient"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/phases"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+)
+
+type InitOptions struct {
+	Kubeconfig              string
+	ConfigFile              string
+	CoreProvider            string
+	BootstrapProviders      []string
+	InfrastructureProviders []string
+	TargetNamespace         string
+	WatchingNamespace       string
+}
+
+var ino = &InitOptions{}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Install Cluster API providers in a management cluster",
+	Long: `Install Cluster API providers in a management cluster.
+
+Providers are given as NAME or NAME:VERSION, the latest version of the repository of
+the provider is installed if the version is omitted. The core provider is installed
+unless it is already installed in the management cluster.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(deprecationMsg)
+		if err := RunInit(ino); err != nil {
+			klog.Exit(err)
+		}
+	},
+}
+
+func RunInit(ino *InitOptions) error {
+	providers, variables, err := loadProvidersConfig(ino.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	client, err := clusterclient.NewFromDefaultSearchPath(ino.Kubeconfig, clientcmd.NewConfigOverrides())
+	if err != nil {
+		return errors.Wrap(err, "error when creating cluster client")
+	}
+	defer client.Close()
+
+	if err := client.EnsureProviderInventory(); err != nil {
+		return err
+	}
+	installed, err := client.GetProviders()
+	if err != nil {
+		return err
+	}
+
+	requested := map[clusterctlv1.ProviderType][]string{
+		clusterctlv1.BootstrapProviderType:      ino.BootstrapProviders,
+		clusterctlv1.InfrastructureProviderType: ino.InfrastructureProviders,
+	}
+	if ino.CoreProvider != "" {
+		requested[clusterctlv1.CoreProviderType] = []string{ino.CoreProvider}
+	} else if !hasCoreProvider(installed) {
+		requested[clusterctlv1.CoreProviderType] = []string{repository.CoreProviderName}
+	}
+
+	var components []*repository.Components
+	for _, providerType := range []clusterctlv1.ProviderType{clusterctlv1.CoreProviderType, clusterctlv1.BootstrapProviderType, clusterctlv1.InfrastructureProviderType} {
+		for _, value := range requested[providerType] {
+			name, version := parseProviderFlag(value)
+			provider, err := repository.Find(providers, providerType, name)
+			if err != nil {
+				return err
+			}
+			c, err := repository.GetComponents(provider, variables, repository.ComponentsOptions{
+				Version:           version,
+				TargetNamespace:   ino.TargetNamespace,
+				WatchingNamespace: ino.WatchingNamespace,
+			})
+			if err != nil {
+				return err
+			}
+			components = append(components, c)
+		}
+	}
+	if len(components) == 0 {
+		return errors.New("no provider to install")
+	}
+
+	return phases.InstallProviders(client, components)
+}
+
+// parseProviderFlag splits a NAME:VERSION provider flag.
+func parseProviderFlag(value string) (string, string) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func hasCoreProvider(installed []*clusterctlv1.Provider) bool {
+	for _, p := range installed {
+		if p.GetProviderType() == clusterctlv1.CoreProviderType {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	initCmd.Flags().StringVarP(&ino.Kubeconfig, "kubeconfig", "", "", "Path to the kubeconfig file of the management cluster, if empty, the default KUBECONFIG load path is used.")
+	initCmd.Flags().StringVarP(&ino.ConfigFile, "config", "", "", "The clusterctl config file defining the providers and the values of the variables of their components (default $HOME/.cluster-api/clusterctl.yaml)")
+	initCmd.Flags().StringVarP(&ino.CoreProvider, "core", "", "", "The core provider to install, as NAME[:VERSION] (default cluster-api if not installed)")
+	initCmd.Flags().StringSliceVarP(&ino.BootstrapProviders, "bootstrap", "b", nil, "Bootstrap providers to install, as NAME[:VERSION]")
+	initCmd.Flags().StringSliceVarP(&ino.InfrastructureProviders, "infrastructure", "i", nil, "Infrastructure providers to install, as NAME[:VERSION]")
+	initCmd.Flags().StringVarP(&ino.TargetNamespace, "target-namespace", "", "", "The namespace to install the providers in, defaults to the namespace defined by their components")
+	initCmd.Flags().StringVarP(&ino.WatchingNamespace, "watching-namespace", "", "", "The namespace the providers watch, defaults to all the namespaces")
+
+	RootCmd.AddCommand(initCmd)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type UpgradeOptions struct {
+	Kubeconfig string
+	ConfigFile string
+	Contract   string
+}
+
+var uo = &UpgradeOptions{}
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade the Cluster API providers of a management cluster",
+	Long:  `Upgrade the Cluster API providers of a management cluster. See subcommands for the upgrade steps.`,
+}
+
+func init() {
+	upgradeCmd.PersistentFlags().StringVarP(&uo.Kubeconfig, "kubeconfig", "", "", "Path to the kubeconfig file of the management cluster, if empty, the default KUBECONFIG load path is used.")
+	upgradeCmd.PersistentFlags().StringVarP(&uo.ConfigFile, "config", "", "", "The clusterctl config file defining the providers and the values of the variables of their components (default $HOME/.cluster-api/clusterctl.yaml)")
+	RootCmd.AddCommand(upgradeCmd)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/clientcmd"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/clusterclient"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/phases"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/upgrade"
+)
+
+var upgradeApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Upgrade the installed providers",
+	Long: `Upgrade the installed providers to their latest versions implementing a Cluster API contract.
+
+The providers are upgraded in order, the core provider first, waiting for the controllers
+of each provider to be rolled out before upgrading the next one.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if uo.Contract == "" {
+			exitWithHelp(cmd, "Please provide the contract to upgrade to.\n")
+		}
+		fmt.Println(deprecationMsg)
+		if err := RunUpgradeApply(uo); err != nil {
+			klog.Exit(err)
+		}
+	},
+}
+
+func RunUpgradeApply(uo *UpgradeOptions) error {
+	plans, err := getUpgradePlans(uo)
+	if err != nil {
+		return err
+	}
+	var plan *upgrade.Plan
+	for i := range plans {
+		if plans[i].Contract == uo.Contract {
+			plan = &plans[i]
+		}
+	}
+	if plan == nil {
+		return errors.Errorf("no upgrade plan found for contract %q, run clusterctl upgrade plan to list the available plans", uo.Contract)
+	}
+	if len(plan.Blockers) > 0 {
+		return errors.Errorf("the providers can't be upgraded to the %s contract: %s", plan.Contract, strings.Join(plan.Blockers, "; "))
+	}
+	if plan.IsUpToDate() {
+		fmt.Println("The providers are already up to date.")
+		return nil
+	}
+
+	_, variables, err := loadProvidersConfig(uo.ConfigFile)
+	if err != nil {
+		return err
+	}
+	var components []*repository.Components
+	for _, item := range plan.Items {
+		if item.NextVersion == "" {
+			continue
+		}
+		c, err := repository.GetComponents(item.Repository, variables, repository.ComponentsOptions{
+			Version:           item.NextVersion,
+			TargetNamespace:   item.Provider.Namespace,
+			WatchingNamespace: item.Provider.WatchedNamespace,
+		})
+		if err != nil {
+			return err
+		}
+		components = append(components, c)
+	}
+
+	client, err := clusterclient.NewFromDefaultSearchPath(uo.Kubeconfig, clientcmd.NewConfigOverrides())
+	if err != nil {
+		return errors.Wrap(err, "error when creating cluster client")
+	}
+	defer client.Close()
+
+	migrations, err := phases.UpgradeProviders(client, components)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		fmt.Printf("CRD %s has objects stored in versions [%s], they must be migrated to the storage version %s\n",
+			m.CRD, strings.Join(m.StoredVersions, ", "), m.StorageVersion)
+	}
+	return nil
+}
+
+func init() {
+	upgradeApplyCmd.Flags().StringVarP(&uo.Contract, "contract", "", "", "The Cluster API contract to upgrade to, e.g. v1alpha3. Required.")
+	upgradeApplyCmd.MarkFlagRequired("contract")
+	upgradeCmd.AddCommand(upgradeApplyCmd)
+}
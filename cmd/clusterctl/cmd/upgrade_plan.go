@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/clientcmd"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/clusterclient"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/upgrade"
+)
+
+var upgradePlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "List the upgrades available for the installed providers",
+	Long: `List the upgrades available for the installed providers.
+
+A plan is listed for the Cluster API contract implemented by the installed providers, and
+for each newer contract implemented by the versions available for the core provider.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(deprecationMsg)
+		if err := RunUpgradePlan(uo); err != nil {
+			klog.Exit(err)
+		}
+	},
+}
+
+func RunUpgradePlan(uo *UpgradeOptions) error {
+	plans, err := getUpgradePlans(uo)
+	if err != nil {
+		return err
+	}
+
+	for _, plan := range plans {
+		fmt.Printf("\nLatest versions of the providers for the %s contract:\n\n", plan.Contract)
+		w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tNAMESPACE\tTYPE\tCURRENT VERSION\tNEXT VERSION")
+		for _, item := range plan.Items {
+			next := item.NextVersion
+			if next == "" {
+				next = "Already up to date"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", item.Provider.ProviderName, item.Provider.Namespace, item.Provider.Type, item.Provider.Version, next)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Println()
+
+		switch {
+		case len(plan.Blockers) > 0:
+			fmt.Printf("The providers can't be upgraded to the %s contract:\n", plan.Contract)
+			for _, blocker := range plan.Blockers {
+				fmt.Printf("  - %s\n", blocker)
+			}
+		case plan.IsUpToDate():
+			fmt.Println("You are already up to date!")
+		default:
+			fmt.Printf("You can now apply the upgrade by executing the following command:\n\n")
+			fmt.Printf("  clusterctl upgrade apply --contract %s\n", plan.Contract)
+		}
+	}
+	return nil
+}
+
+func getUpgradePlans(uo *UpgradeOptions) ([]upgrade.Plan, error) {
+	providers, _, err := loadProvidersConfig(uo.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	client, err := clusterclient.NewFromDefaultSearchPath(uo.Kubeconfig, clientcmd.NewConfigOverrides())
+	if err != nil {
+		return nil, errors.Wrap(err, "error when creating cluster client")
+	}
+	defer client.Close()
+
+	if err := client.EnsureProviderInventory(); err != nil {
+		return nil, err
+	}
+	installed, err := client.GetProviders()
+	if err != nil {
+		return nil, err
+	}
+	return upgrade.GetPlans(installed, providers)
+}
+
+func init() {
+	upgradeCmd.AddCommand(upgradePlanCmd)
+}
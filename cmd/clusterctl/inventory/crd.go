@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+// CRD is the manifest of the CustomResourceDefinition of the clusterctl Provider objects,
+// which record the providers installed in a management cluster.
+const CRD = `---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  creationTimestamp: null
+  name: providers.clusterctl.cluster.x-k8s.io
+spec:
+  additionalPrinterColumns:
+  - JSONPath: .type
+    name: Type
+    type: string
+  - JSONPath: .providerName
+    name: Provider
+    type: string
+  - JSONPath: .version
+    name: Version
+    type: string
+  - JSONPath: .watchedNamespace
+    name: Watch Namespace
+    type: string
+  group: clusterctl.cluster.x-k8s.io
+  names:
+    kind: Provider
+    listKind: ProviderList
+    plural: providers
+    singular: provider
+  scope: Namespaced
+  version: v1alpha3
+  versions:
+  - name: v1alpha3
+    schema:
+      openAPIV3Schema:
+        description: Provider is the Schema for the providers API, recording a provider
+          installed by clusterctl. The Provider object is created in the namespace
+          of the provider components.
+        properties:
+          apiVersion:
+            description: 'APIVersion defines the versioned schema of this representation
+              of an object. Servers should convert recognized schemas to the latest
+              internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources'
+            type: string
+          kind:
+            description: 'Kind is a string value representing the REST resource this
+              object represents. Servers may infer this from the endpoint the client
+              submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds'
+            type: string
+          metadata:
+            type: object
+          providerName:
+            description: ProviderName is the name of the provider, e.g. cluster-api
+              or docker.
+            type: string
+          type:
+            description: Type is the type of the provider.
+            type: string
+          version:
+            description: Version is the version of the provider components.
+            type: string
+          watchedNamespace:
+            description: WatchedNamespace is the namespace watched by the provider
+              controllers. An empty value means all the namespaces.
+            type: string
+        type: object
+    served: true
+    storage: true
+status:
+  acceptedNames:
+    kind: ""
+    plural: ""
+  conditions: []
+  storedVersions: []
+`
@@ -66,10 +66,16 @@ func TestEmptyAndInvalidArgs(t *testing.T) {
 		{"create with no arguments with invalid flag", []string{"create", "--invalid-flag"}, 1, "create-no-args-invalid-flag.golden"},
 		{"create cluster with no arguments", []string{"create", "cluster"}, 1, "create-cluster-no-args.golden"},
 		{"create cluster with no arguments with invalid flag", []string{"create", "cluster", "--invalid-flag"}, 1, "create-cluster-no-args-invalid-flag.golden"},
+		{"config with no arguments", []string{"config"}, 0, "config-no-args.golden"},
+		{"config cluster with no arguments", []string{"config", "cluster"}, 1, "config-cluster-no-args.golden"},
+		{"config cluster with no arguments with invalid flag", []string{"config", "cluster", "--invalid-flag"}, 1, "config-cluster-no-args-invalid-flag.golden"},
+		{"init with invalid flag", []string{"init", "--invalid-flag"}, 1, "init-invalid-flag.golden"},
 		{"delete with no arguments", []string{"delete"}, 0, "delete-no-args.golden"},
 		{"delete with no arguments with invalid flag", []string{"delete", "--invalid-flag"}, 1, "delete-no-args-invalid-flag.golden"},
 		{"delete cluster with no arguments", []string{"delete", "cluster"}, 1, "delete-cluster-no-args.golden"},
 		{"delete cluster with no arguments with invalid flag", []string{"delete", "cluster", "--invalid-flag"}, 1, "delete-cluster-no-args-invalid-flag.golden"},
+		{"upgrade with no arguments", []string{"upgrade"}, 0, "upgrade-no-args.golden"},
+		{"upgrade apply with no arguments", []string{"upgrade", "apply"}, 1, "upgrade-apply-no-args.golden"},
 		{"validate with no arguments", []string{"validate"}, 0, "validate-no-args.golden"},
 		{"validate with no arguments with invalid flag", []string{"validate", "--invalid-flag"}, 1, "validate-no-args-invalid-flag.golden"},
 		{"validate cluster with no arguments with invalid flag", []string{"validate", "cluster", "--invalid-flag"}, 1, "validate-cluster-no-args-invalid-flag.golden"},
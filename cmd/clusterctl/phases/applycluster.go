@@ -36,13 +36,13 @@ func ApplyCluster(client clusterclient.Client, cluster *clusterv1.Cluster, extra
 
 	for _, e := range extra {
 		klog.Infof("Creating Cluster referenced object %q with name %q in namespace %q", e.GroupVersionKind(), e.GetName(), e.GetNamespace())
-		if err := client.CreateUnstructuredObject(e); err != nil {
+		if err := ignoreAlreadyExists(client.CreateUnstructuredObject(e)); err != nil {
 			return err
 		}
 	}
 
 	klog.Infof("Creating cluster object %v in namespace %q", cluster.Name, cluster.Namespace)
-	if err := client.CreateClusterObject(cluster); err != nil {
+	if err := ignoreAlreadyExists(client.CreateClusterObject(cluster)); err != nil {
 		return err
 	}
 
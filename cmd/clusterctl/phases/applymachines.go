@@ -36,7 +36,7 @@ func ApplyMachines(client clusterclient.Client, namespace string, machines []*cl
 
 	for _, e := range extra {
 		klog.Infof("Creating Machine referenced object %q with name %q in namespace %q", e.GroupVersionKind(), e.GetName(), e.GetNamespace())
-		if err := client.CreateUnstructuredObject(e); err != nil {
+		if err := ignoreAlreadyExists(client.CreateUnstructuredObject(e)); err != nil {
 			return err
 		}
 	}
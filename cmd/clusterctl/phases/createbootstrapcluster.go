@@ -26,11 +26,17 @@ import (
 func CreateBootstrapCluster(provisioner bootstrap.ClusterProvisioner, cleanupBootstrapCluster bool, clientFactory clusterclient.Factory) (clusterclient.Client, func(), error) {
 	klog.Info("Preparing bootstrap cluster")
 
-	cleanupFn := func() {}
 	if err := provisioner.Create(); err != nil {
-		return nil, cleanupFn, errors.Wrap(err, "could not create bootstrap control plane")
+		return nil, func() {}, errors.Wrap(err, "could not create bootstrap control plane")
 	}
 
+	return ConnectBootstrapCluster(provisioner, cleanupBootstrapCluster, clientFactory)
+}
+
+// ConnectBootstrapCluster returns a client for a bootstrap cluster that has already been created,
+// e.g. by a previous run of clusterctl that did not complete.
+func ConnectBootstrapCluster(provisioner bootstrap.ClusterProvisioner, cleanupBootstrapCluster bool, clientFactory clusterclient.Factory) (clusterclient.Client, func(), error) {
+	cleanupFn := func() {}
 	if cleanupBootstrapCluster {
 		cleanupFn = func() {
 			klog.Info("Cleaning up bootstrap cluster.")
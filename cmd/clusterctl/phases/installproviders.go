@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+)
+
+type providerInstallerClient interface {
+	Apply(string) error
+	GetProviders() ([]*clusterctlv1.Provider, error)
+	SaveProvider(*clusterctlv1.Provider) error
+}
+
+// InstallProviders applies the components of providers and records them in the provider inventory,
+// which must exist. Installing a provider already installed in the same namespace fails, it must be
+// upgraded instead.
+func InstallProviders(client providerInstallerClient, components []*repository.Components) error {
+	installed, err := client.GetProviders()
+	if err != nil {
+		return err
+	}
+	for _, c := range components {
+		for _, p := range installed {
+			if p.Namespace == c.TargetNamespace && p.Name == c.Provider.InstanceName() {
+				return errors.Errorf("%s %q is already installed in namespace %q with version %s", c.Provider.Type, c.Provider.Name, p.Namespace, p.Version)
+			}
+		}
+	}
+
+	for _, c := range components {
+		klog.Infof("Installing %s %q version %s in namespace %q", c.Provider.Type, c.Provider.Name, c.Version, c.TargetNamespace)
+		manifest, err := c.Yaml()
+		if err != nil {
+			return err
+		}
+		if err := client.Apply(string(manifest)); err != nil {
+			return errors.Wrapf(err, "unable to apply the components of %s %q", c.Provider.Type, c.Provider.Name)
+		}
+		if err := client.SaveProvider(c.InventoryObject()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
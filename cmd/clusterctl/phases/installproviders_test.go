@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/template"
+)
+
+type installer struct {
+	applied   []string
+	providers []*clusterctlv1.Provider
+}
+
+func (i *installer) Apply(manifest string) error {
+	i.applied = append(i.applied, manifest)
+	return nil
+}
+
+func (i *installer) GetProviders() ([]*clusterctlv1.Provider, error) {
+	return i.providers, nil
+}
+
+func (i *installer) SaveProvider(provider *clusterctlv1.Provider) error {
+	i.providers = append(i.providers, provider)
+	return nil
+}
+
+func newComponents(t *testing.T, name string, providerType clusterctlv1.ProviderType) *repository.Components {
+	provider := repository.Provider{Name: name, Type: providerType}
+	data := []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: " + name + "-system\n")
+	c, err := repository.NewComponents(provider, "v0.3.0", data, &template.Variables{}, repository.ComponentsOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return c
+}
+
+func TestInstallProviders(t *testing.T) {
+	client := &installer{}
+	components := []*repository.Components{
+		newComponents(t, "cluster-api", clusterctlv1.CoreProviderType),
+		newComponents(t, "docker", clusterctlv1.InfrastructureProviderType),
+	}
+	if err := InstallProviders(client, components); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.applied) != 2 || !strings.Contains(client.applied[1], "name: docker-system") {
+		t.Errorf("unexpected applied manifests: %v", client.applied)
+	}
+	if len(client.providers) != 2 || client.providers[1].Name != "infrastructure-docker" || client.providers[1].Namespace != "docker-system" {
+		t.Errorf("unexpected inventory: %v", client.providers)
+	}
+}
+
+func TestInstallProvidersAlreadyInstalled(t *testing.T) {
+	client := &installer{
+		providers: []*clusterctlv1.Provider{{
+			ObjectMeta:   metav1.ObjectMeta{Namespace: "docker-system", Name: "infrastructure-docker"},
+			ProviderName: "docker",
+			Type:         string(clusterctlv1.InfrastructureProviderType),
+			Version:      "v0.2.0",
+		}},
+	}
+	components := []*repository.Components{
+		newComponents(t, "kubeadm", clusterctlv1.BootstrapProviderType),
+		newComponents(t, "docker", clusterctlv1.InfrastructureProviderType),
+	}
+	err := InstallProviders(client, components)
+	if err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Fatalf("expected an already installed error, got %v", err)
+	}
+	if len(client.applied) != 0 {
+		t.Errorf("expected no components to be applied, got %v", client.applied)
+	}
+}
@@ -202,7 +202,7 @@ func moveCluster(from sourceClient, to targetClient, cluster *clusterv1.Cluster)
 
 	// New objects cannot have a specified resource version. Clear it out.
 	cluster.SetResourceVersion("")
-	if err := to.CreateClusterObject(cluster); err != nil {
+	if err := ignoreAlreadyExists(to.CreateClusterObject(cluster)); err != nil {
 		return errors.Wrapf(err, "error copying Cluster %s/%s to target cluster", cluster.Namespace, cluster.Name)
 	}
 
@@ -283,7 +283,7 @@ func moveSecret(from sourceClient, to targetClient, secret *corev1.Secret, toClu
 	// Set the cluster owner ref based on target cluster's Cluster resource
 	to.SetClusterOwnerRef(secret, toCluster)
 
-	if err := to.CreateSecret(secret); err != nil {
+	if err := ignoreAlreadyExists(to.CreateSecret(secret)); err != nil {
 		return errors.Wrapf(err, "error copying Secret %s/%s to target cluster", secret.Namespace, secret.Name)
 	}
 
@@ -333,7 +333,7 @@ func moveMachineDeployment(from sourceClient, to targetClient, md *clusterv1.Mac
 	// Remove owner reference. This currently assumes that the only owner reference would be a Cluster.
 	md.SetOwnerReferences(nil)
 
-	if err := to.CreateMachineDeployments([]*clusterv1.MachineDeployment{md}, md.Namespace); err != nil {
+	if err := ignoreAlreadyExists(to.CreateMachineDeployments([]*clusterv1.MachineDeployment{md}, md.Namespace)); err != nil {
 		return errors.Wrapf(err, "error copying MachineDeployment %s/%s to target cluster", md.Namespace, md.Name)
 	}
 
@@ -387,7 +387,7 @@ func moveMachineSet(from sourceClient, to targetClient, ms *clusterv1.MachineSet
 	// Remove owner reference. This currently assumes that the only owner references would be a MachineDeployment and/or a Cluster.
 	ms.SetOwnerReferences(nil)
 
-	if err := to.CreateMachineSets([]*clusterv1.MachineSet{ms}, ms.Namespace); err != nil {
+	if err := ignoreAlreadyExists(to.CreateMachineSets([]*clusterv1.MachineSet{ms}, ms.Namespace)); err != nil {
 		return errors.Wrapf(err, "error copying MachineSet %s/%s to target cluster", ms.Namespace, ms.Name)
 	}
 	if err := from.ForceDeleteMachineSet(ms.Namespace, ms.Name); err != nil {
@@ -477,7 +477,7 @@ func moveUnstructured(from sourceClient, to targetClient, u *unstructured.Unstru
 	// Remove owner reference.
 	targetObject.SetOwnerReferences(nil)
 
-	if err := to.CreateUnstructuredObject(targetObject); err != nil {
+	if err := ignoreAlreadyExists(to.CreateUnstructuredObject(targetObject)); err != nil {
 		return errors.Wrapf(err, "error copying unstructured object %q %s/%s to target cluster",
 			u.GroupVersionKind(), u.GetNamespace(), u.GetName())
 	}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+)
+
+type providerUpgraderClient interface {
+	Apply(string) error
+	GetUnstructuredObject(*unstructured.Unstructured) error
+	SaveProvider(*clusterctlv1.Provider) error
+	WaitForDeploymentsReady(namespace string, labels map[string]string) error
+}
+
+// StorageMigration is a CRD with objects stored in versions other than its storage version,
+// which must be migrated before these versions are removed.
+type StorageMigration struct {
+	CRD            string
+	StorageVersion string
+	StoredVersions []string
+}
+
+// UpgradeProviders applies the components of the new versions of providers, in order, waiting for
+// the controllers of each provider to be rolled out before upgrading the next one, and updates the
+// provider inventory. It returns the CRDs of the components which need a storage version migration.
+func UpgradeProviders(client providerUpgraderClient, components []*repository.Components) ([]StorageMigration, error) {
+	var migrations []StorageMigration
+	for _, c := range components {
+		klog.Infof("Upgrading %s %q in namespace %q to version %s", c.Provider.Type, c.Provider.Name, c.TargetNamespace, c.Version)
+		manifest, err := c.Yaml()
+		if err != nil {
+			return nil, err
+		}
+		if err := client.Apply(string(manifest)); err != nil {
+			return nil, errors.Wrapf(err, "unable to apply the components of %s %q", c.Provider.Type, c.Provider.Name)
+		}
+		labels := map[string]string{clusterctlv1.ProviderLabelName: c.Provider.InstanceName()}
+		if err := client.WaitForDeploymentsReady(c.TargetNamespace, labels); err != nil {
+			return nil, errors.Wrapf(err, "the controllers of %s %q were not rolled out", c.Provider.Type, c.Provider.Name)
+		}
+		if err := client.SaveProvider(c.InventoryObject()); err != nil {
+			return nil, err
+		}
+
+		for _, obj := range c.Objs {
+			if obj.GetKind() != "CustomResourceDefinition" {
+				continue
+			}
+			migration, err := storageMigration(client, obj)
+			if err != nil {
+				return nil, err
+			}
+			if migration != nil {
+				migrations = append(migrations, *migration)
+			}
+		}
+	}
+	return migrations, nil
+}
+
+// storageMigration returns the storage migration needed by a CRD, if any.
+func storageMigration(client providerUpgraderClient, crd *unstructured.Unstructured) (*StorageMigration, error) {
+	storageVersion, _, _ := unstructured.NestedString(crd.Object, "spec", "version")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		if m, ok := v.(map[string]interface{}); ok && m["storage"] == true {
+			storageVersion, _ = m["name"].(string)
+		}
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(crd.GroupVersionKind())
+	existing.SetName(crd.GetName())
+	if err := client.GetUnstructuredObject(existing); err != nil {
+		return nil, err
+	}
+	stored, _, _ := unstructured.NestedStringSlice(existing.Object, "status", "storedVersions")
+	for _, v := range stored {
+		if v != storageVersion {
+			return &StorageMigration{CRD: crd.GetName(), StorageVersion: storageVersion, StoredVersions: stored}, nil
+		}
+	}
+	return nil, nil
+}
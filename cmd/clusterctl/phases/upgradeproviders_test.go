@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/template"
+)
+
+type upgrader struct {
+	installer
+	storedVersions map[string][]string
+	waited         []string
+}
+
+func (u *upgrader) GetUnstructuredObject(obj *unstructured.Unstructured) error {
+	return unstructured.SetNestedStringSlice(obj.Object, u.storedVersions[obj.GetName()], "status", "storedVersions")
+}
+
+func (u *upgrader) WaitForDeploymentsReady(namespace string, labels map[string]string) error {
+	u.waited = append(u.waited, namespace+"/"+labels[clusterctlv1.ProviderLabelName])
+	return nil
+}
+
+const upgradedComponents = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: capi-system
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: clusters.cluster.x-k8s.io
+spec:
+  versions:
+  - name: v1alpha2
+    storage: false
+  - name: v1alpha3
+    storage: true
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: machines.cluster.x-k8s.io
+spec:
+  version: v1alpha3
+`
+
+func TestUpgradeProviders(t *testing.T) {
+	provider := repository.Provider{Name: "cluster-api", Type: clusterctlv1.CoreProviderType}
+	c, err := repository.NewComponents(provider, "v0.3.0", []byte(upgradedComponents), &template.Variables{}, repository.ComponentsOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := &upgrader{
+		storedVersions: map[string][]string{
+			"clusters.cluster.x-k8s.io": {"v1alpha2", "v1alpha3"},
+			"machines.cluster.x-k8s.io": {"v1alpha3"},
+		},
+	}
+
+	migrations, err := UpgradeProviders(client, []*repository.Components{c})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []StorageMigration{{CRD: "clusters.cluster.x-k8s.io", StorageVersion: "v1alpha3", StoredVersions: []string{"v1alpha2", "v1alpha3"}}}
+	if !reflect.DeepEqual(migrations, expected) {
+		t.Errorf("migrations mismatch: got %v, want %v", migrations, expected)
+	}
+	if len(client.applied) != 1 {
+		t.Errorf("expected the components to be applied once, got %d", len(client.applied))
+	}
+	if expected := []string{"capi-system/cluster-api"}; !reflect.DeepEqual(client.waited, expected) {
+		t.Errorf("expected to wait for the rollout of %v, got %v", expected, client.waited)
+	}
+	if len(client.providers) != 1 || client.providers[0].Version != "v0.3.0" {
+		t.Errorf("expected the inventory to be updated, got %v", client.providers)
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/template"
+	"sigs.k8s.io/cluster-api/util/yaml"
+)
+
+const (
+	namespaceArgPrefix = "--namespace="
+)
+
+// clusterScopedKinds are the kinds of the cluster scoped objects usually found in components.
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// ComponentsOptions are the options to install the components of a provider.
+type ComponentsOptions struct {
+	// Version is the version of the components, defaults to the latest version of the repository.
+	Version string
+
+	// TargetNamespace is the namespace the components are installed in, defaults to the
+	// namespace defined by the components.
+	TargetNamespace string
+
+	// WatchingNamespace is the namespace watched by the controllers of the provider,
+	// defaults to all the namespaces.
+	WatchingNamespace string
+}
+
+// Components are the objects of the components of a provider, ready to be installed.
+type Components struct {
+	Provider          Provider
+	Version           string
+	TargetNamespace   string
+	WatchingNamespace string
+	Objs              []*unstructured.Unstructured
+}
+
+// GetComponents fetches the components of a provider from its repository and processes them:
+// the variables are replaced, the objects are moved to the target namespace, the controllers
+// are set to watch the watching namespace, and all the objects are labeled with the instance
+// name of the provider.
+func GetComponents(provider Provider, variables *template.Variables, options ComponentsOptions) (*Components, error) {
+	data, version, err := New(provider).Components(options.Version)
+	if err != nil {
+		return nil, err
+	}
+	return NewComponents(provider, version, data, variables, options)
+}
+
+// NewComponents processes the components of a provider, see GetComponents.
+func NewComponents(provider Provider, version string, data []byte, variables *template.Variables, options ComponentsOptions) (*Components, error) {
+	data, err := template.Process(data, variables)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to process the components of provider %q", provider.Name)
+	}
+	objs, err := yaml.ToUnstructured(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the components of provider %q", provider.Name)
+	}
+
+	c := &Components{
+		Provider:          provider,
+		Version:           version,
+		TargetNamespace:   options.TargetNamespace,
+		WatchingNamespace: options.WatchingNamespace,
+		Objs:              objs,
+	}
+
+	namespace, err := c.namespace()
+	if err != nil {
+		return nil, err
+	}
+	if c.TargetNamespace == "" {
+		c.TargetNamespace = namespace
+	}
+	if c.TargetNamespace == "" {
+		return nil, errors.Errorf("the components of provider %q don't define a namespace, the target namespace must be set", provider.Name)
+	}
+	if err := c.setNamespace(namespace); err != nil {
+		return nil, err
+	}
+	if c.WatchingNamespace != "" {
+		if err := c.setWatchingNamespace(); err != nil {
+			return nil, err
+		}
+	}
+	for _, obj := range c.Objs {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[clusterctlv1.ProviderLabelName] = provider.InstanceName()
+		obj.SetLabels(labels)
+	}
+	return c, nil
+}
+
+// namespace returns the namespace defined by the components, if any.
+func (c *Components) namespace() (string, error) {
+	var namespaces []string
+	for _, obj := range c.Objs {
+		if obj.GetKind() == "Namespace" {
+			namespaces = append(namespaces, obj.GetName())
+		}
+	}
+	if len(namespaces) > 1 {
+		return "", errors.Errorf("the components of provider %q define more than one namespace: %s", c.Provider.Name, strings.Join(namespaces, ", "))
+	}
+	if len(namespaces) == 1 {
+		return namespaces[0], nil
+	}
+	return "", nil
+}
+
+// setNamespace moves the objects of the components from their namespace to the target namespace.
+func (c *Components) setNamespace(namespace string) error {
+	for _, obj := range c.Objs {
+		switch {
+		case obj.GetKind() == "Namespace":
+			obj.SetName(c.TargetNamespace)
+		case !clusterScopedKinds[obj.GetKind()]:
+			obj.SetNamespace(c.TargetNamespace)
+		}
+
+		if namespace == "" || namespace == c.TargetNamespace {
+			continue
+		}
+		switch obj.GetKind() {
+		case "ClusterRoleBinding", "RoleBinding":
+			if err := replaceNestedNamespaces(obj, namespace, c.TargetNamespace, []string{"subjects"}, "namespace"); err != nil {
+				return err
+			}
+		case "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration":
+			if err := replaceNestedNamespaces(obj, namespace, c.TargetNamespace, []string{"webhooks"}, "clientConfig", "service", "namespace"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replaceNestedNamespaces replaces a namespace by another in the field at the given path of
+// each item of a list.
+func replaceNestedNamespaces(obj *unstructured.Unstructured, from, to string, listPath []string, fieldPath ...string) error {
+	items, ok, err := unstructured.NestedSlice(obj.Object, listPath...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s of %s %q", strings.Join(listPath, "."), obj.GetKind(), obj.GetName())
+	}
+	if !ok {
+		return nil
+	}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ns, _, _ := unstructured.NestedString(m, fieldPath...); ns == from {
+			if err := unstructured.SetNestedField(m, to, fieldPath...); err != nil {
+				return errors.Wrapf(err, "failed to set namespace of %s %q", obj.GetKind(), obj.GetName())
+			}
+		}
+	}
+	if err := unstructured.SetNestedSlice(obj.Object, items, listPath...); err != nil {
+		return errors.Wrapf(err, "failed to set %s of %s %q", strings.Join(listPath, "."), obj.GetKind(), obj.GetName())
+	}
+	return nil
+}
+
+// setWatchingNamespace sets the --namespace argument of the containers of the Deployments of the
+// components, restricting the controllers to the watching namespace.
+func (c *Components) setWatchingNamespace() error {
+	for _, obj := range c.Objs {
+		if obj.GetKind() != "Deployment" {
+			continue
+		}
+		containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		if err != nil {
+			return errors.Wrapf(err, "failed to read containers of Deployment %q", obj.GetName())
+		}
+		for _, item := range containers {
+			container, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			args, _, _ := unstructured.NestedStringSlice(container, "args")
+			found := false
+			for i, arg := range args {
+				if strings.HasPrefix(arg, namespaceArgPrefix) {
+					args[i] = namespaceArgPrefix + c.WatchingNamespace
+					found = true
+				}
+			}
+			if !found {
+				args = append(args, namespaceArgPrefix+c.WatchingNamespace)
+			}
+			if err := unstructured.SetNestedStringSlice(container, args, "args"); err != nil {
+				return errors.Wrapf(err, "failed to set args of Deployment %q", obj.GetName())
+			}
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers"); err != nil {
+			return errors.Wrapf(err, "failed to set containers of Deployment %q", obj.GetName())
+		}
+	}
+	return nil
+}
+
+// Yaml returns the manifest of the components.
+func (c *Components) Yaml() ([]byte, error) {
+	return yaml.FromUnstructured(c.Objs)
+}
+
+// InventoryObject returns the Provider object recording the installation of the components.
+func (c *Components) InventoryObject() *clusterctlv1.Provider {
+	return &clusterctlv1.Provider{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterctlv1.GroupVersion.String(),
+			Kind:       "Provider",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Provider.InstanceName(),
+			Namespace: c.TargetNamespace,
+			Labels: map[string]string{
+				clusterctlv1.ProviderLabelName: c.Provider.InstanceName(),
+			},
+		},
+		ProviderName:     c.Provider.Name,
+		Type:             string(c.Provider.Type),
+		Version:          c.Version,
+		WatchedNamespace: c.WatchingNamespace,
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository_test
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/template"
+)
+
+const components = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: capd-system
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: dockerclusters.infrastructure.cluster.x-k8s.io
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: capd-manager-rolebinding
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: capd-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: capd-controller-manager
+  namespace: capd-system
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        image: gcr.io/k8s-staging-capi-docker/capd-manager:${TAG:=dev}
+        args:
+        - --enable-leader-election
+`
+
+func TestNewComponents(t *testing.T) {
+	provider := repository.Provider{Name: "docker", Type: clusterctlv1.InfrastructureProviderType}
+	variables := &template.Variables{LookupEnv: func(string) (string, bool) { return "", false }}
+
+	testCases := []struct {
+		name              string
+		options           repository.ComponentsOptions
+		expectedNamespace string
+		expectedArgs      []string
+	}{
+		{
+			name:              "default namespace",
+			expectedNamespace: "capd-system",
+			expectedArgs:      []string{"--enable-leader-election"},
+		},
+		{
+			name:              "target and watching namespaces",
+			options:           repository.ComponentsOptions{TargetNamespace: "docker", WatchingNamespace: "clusters"},
+			expectedNamespace: "docker",
+			expectedArgs:      []string{"--enable-leader-election", "--namespace=clusters"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := repository.NewComponents(provider, "v0.3.0", []byte(components), variables, tc.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.TargetNamespace != tc.expectedNamespace {
+				t.Errorf("target namespace mismatch: got %q, want %q", c.TargetNamespace, tc.expectedNamespace)
+			}
+			if len(c.Objs) != 4 {
+				t.Fatalf("expected 4 objects, got %d", len(c.Objs))
+			}
+			for _, obj := range c.Objs {
+				if obj.GetLabels()[clusterctlv1.ProviderLabelName] != "infrastructure-docker" {
+					t.Errorf("expected %s %q to be labeled with the provider", obj.GetKind(), obj.GetName())
+				}
+			}
+
+			namespace, crd, binding, deployment := c.Objs[0], c.Objs[1], c.Objs[2], c.Objs[3]
+			if namespace.GetName() != tc.expectedNamespace {
+				t.Errorf("namespace mismatch: got %q, want %q", namespace.GetName(), tc.expectedNamespace)
+			}
+			if crd.GetNamespace() != "" {
+				t.Errorf("expected the CRD to be cluster scoped, got namespace %q", crd.GetNamespace())
+			}
+			subjects, _, _ := unstructured.NestedSlice(binding.Object, "subjects")
+			if ns := subjects[0].(map[string]interface{})["namespace"]; ns != tc.expectedNamespace {
+				t.Errorf("subject namespace mismatch: got %q, want %q", ns, tc.expectedNamespace)
+			}
+			if deployment.GetNamespace() != tc.expectedNamespace {
+				t.Errorf("deployment namespace mismatch: got %q, want %q", deployment.GetNamespace(), tc.expectedNamespace)
+			}
+			containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+			container := containers[0].(map[string]interface{})
+			args, _, _ := unstructured.NestedStringSlice(container, "args")
+			if !reflect.DeepEqual(args, tc.expectedArgs) {
+				t.Errorf("args mismatch: got %v, want %v", args, tc.expectedArgs)
+			}
+			if image := container["image"]; image != "gcr.io/k8s-staging-capi-docker/capd-manager:dev" {
+				t.Errorf("expected the variables to be replaced, got image %q", image)
+			}
+
+			inventory := c.InventoryObject()
+			if inventory.Name != "infrastructure-docker" || inventory.Namespace != tc.expectedNamespace ||
+				inventory.ProviderName != "docker" || inventory.Type != "InfrastructureProvider" ||
+				inventory.Version != "v0.3.0" || inventory.WatchedNamespace != tc.options.WatchingNamespace {
+				t.Errorf("unexpected inventory object: %+v", inventory)
+			}
+		})
+	}
+}
+
+func TestNewComponentsWithoutNamespace(t *testing.T) {
+	provider := repository.Provider{Name: "docker", Type: clusterctlv1.InfrastructureProviderType}
+	variables := &template.Variables{LookupEnv: func(string) (string, bool) { return "", false }}
+	data := []byte("apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: manager\n")
+
+	if _, err := repository.NewComponents(provider, "v0.3.0", data, variables, repository.ComponentsOptions{}); err == nil {
+		t.Errorf("expected an error when the target namespace can't be determined")
+	}
+	c, err := repository.NewComponents(provider, "v0.3.0", data, variables, repository.ComponentsOptions{TargetNamespace: "docker"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Objs[0].GetNamespace() != "docker" {
+		t.Errorf("expected the service account to be moved to the target namespace, got %q", c.Objs[0].GetNamespace())
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// MetadataFileName is the name of the metadata file at the root of a repository, or next
+	// to a components file.
+	MetadataFileName = "metadata.yaml"
+)
+
+// ReleaseSeries maps a minor release series of a provider to the Cluster API contract it implements.
+type ReleaseSeries struct {
+	Major    uint   `json:"major"`
+	Minor    uint   `json:"minor"`
+	Contract string `json:"contract"`
+}
+
+// Metadata is the metadata of the releases of a provider, e.g.
+//
+//	releaseSeries:
+//	- major: 0
+//	  minor: 2
+//	  contract: v1alpha2
+//	- major: 0
+//	  minor: 3
+//	  contract: v1alpha3
+type Metadata struct {
+	ReleaseSeries []ReleaseSeries `json:"releaseSeries"`
+}
+
+// Contract returns the Cluster API contract implemented by a version of the provider.
+func (m *Metadata) Contract(v string) (string, error) {
+	parsed, err := version.ParseSemantic(v)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid version %q", v)
+	}
+	for _, series := range m.ReleaseSeries {
+		if parsed.Major() == series.Major && parsed.Minor() == series.Minor {
+			return series.Contract, nil
+		}
+	}
+	return "", errors.Errorf("no release series found for version %q", v)
+}
+
+// Metadata returns the metadata of the releases in the repository.
+func (r *Repository) Metadata() (*Metadata, error) {
+	isFile, err := r.isFile()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(r.URL, MetadataFileName)
+	if isFile {
+		path = filepath.Join(filepath.Dir(r.URL), MetadataFileName)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read metadata of repository %q", r.URL)
+	}
+	metadata := &Metadata{}
+	if err := yaml.Unmarshal(data, metadata); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse metadata of repository %q", r.URL)
+	}
+	return metadata, nil
+}
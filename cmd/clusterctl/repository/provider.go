@@ -0,0 +1,123 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/homedir"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// CoreProviderName is the name of the Cluster API core provider.
+	CoreProviderName = "cluster-api"
+)
+
+// Provider defines a provider and the location of its repository.
+type Provider struct {
+	// Name is the name of the provider, e.g. docker.
+	Name string `json:"name"`
+
+	// Type is the type of the provider.
+	Type clusterctlv1.ProviderType `json:"type"`
+
+	// URL is the path of the repository of the provider, or of its components file.
+	URL string `json:"url"`
+}
+
+// InstanceName returns the name of the Provider objects recording the provider.
+func (p Provider) InstanceName() string {
+	return clusterctlv1.InstanceName(p.Type, p.Name)
+}
+
+// DefaultRoot returns the directory containing the repositories of the providers without URL.
+func DefaultRoot() string {
+	return filepath.Join(homedir.HomeDir(), ".cluster-api", "repository")
+}
+
+// DefaultProviders returns the providers known by clusterctl, whose repositories are
+// the directories named after their instance name in the default root.
+func DefaultProviders() []Provider {
+	providers := []Provider{
+		{Name: CoreProviderName, Type: clusterctlv1.CoreProviderType},
+		{Name: "kubeadm", Type: clusterctlv1.BootstrapProviderType},
+		{Name: "docker", Type: clusterctlv1.InfrastructureProviderType},
+	}
+	for i := range providers {
+		providers[i].URL = filepath.Join(DefaultRoot(), providers[i].InstanceName())
+	}
+	return providers
+}
+
+// LoadProviders returns the default providers, overridden or extended by the providers
+// defined under the providers key of a clusterctl config file. An empty path only
+// returns the default providers.
+func LoadProviders(configFile string) ([]Provider, error) {
+	providers := DefaultProviders()
+	if configFile == "" {
+		return providers, nil
+	}
+
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file %q", configFile)
+	}
+	config := struct {
+		Providers []Provider `json:"providers"`
+	}{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config file %q", configFile)
+	}
+
+	for _, p := range config.Providers {
+		if p.Name == "" || p.URL == "" {
+			return nil, errors.Errorf("invalid provider %q in config file %q, name and url are required", p.Name, configFile)
+		}
+		switch p.Type {
+		case clusterctlv1.CoreProviderType, clusterctlv1.BootstrapProviderType, clusterctlv1.InfrastructureProviderType:
+		default:
+			return nil, errors.Errorf("invalid type %q for provider %q in config file %q", p.Type, p.Name, configFile)
+		}
+		if found := find(providers, p.Type, p.Name); found != nil {
+			found.URL = p.URL
+			continue
+		}
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
+
+// Find returns the provider with the given type and name.
+func Find(providers []Provider, providerType clusterctlv1.ProviderType, name string) (Provider, error) {
+	if p := find(providers, providerType, name); p != nil {
+		return *p, nil
+	}
+	return Provider{}, errors.Errorf("unknown %s %q, it must be defined in the clusterctl config file", providerType, name)
+}
+
+func find(providers []Provider, providerType clusterctlv1.ProviderType, name string) *Provider {
+	for i := range providers {
+		if providers[i].Type == providerType && providers[i].Name == name {
+			return &providers[i]
+		}
+	}
+	return nil
+}
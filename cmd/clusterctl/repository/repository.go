@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+const (
+	// ComponentsFileName is the name of the components file in each version directory of a repository.
+	ComponentsFileName = "components.yaml"
+)
+
+// Repository is a file based repository of the components of a provider. It is either a
+// directory with a sub directory per version containing the components file, e.g.
+//
+//	<url>/v0.3.0/components.yaml
+//	<url>/v0.3.1/components.yaml
+//
+// or a components file, whose version must then be set explicitly.
+type Repository struct {
+	URL string
+}
+
+// New returns the repository of a provider.
+func New(provider Provider) *Repository {
+	return &Repository{URL: provider.URL}
+}
+
+func (r *Repository) isFile() (bool, error) {
+	info, err := os.Stat(r.URL)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to read repository %q", r.URL)
+	}
+	return !info.IsDir(), nil
+}
+
+// Versions returns the versions available in the repository, sorted from the oldest to the latest.
+// Directories whose name is not a semantic version are ignored.
+func (r *Repository) Versions() ([]string, error) {
+	isFile, err := r.isFile()
+	if err != nil {
+		return nil, err
+	}
+	if isFile {
+		return nil, nil
+	}
+
+	entries, err := ioutil.ReadDir(r.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read repository %q", r.URL)
+	}
+	var versions []*version.Version
+	names := map[*version.Version]string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		v, err := version.ParseSemantic(entry.Name())
+		if err != nil {
+			continue
+		}
+		versions = append(versions, v)
+		names[v] = entry.Name()
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].LessThan(versions[j]) })
+
+	result := make([]string, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, names[v])
+	}
+	return result, nil
+}
+
+// LatestVersion returns the latest version available in the repository.
+func (r *Repository) LatestVersion() (string, error) {
+	versions, err := r.Versions()
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", errors.Errorf("no version found in repository %q", r.URL)
+	}
+	return versions[len(versions)-1], nil
+}
+
+// Components returns the components file of a version, or of the latest version if empty,
+// along with the version.
+func (r *Repository) Components(version string) ([]byte, string, error) {
+	isFile, err := r.isFile()
+	if err != nil {
+		return nil, "", err
+	}
+
+	path := r.URL
+	if !isFile {
+		if version == "" {
+			if version, err = r.LatestVersion(); err != nil {
+				return nil, "", err
+			}
+		}
+		path = filepath.Join(r.URL, version, ComponentsFileName)
+	} else if version == "" {
+		return nil, "", errors.Errorf("the version of the components file %q must be set", r.URL)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to read components of version %q from repository %q", version, r.URL)
+	}
+	return data, version, nil
+}
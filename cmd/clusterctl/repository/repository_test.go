@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepository(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repository")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, version := range []string{"v0.3.10", "v0.3.2", "v0.2.0"} {
+		writeFile(t, filepath.Join(dir, version, repository.ComponentsFileName), "version: "+version)
+	}
+	writeFile(t, filepath.Join(dir, "latest", repository.ComponentsFileName), "version: latest")
+	writeFile(t, filepath.Join(dir, "README.md"), "")
+
+	r := &repository.Repository{URL: dir}
+	versions, err := r.Versions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"v0.2.0", "v0.3.2", "v0.3.10"}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("versions mismatch: got %v, want %v", versions, expected)
+	}
+
+	testCases := []struct {
+		name            string
+		version         string
+		expectedVersion string
+		expectedError   bool
+	}{
+		{"latest version", "", "v0.3.10", false},
+		{"explicit version", "v0.3.2", "v0.3.2", false},
+		{"unknown version", "v0.4.0", "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, version, err := r.Components(tc.version)
+			if tc.expectedError {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if version != tc.expectedVersion || string(data) != "version: "+tc.expectedVersion {
+				t.Errorf("components mismatch: got %q with version %q, want version %q", data, version, tc.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestRepositoryComponentsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repository")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "infrastructure-components.yaml")
+	writeFile(t, path, "kind: Namespace")
+
+	r := &repository.Repository{URL: path}
+	if _, _, err := r.Components(""); err == nil {
+		t.Errorf("expected an error when the version of a components file is not set")
+	}
+	data, version, err := r.Components("v0.1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != "v0.1.0" || string(data) != "kind: Namespace" {
+		t.Errorf("components mismatch: got %q with version %q", data, version)
+	}
+}
+
+func TestLoadProviders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repository")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "clusterctl.yaml")
+	writeFile(t, configFile, `AWS_REGION: us-east-1
+providers:
+- name: docker
+  type: InfrastructureProvider
+  url: /repositories/docker
+- name: aws
+  type: InfrastructureProvider
+  url: /repositories/aws
+`)
+	providers, err := repository.LoadProviders(configFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	docker, err := repository.Find(providers, clusterctlv1.InfrastructureProviderType, "docker")
+	if err != nil || docker.URL != "/repositories/docker" {
+		t.Errorf("expected the docker provider to be overridden, got %v: %v", docker, err)
+	}
+	aws, err := repository.Find(providers, clusterctlv1.InfrastructureProviderType, "aws")
+	if err != nil || aws.URL != "/repositories/aws" {
+		t.Errorf("expected the aws provider to be added, got %v: %v", aws, err)
+	}
+	core, err := repository.Find(providers, clusterctlv1.CoreProviderType, repository.CoreProviderName)
+	if err != nil || core.URL != filepath.Join(repository.DefaultRoot(), "cluster-api") {
+		t.Errorf("expected the default core provider, got %v: %v", core, err)
+	}
+	if _, err := repository.Find(providers, clusterctlv1.BootstrapProviderType, "aws"); err == nil {
+		t.Errorf("expected an error for an unknown provider")
+	}
+
+	writeFile(t, configFile, "providers:\n- name: aws\n  type: Infrastructure\n  url: /repositories/aws\n")
+	if _, err := repository.LoadProviders(configFile); err == nil {
+		t.Errorf("expected an error for an invalid provider type")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/yaml"
+)
+
+// Variables set by clusterctl when generating a cluster.
+const (
+	ClusterNameVariable              = "CLUSTER_NAME"
+	NamespaceVariable                = "NAMESPACE"
+	KubernetesVersionVariable        = "KUBERNETES_VERSION"
+	ControlPlaneMachineCountVariable = "CONTROL_PLANE_MACHINE_COUNT"
+	WorkerMachineCountVariable       = "WORKER_MACHINE_COUNT"
+)
+
+// machineReferencePaths are the fields of a Machine referencing the objects it owns.
+var machineReferencePaths = [][]string{
+	{"spec", "bootstrap", "configRef"},
+	{"spec", "infrastructureRef"},
+}
+
+// GenerateCluster renders a cluster template with the given variables.
+//
+// The control plane Machines of the template, i.e. the Machines with the control plane
+// label, are replicated CONTROL_PLANE_MACHINE_COUNT times (once if the variable is not
+// set) together with the bootstrap configurations and infrastructure machines they
+// reference; the name of each copy is suffixed with its index.
+func GenerateCluster(template []byte, variables *Variables) ([]byte, error) {
+	out, err := Process(template, variables)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := yaml.ToUnstructured(out)
+	if err != nil {
+		return nil, err
+	}
+
+	count := 1
+	if value, ok := variables.Get(ControlPlaneMachineCountVariable); ok {
+		count, err = strconv.Atoi(value)
+		if err != nil || count < 0 {
+			return nil, errors.Errorf("invalid %s %q, must be a non-negative integer", ControlPlaneMachineCountVariable, value)
+		}
+	}
+	objs, err = replicateControlPlane(objs, count)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.FromUnstructured(objs)
+}
+
+func replicateControlPlane(objs []*unstructured.Unstructured, count int) ([]*unstructured.Unstructured, error) {
+	// Collect the objects of the template referenced by the control plane Machines.
+	objects := map[string]bool{}
+	for _, obj := range objs {
+		objects[objectKey(obj.GetKind(), obj.GetNamespace(), obj.GetName())] = true
+	}
+	templates := map[string]bool{}
+	for _, obj := range objs {
+		if isControlPlaneMachine(obj) {
+			for _, path := range machineReferencePaths {
+				kind, name, ok := reference(obj, path)
+				if key := objectKey(kind, obj.GetNamespace(), name); ok && objects[key] {
+					templates[key] = true
+				}
+			}
+		}
+	}
+
+	var result []*unstructured.Unstructured
+	for _, obj := range objs {
+		machine := isControlPlaneMachine(obj)
+		if !machine && !templates[objectKey(obj.GetKind(), obj.GetNamespace(), obj.GetName())] {
+			result = append(result, obj)
+			continue
+		}
+		for i := 0; i < count; i++ {
+			replica := obj.DeepCopy()
+			replica.SetName(fmt.Sprintf("%s-%d", obj.GetName(), i))
+			if machine {
+				for _, path := range machineReferencePaths {
+					kind, name, ok := reference(obj, path)
+					if !ok || !templates[objectKey(kind, obj.GetNamespace(), name)] {
+						continue
+					}
+					namePath := append(append([]string{}, path...), "name")
+					if err := unstructured.SetNestedField(replica.Object, fmt.Sprintf("%s-%d", name, i), namePath...); err != nil {
+						return nil, errors.Wrapf(err, "failed to set reference of Machine %q", replica.GetName())
+					}
+				}
+			}
+			result = append(result, replica)
+		}
+	}
+	return result, nil
+}
+
+func isControlPlaneMachine(obj *unstructured.Unstructured) bool {
+	if obj.GetKind() != "Machine" {
+		return false
+	}
+	_, ok := obj.GetLabels()[clusterv1.MachineControlPlaneLabelName]
+	return ok
+}
+
+// reference returns the kind and name of the object referenced by a Machine at the given path.
+// References to other namespaces are not returned, as they can't point to an object of the template.
+func reference(machine *unstructured.Unstructured, path []string) (string, string, bool) {
+	ref, ok, _ := unstructured.NestedStringMap(machine.Object, path...)
+	if !ok || ref["kind"] == "" || ref["name"] == "" {
+		return "", "", false
+	}
+	if ns := ref["namespace"]; ns != "" && ns != machine.GetNamespace() {
+		return "", "", false
+	}
+	return ref["kind"], ref["name"], true
+}
+
+func objectKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
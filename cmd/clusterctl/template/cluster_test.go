@@ -0,0 +1,182 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/template"
+)
+
+const clusterTemplate = `apiVersion: cluster.x-k8s.io/v1alpha3
+kind: Cluster
+metadata:
+  name: ${CLUSTER_NAME}
+---
+apiVersion: bootstrap.cluster.x-k8s.io/v1alpha2
+kind: KubeadmConfig
+metadata:
+  name: ${CLUSTER_NAME}-controlplane
+---
+apiVersion: cluster.x-k8s.io/v1alpha3
+kind: Machine
+metadata:
+  name: ${CLUSTER_NAME}-controlplane
+  labels:
+    cluster.x-k8s.io/control-plane: "true"
+spec:
+  version: ${KUBERNETES_VERSION}
+  bootstrap:
+    configRef:
+      kind: KubeadmConfig
+      name: ${CLUSTER_NAME}-controlplane
+  infrastructureRef:
+    kind: InfrastructureMachine
+    name: shared
+---
+apiVersion: cluster.x-k8s.io/v1alpha3
+kind: MachineDeployment
+metadata:
+  name: ${CLUSTER_NAME}-md-0
+spec:
+  replicas: ${WORKER_MACHINE_COUNT}
+`
+
+func TestGenerateCluster(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		controlPlaneCount    string
+		expected             string
+		expectedErrorMessage string
+	}{
+		{
+			name:              "control plane replicated",
+			controlPlaneCount: "2",
+			expected: `apiVersion: cluster.x-k8s.io/v1alpha3
+kind: Cluster
+metadata:
+  name: test
+---
+apiVersion: bootstrap.cluster.x-k8s.io/v1alpha2
+kind: KubeadmConfig
+metadata:
+  name: test-controlplane-0
+---
+apiVersion: bootstrap.cluster.x-k8s.io/v1alpha2
+kind: KubeadmConfig
+metadata:
+  name: test-controlplane-1
+---
+apiVersion: cluster.x-k8s.io/v1alpha3
+kind: Machine
+metadata:
+  labels:
+    cluster.x-k8s.io/control-plane: "true"
+  name: test-controlplane-0
+spec:
+  bootstrap:
+    configRef:
+      kind: KubeadmConfig
+      name: test-controlplane-0
+  infrastructureRef:
+    kind: InfrastructureMachine
+    name: shared
+  version: v1.16.3
+---
+apiVersion: cluster.x-k8s.io/v1alpha3
+kind: Machine
+metadata:
+  labels:
+    cluster.x-k8s.io/control-plane: "true"
+  name: test-controlplane-1
+spec:
+  bootstrap:
+    configRef:
+      kind: KubeadmConfig
+      name: test-controlplane-1
+  infrastructureRef:
+    kind: InfrastructureMachine
+    name: shared
+  version: v1.16.3
+---
+apiVersion: cluster.x-k8s.io/v1alpha3
+kind: MachineDeployment
+metadata:
+  name: test-md-0
+spec:
+  replicas: 3
+`,
+		},
+		{
+			name:              "no control plane",
+			controlPlaneCount: "0",
+			expected: `apiVersion: cluster.x-k8s.io/v1alpha3
+kind: Cluster
+metadata:
+  name: test
+---
+apiVersion: cluster.x-k8s.io/v1alpha3
+kind: MachineDeployment
+metadata:
+  name: test-md-0
+spec:
+  replicas: 3
+`,
+		},
+		{
+			name:                 "invalid control plane count",
+			controlPlaneCount:    "-1",
+			expectedErrorMessage: `invalid CONTROL_PLANE_MACHINE_COUNT "-1", must be a non-negative integer`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			variables := &template.Variables{
+				Values: map[string]string{
+					template.ClusterNameVariable:              "test",
+					template.KubernetesVersionVariable:        "v1.16.3",
+					template.ControlPlaneMachineCountVariable: tc.controlPlaneCount,
+					template.WorkerMachineCountVariable:       "3",
+				},
+				LookupEnv: func(string) (string, bool) { return "", false },
+			}
+			out, err := template.GenerateCluster([]byte(clusterTemplate), variables)
+			if tc.expectedErrorMessage != "" {
+				if err == nil || err.Error() != tc.expectedErrorMessage {
+					t.Fatalf("error mismatch: got %v, want %q", err, tc.expectedErrorMessage)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(out) != tc.expected {
+				t.Errorf("output mismatch:\ngot:\n%s\nwant:\n%s", out, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateClusterMissingVariables(t *testing.T) {
+	_, err := template.GenerateCluster([]byte(clusterTemplate), &template.Variables{
+		LookupEnv: func(string) (string, bool) { return "", false },
+	})
+	if err == nil || !strings.Contains(err.Error(), "[CLUSTER_NAME, KUBERNETES_VERSION, WORKER_MACHINE_COUNT]") {
+		t.Errorf("expected missing variables to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// variableRegexp matches ${NAME}, ${NAME:=default} and ${NAME:-default} placeholders.
+var variableRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:[=-]([^}]*))?\}`)
+
+// Variables resolves the values of template variables. Values set explicitly take
+// precedence over the environment, which takes precedence over the config file.
+type Variables struct {
+	// Values are the variables set explicitly, e.g. from command line flags.
+	Values map[string]string
+
+	// ConfigFile are the variables read from a clusterctl config file.
+	ConfigFile map[string]string
+
+	// LookupEnv looks up a variable in the environment, defaults to os.LookupEnv.
+	LookupEnv func(string) (string, bool)
+}
+
+// Get returns the value of a variable and whether it is set.
+func (v *Variables) Get(name string) (string, bool) {
+	if value, ok := v.Values[name]; ok {
+		return value, true
+	}
+	lookupEnv := v.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	if value, ok := lookupEnv(name); ok {
+		return value, true
+	}
+	value, ok := v.ConfigFile[name]
+	return value, ok
+}
+
+// Set sets the value of a variable, overriding any other source.
+func (v *Variables) Set(name, value string) {
+	if v.Values == nil {
+		v.Values = map[string]string{}
+	}
+	v.Values[name] = value
+}
+
+// LoadConfigFile reads the variables defined at the top level of a clusterctl config file.
+// Keys whose value is not a scalar are ignored.
+func LoadConfigFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file %q", path)
+	}
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config file %q", path)
+	}
+	variables := map[string]string{}
+	for key, value := range raw {
+		switch value.(type) {
+		case string, bool, float64, int64:
+			variables[key] = fmt.Sprint(value)
+		}
+	}
+	return variables, nil
+}
+
+// MissingVariablesError is returned when a template uses variables without a value
+// nor a default.
+type MissingVariablesError struct {
+	Names []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	return fmt.Sprintf("value for variables [%s] is not set", strings.Join(e.Names, ", "))
+}
+
+// VariableNames returns the sorted names of the variables used in a template.
+func VariableNames(template []byte) []string {
+	names := map[string]struct{}{}
+	for _, match := range variableRegexp.FindAllSubmatch(template, -1) {
+		names[string(match[1])] = struct{}{}
+	}
+	return sortedKeys(names)
+}
+
+// Process replaces the variables of a template with their values, falling back to the
+// default set in the template if any. All the variables without a value are reported
+// with a MissingVariablesError.
+func Process(template []byte, variables *Variables) ([]byte, error) {
+	missing := map[string]struct{}{}
+	out := variableRegexp.ReplaceAllFunc(template, func(placeholder []byte) []byte {
+		match := variableRegexp.FindSubmatch(placeholder)
+		if value, ok := variables.Get(string(match[1])); ok {
+			return []byte(value)
+		}
+		if len(match[2]) > 0 {
+			return match[3]
+		}
+		missing[string(match[1])] = struct{}{}
+		return placeholder
+	})
+	if len(missing) > 0 {
+		return nil, &MissingVariablesError{Names: sortedKeys(missing)}
+	}
+	return out, nil
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/template"
+)
+
+func TestProcess(t *testing.T) {
+	variables := &template.Variables{
+		Values:     map[string]string{"NAME": "from-flags"},
+		ConfigFile: map[string]string{"NAME": "from-config", "REGION": "from-config", "ZONE": "from-config"},
+		LookupEnv: func(name string) (string, bool) {
+			if name == "REGION" {
+				return "from-env", true
+			}
+			return "", false
+		},
+	}
+	testCases := []struct {
+		name                 string
+		template             string
+		expected             string
+		expectedErrorMessage string
+	}{
+		{"values take precedence", "name: ${NAME}", "name: from-flags", ""},
+		{"environment takes precedence over config file", "region: ${REGION}", "region: from-env", ""},
+		{"config file", "zone: ${ZONE}", "zone: from-config", ""},
+		{"default ignored when set", "zone: ${ZONE:=default}", "zone: from-config", ""},
+		{"default", "size: ${SIZE:=large} ${COUNT:-3} ${EMPTY:=}", "size: large 3 ", ""},
+		{"no variables", "size: $SIZE", "size: $SIZE", ""},
+		{"missing variables", "${B} ${A} ${NAME} ${B}", "", "value for variables [A, B] is not set"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := template.Process([]byte(tc.template), variables)
+			if tc.expectedErrorMessage != "" {
+				if err == nil || err.Error() != tc.expectedErrorMessage {
+					t.Fatalf("error mismatch: got %v, want %q", err, tc.expectedErrorMessage)
+				}
+				if _, ok := err.(*template.MissingVariablesError); !ok {
+					t.Errorf("expected a MissingVariablesError, got %T", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(out) != tc.expected {
+				t.Errorf("output mismatch: got %q, want %q", out, tc.expected)
+			}
+		})
+	}
+}
+
+func TestVariableNames(t *testing.T) {
+	names := template.VariableNames([]byte("${B} ${A:=default} ${B} $C"))
+	if expected := []string{"A", "B"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("variable names mismatch: got %v, want %v", names, expected)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clusterctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "clusterctl.yaml")
+	content := "REGION: us-east-1\nCOUNT: 3\nENABLED: true\nproviders:\n- name: docker\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	variables, err := template.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{"REGION": "us-east-1", "COUNT": "3", "ENABLED": "true"}
+	if !reflect.DeepEqual(variables, expected) {
+		t.Errorf("variables mismatch: got %v, want %v", variables, expected)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package upgrade computes the upgrade plans of the providers installed by clusterctl.
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+)
+
+// providerOrder is the order providers are upgraded in, the core provider first.
+var providerOrder = map[clusterctlv1.ProviderType]int{
+	clusterctlv1.CoreProviderType:           0,
+	clusterctlv1.BootstrapProviderType:      1,
+	clusterctlv1.InfrastructureProviderType: 2,
+}
+
+// Item is the upgrade of an installed provider.
+type Item struct {
+	// Provider is the inventory object of the installed provider.
+	Provider *clusterctlv1.Provider
+
+	// Repository is the provider, defining the repository of its components.
+	Repository repository.Provider
+
+	// NextVersion is the version to upgrade to, empty if the provider is up to date.
+	NextVersion string
+}
+
+// Plan is the upgrade of all the installed providers to the latest versions implementing a contract.
+type Plan struct {
+	// Contract is the Cluster API contract implemented after the upgrade, e.g. v1alpha3.
+	Contract string
+
+	// Items are the upgrades of the installed providers, in the order they must be applied.
+	Items []Item
+
+	// Blockers are the reasons the plan can't be applied, e.g. a provider without any version
+	// implementing the contract.
+	Blockers []string
+}
+
+// IsUpToDate returns true if all the providers are already at their latest version for the contract.
+func (p *Plan) IsUpToDate() bool {
+	for _, item := range p.Items {
+		if item.NextVersion != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// GetPlans returns a plan for the contract implemented by the installed core provider, and
+// for each newer contract implemented by a version available for the core provider.
+//
+// The installed providers must all implement the contract of the core provider.
+func GetPlans(installed []*clusterctlv1.Provider, providers []repository.Provider) ([]Plan, error) {
+	items := make([]Item, 0, len(installed))
+	var core *Item
+	for _, p := range installed {
+		r, err := repository.Find(providers, p.GetProviderType(), p.ProviderName)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, Item{Provider: p, Repository: r})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return providerOrder[items[i].Provider.GetProviderType()] < providerOrder[items[j].Provider.GetProviderType()]
+	})
+	for i := range items {
+		if items[i].Provider.GetProviderType() == clusterctlv1.CoreProviderType {
+			if core != nil {
+				return nil, errors.New("more than one core provider is installed")
+			}
+			core = &items[i]
+		}
+	}
+	if core == nil {
+		return nil, errors.New("the core provider is not installed, run clusterctl init first")
+	}
+
+	// Check the installed providers implement the same contract.
+	metadata := map[string]*repository.Metadata{}
+	for _, item := range items {
+		m, err := repository.New(item.Repository).Metadata()
+		if err != nil {
+			return nil, err
+		}
+		metadata[item.Provider.Name] = m
+	}
+	currentContract, err := metadata[core.Provider.Name].Contract(core.Provider.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to determine the contract of the core provider")
+	}
+	for _, item := range items {
+		contract, err := metadata[item.Provider.Name].Contract(item.Provider.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to determine the contract of %s %q", item.Provider.Type, item.Provider.ProviderName)
+		}
+		if contract != currentContract {
+			return nil, errors.Errorf("%s %q version %s implements contract %s, which is not compatible with contract %s of the core provider",
+				item.Provider.Type, item.Provider.ProviderName, item.Provider.Version, contract, currentContract)
+		}
+	}
+
+	// The contracts to upgrade to are the current one and the ones of the newer core versions.
+	contracts := []string{currentContract}
+	coreVersions, err := newerVersions(core)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range coreVersions {
+		contract, err := metadata[core.Provider.Name].Contract(v)
+		if err != nil {
+			continue
+		}
+		if contract != contracts[len(contracts)-1] {
+			contracts = append(contracts, contract)
+		}
+	}
+
+	plans := make([]Plan, 0, len(contracts))
+	for _, contract := range contracts {
+		plan := Plan{Contract: contract}
+		for _, item := range items {
+			next, err := latestVersion(item, metadata[item.Provider.Name], contract)
+			if err != nil {
+				return nil, err
+			}
+			if next == "" && contract != currentContract {
+				plan.Blockers = append(plan.Blockers, fmt.Sprintf("%s %q has no version implementing contract %s", item.Provider.Type, item.Provider.ProviderName, contract))
+			}
+			item.NextVersion = next
+			plan.Items = append(plan.Items, item)
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// newerVersions returns the versions of the repository of a provider newer than the installed one.
+func newerVersions(item *Item) ([]string, error) {
+	current, err := version.ParseSemantic(item.Provider.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid version %q of %s %q", item.Provider.Version, item.Provider.Type, item.Provider.ProviderName)
+	}
+	versions, err := repository.New(item.Repository).Versions()
+	if err != nil {
+		return nil, err
+	}
+	var newer []string
+	for _, v := range versions {
+		if parsed, err := version.ParseSemantic(v); err == nil && current.LessThan(parsed) {
+			newer = append(newer, v)
+		}
+	}
+	return newer, nil
+}
+
+// latestVersion returns the latest version of a provider implementing a contract, if newer
+// than the installed one.
+func latestVersion(item Item, metadata *repository.Metadata, contract string) (string, error) {
+	versions, err := newerVersions(&item)
+	if err != nil {
+		return "", err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if c, err := metadata.Contract(versions[i]); err == nil && c == contract {
+			return versions[i], nil
+		}
+	}
+	return "", nil
+}
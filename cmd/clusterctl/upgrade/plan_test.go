@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/upgrade"
+)
+
+const metadata = `releaseSeries:
+- major: 0
+  minor: 2
+  contract: v1alpha2
+- major: 0
+  minor: 3
+  contract: v1alpha3
+`
+
+// newRepository creates a repository with the given versions and returns its provider.
+func newRepository(t *testing.T, root, name string, providerType clusterctlv1.ProviderType, versions ...string) repository.Provider {
+	provider := repository.Provider{Name: name, Type: providerType}
+	provider.URL = filepath.Join(root, provider.InstanceName())
+	for _, v := range versions {
+		if err := os.MkdirAll(filepath.Join(provider.URL, v), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(provider.URL, v, repository.ComponentsFileName), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(provider.URL, repository.MetadataFileName), []byte(metadata), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func installed(provider repository.Provider, version string) *clusterctlv1.Provider {
+	return &clusterctlv1.Provider{
+		ObjectMeta:   metav1.ObjectMeta{Namespace: provider.Name + "-system", Name: provider.InstanceName()},
+		ProviderName: provider.Name,
+		Type:         string(provider.Type),
+		Version:      version,
+	}
+}
+
+func nextVersions(plan upgrade.Plan) []string {
+	var versions []string
+	for _, item := range plan.Items {
+		versions = append(versions, item.Provider.ProviderName+":"+item.NextVersion)
+	}
+	return versions
+}
+
+func TestGetPlans(t *testing.T) {
+	root, err := ioutil.TempDir("", "upgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	core := newRepository(t, root, "cluster-api", clusterctlv1.CoreProviderType, "v0.2.0", "v0.2.1", "v0.3.0", "v0.3.1")
+	bootstrap := newRepository(t, root, "kubeadm", clusterctlv1.BootstrapProviderType, "v0.1.0", "v0.2.0")
+	docker := newRepository(t, root, "docker", clusterctlv1.InfrastructureProviderType, "v0.2.0", "v0.2.2", "v0.3.0")
+	providers := []repository.Provider{core, bootstrap, docker}
+
+	metadataBootstrap := strings.Replace(metadata, "minor: 2", "minor: 1", 1)
+	metadataBootstrap = strings.Replace(metadataBootstrap, "minor: 3", "minor: 2", 1)
+	if err := ioutil.WriteFile(filepath.Join(bootstrap.URL, repository.MetadataFileName), []byte(metadataBootstrap), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The providers are sorted core first, and the plans cover the current and newer contracts.
+	plans, err := upgrade.GetPlans([]*clusterctlv1.Provider{
+		installed(docker, "v0.2.0"),
+		installed(bootstrap, "v0.1.0"),
+		installed(core, "v0.2.0"),
+	}, providers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plans) != 2 {
+		t.Fatalf("expected 2 plans, got %d", len(plans))
+	}
+	if plans[0].Contract != "v1alpha2" || plans[1].Contract != "v1alpha3" {
+		t.Errorf("unexpected contracts %q and %q", plans[0].Contract, plans[1].Contract)
+	}
+	if expected := []string{"cluster-api:v0.2.1", "kubeadm:", "docker:v0.2.2"}; !reflect.DeepEqual(nextVersions(plans[0]), expected) {
+		t.Errorf("next versions mismatch: got %v, want %v", nextVersions(plans[0]), expected)
+	}
+	if expected := []string{"cluster-api:v0.3.1", "kubeadm:v0.2.0", "docker:v0.3.0"}; !reflect.DeepEqual(nextVersions(plans[1]), expected) {
+		t.Errorf("next versions mismatch: got %v, want %v", nextVersions(plans[1]), expected)
+	}
+	if plans[0].IsUpToDate() || len(plans[0].Blockers) > 0 || len(plans[1].Blockers) > 0 {
+		t.Errorf("expected the plans to be applicable: %+v", plans)
+	}
+
+	// A provider without a version for the newer contract blocks the upgrade.
+	if err := os.RemoveAll(filepath.Join(docker.URL, "v0.3.0")); err != nil {
+		t.Fatal(err)
+	}
+	plans, err = upgrade.GetPlans([]*clusterctlv1.Provider{installed(core, "v0.2.1"), installed(docker, "v0.2.2")}, providers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !plans[0].IsUpToDate() {
+		t.Errorf("expected the providers to be up to date for the current contract: %+v", plans[0])
+	}
+	if len(plans[1].Blockers) != 1 || !strings.Contains(plans[1].Blockers[0], "docker") {
+		t.Errorf("expected the docker provider to block the upgrade: %v", plans[1].Blockers)
+	}
+}
+
+func TestGetPlansIncompatibleContracts(t *testing.T) {
+	root, err := ioutil.TempDir("", "upgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	core := newRepository(t, root, "cluster-api", clusterctlv1.CoreProviderType, "v0.3.0")
+	docker := newRepository(t, root, "docker", clusterctlv1.InfrastructureProviderType, "v0.2.0")
+	providers := []repository.Provider{core, docker}
+
+	_, err = upgrade.GetPlans([]*clusterctlv1.Provider{installed(core, "v0.3.0"), installed(docker, "v0.2.0")}, providers)
+	if err == nil || !strings.Contains(err.Error(), "not compatible") {
+		t.Errorf("expected an incompatible contract error, got %v", err)
+	}
+
+	_, err = upgrade.GetPlans([]*clusterctlv1.Provider{installed(docker, "v0.2.0")}, providers)
+	if err == nil || !strings.Contains(err.Error(), "core provider is not installed") {
+		t.Errorf("expected a missing core provider error, got %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getTemplateCapacity returns the capacity of the Nodes created from the Machine template,
+// as reported in the status.capacity field of its infrastructure template.
+// It returns nil if the infrastructure template isn't set, doesn't exist or doesn't report a capacity.
+func getTemplateCapacity(ctx context.Context, c client.Client, template *clusterv1.MachineTemplateSpec, namespace string) (corev1.ResourceList, error) {
+	if template.Spec.InfrastructureRef.Kind == "" || template.Spec.InfrastructureRef.Name == "" {
+		return nil, nil
+	}
+	infraTemplate, err := external.Get(ctx, c, &template.Spec.InfrastructureRef, namespace)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get infrastructure template %q", template.Spec.InfrastructureRef.Name)
+	}
+
+	values, found, err := unstructured.NestedStringMap(infraTemplate.Object, "status", "capacity")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read status.capacity of %v %q", infraTemplate.GroupVersionKind(), infraTemplate.GetName())
+	} else if !found {
+		return nil, nil
+	}
+
+	capacity := corev1.ResourceList{}
+	for name, value := range values {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s capacity of %v %q", name, infraTemplate.GroupVersionKind(), infraTemplate.GetName())
+		}
+		capacity[corev1.ResourceName(name)] = quantity
+	}
+	return capacity, nil
+}
+
+// setCapacityAnnotations records the CPU, memory and GPU capacity, and the labels and taints of the Nodes
+// created from the Machine template in the annotations of obj, and returns whether they changed.
+// Annotations of resources missing from the capacity are left untouched, while the labels and taints
+// annotations are removed when the template has no NodeLabels or NodeTaints.
+func setCapacityAnnotations(obj metav1.Object, capacity corev1.ResourceList, template *clusterv1.MachineTemplateSpec) bool {
+	values := map[string]string{}
+	if cpu, ok := capacity[corev1.ResourceCPU]; ok {
+		values[clusterv1.CapacityCPUAnnotation] = cpu.String()
+	}
+	if memory, ok := capacity[corev1.ResourceMemory]; ok {
+		values[clusterv1.CapacityMemoryAnnotation] = memory.String()
+	}
+	var gpus *int64
+	for name, quantity := range capacity {
+		if strings.HasSuffix(string(name), "gpu") {
+			count := quantity.Value()
+			if gpus != nil {
+				count += *gpus
+			}
+			gpus = &count
+		}
+	}
+	if gpus != nil {
+		values[clusterv1.CapacityGPUCountAnnotation] = strconv.FormatInt(*gpus, 10)
+	}
+	var removed []string
+	if len(template.Spec.NodeLabels) > 0 {
+		values[clusterv1.CapacityLabelsAnnotation] = formatCapacityLabels(template.Spec.NodeLabels)
+	} else {
+		removed = append(removed, clusterv1.CapacityLabelsAnnotation)
+	}
+	if len(template.Spec.NodeTaints) > 0 {
+		values[clusterv1.CapacityTaintsAnnotation] = formatCapacityTaints(template.Spec.NodeTaints)
+	} else {
+		removed = append(removed, clusterv1.CapacityTaintsAnnotation)
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	changed := false
+	for k, v := range values {
+		if annotations[k] != v {
+			annotations[k] = v
+			changed = true
+		}
+	}
+	for _, k := range removed {
+		if _, ok := annotations[k]; ok {
+			delete(annotations, k)
+			changed = true
+		}
+	}
+	obj.SetAnnotations(annotations)
+	return changed
+}
+
+// validateAutoscalerAnnotations checks the format of the cluster autoscaler annotations of a
+// MachineSet or MachineDeployment, and that its minimum size isn't greater than its maximum size.
+func validateAutoscalerAnnotations(annotations map[string]string) error {
+	var errs []error
+
+	sizes := map[string]int{}
+	for _, key := range []string{clusterv1.AutoscalerMinSizeAnnotation, clusterv1.AutoscalerMaxSizeAnnotation, clusterv1.CapacityGPUCountAnnotation} {
+		value, ok := annotations[key]
+		if !ok {
+			continue
+		}
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			errs = append(errs, errors.Errorf("annotation %s must be a non-negative integer, got %q", key, value))
+			continue
+		}
+		sizes[key] = size
+	}
+	minSize, hasMin := sizes[clusterv1.AutoscalerMinSizeAnnotation]
+	maxSize, hasMax := sizes[clusterv1.AutoscalerMaxSizeAnnotation]
+	if hasMin && hasMax && minSize > maxSize {
+		errs = append(errs, errors.Errorf("annotation %s (%d) must not be greater than %s (%d)",
+			clusterv1.AutoscalerMinSizeAnnotation, minSize, clusterv1.AutoscalerMaxSizeAnnotation, maxSize))
+	}
+
+	for _, key := range []string{clusterv1.CapacityCPUAnnotation, clusterv1.CapacityMemoryAnnotation} {
+		if value, ok := annotations[key]; ok {
+			if _, err := resource.ParseQuantity(value); err != nil {
+				errs = append(errs, errors.Errorf("annotation %s must be a quantity, got %q", key, value))
+			}
+		}
+	}
+
+	if value, ok := annotations[clusterv1.CapacityLabelsAnnotation]; ok {
+		if _, err := parseCapacityLabels(value); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid annotation %s", clusterv1.CapacityLabelsAnnotation))
+		}
+	}
+	if value, ok := annotations[clusterv1.CapacityTaintsAnnotation]; ok {
+		if _, err := parseCapacityTaints(value); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid annotation %s", clusterv1.CapacityTaintsAnnotation))
+		}
+	}
+
+	return kerrors.NewAggregate(errs)
+}
+
+// formatCapacityLabels formats labels as comma separated key=value pairs, sorted by key.
+func formatCapacityLabels(labels map[string]string) string {
+	entries := make([]string, 0, len(labels))
+	for k, v := range labels {
+		entries = append(entries, k+"="+v)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// formatCapacityTaints formats taints as comma separated key=value:Effect entries, omitting empty values.
+func formatCapacityTaints(taints []corev1.Taint) string {
+	entries := make([]string, 0, len(taints))
+	for _, taint := range taints {
+		entry := taint.Key
+		if taint.Value != "" {
+			entry += "=" + taint.Value
+		}
+		entries = append(entries, entry+":"+string(taint.Effect))
+	}
+	return strings.Join(entries, ",")
+}
+
+// parseCapacityLabels parses comma separated key=value labels.
+func parseCapacityLabels(value string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, entry := range splitList(value) {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("label %q must be formatted as key=value", entry)
+		}
+		if errs := validation.IsQualifiedName(parts[0]); len(errs) > 0 {
+			return nil, errors.Errorf("invalid label key %q: %s", parts[0], strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(parts[1]); len(errs) > 0 {
+			return nil, errors.Errorf("invalid label value %q: %s", parts[1], strings.Join(errs, "; "))
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
+
+// parseCapacityTaints parses comma separated key=value:Effect taints, where the value is optional.
+func parseCapacityTaints(value string) ([]corev1.Taint, error) {
+	var taints []corev1.Taint
+	for _, entry := range splitList(value) {
+		i := strings.LastIndex(entry, ":")
+		if i < 0 {
+			return nil, errors.Errorf("taint %q must be formatted as key=value:Effect", entry)
+		}
+		taint := corev1.Taint{Effect: corev1.TaintEffect(entry[i+1:])}
+		switch taint.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return nil, errors.Errorf("taint %q has an invalid effect %q", entry, taint.Effect)
+		}
+		parts := strings.SplitN(entry[:i], "=", 2)
+		taint.Key = parts[0]
+		if len(parts) == 2 {
+			taint.Value = parts[1]
+		}
+		if errs := validation.IsQualifiedName(taint.Key); len(errs) > 0 {
+			return nil, errors.Errorf("invalid taint key %q: %s", taint.Key, strings.Join(errs, "; "))
+		}
+		taints = append(taints, taint)
+	}
+	return taints, nil
+}
+
+// splitList splits a comma separated list, ignoring empty entries.
+func splitList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
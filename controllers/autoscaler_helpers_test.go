@@ -0,0 +1,274 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetTemplateCapacity(t *testing.T) {
+	template := &clusterv1.MachineTemplateSpec{
+		Spec: clusterv1.MachineSpec{
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+				Kind:       "InfrastructureMachineTemplate",
+				Name:       "infra-template",
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		infraTemplate map[string]interface{}
+		expectError   bool
+		expected      corev1.ResourceList
+	}{
+		{
+			name: "template reporting its capacity",
+			infraTemplate: map[string]interface{}{
+				"status": map[string]interface{}{
+					"capacity": map[string]interface{}{
+						"cpu":            "4",
+						"memory":         "16Gi",
+						"nvidia.com/gpu": "2",
+					},
+				},
+			},
+			expected: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				"nvidia.com/gpu":      resource.MustParse("2"),
+			},
+		},
+		{
+			name:          "template without capacity",
+			infraTemplate: map[string]interface{}{},
+		},
+		{
+			name: "template with an invalid capacity",
+			infraTemplate: map[string]interface{}{
+				"status": map[string]interface{}{
+					"capacity": map[string]interface{}{
+						"cpu": "four",
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "missing template",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+
+			c := fake.NewFakeClient()
+			if tc.infraTemplate != nil {
+				infraTemplate := &unstructured.Unstructured{Object: tc.infraTemplate}
+				infraTemplate.SetAPIVersion(template.Spec.InfrastructureRef.APIVersion)
+				infraTemplate.SetKind(template.Spec.InfrastructureRef.Kind)
+				infraTemplate.SetName(template.Spec.InfrastructureRef.Name)
+				infraTemplate.SetNamespace("default")
+				c = fake.NewFakeClient(infraTemplate)
+			}
+
+			capacity, err := getTemplateCapacity(context.Background(), c, template, "default")
+			if tc.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(capacity).To(gomega.HaveLen(len(tc.expected)))
+			for name, quantity := range tc.expected {
+				actual, ok := capacity[name]
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(actual.Cmp(quantity)).To(gomega.Equal(0))
+			}
+		})
+	}
+}
+
+func TestSetCapacityAnnotations(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	ms := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				clusterv1.AutoscalerMaxSizeAnnotation: "5",
+			},
+		},
+	}
+	capacity := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+		"nvidia.com/gpu":      resource.MustParse("2"),
+		"amd.com/gpu":         resource.MustParse("1"),
+	}
+
+	g.Expect(setCapacityAnnotations(ms, capacity, &ms.Spec.Template)).To(gomega.BeTrue())
+	g.Expect(ms.Annotations).To(gomega.Equal(map[string]string{
+		clusterv1.AutoscalerMaxSizeAnnotation: "5",
+		clusterv1.CapacityCPUAnnotation:       "4",
+		clusterv1.CapacityMemoryAnnotation:    "16Gi",
+		clusterv1.CapacityGPUCountAnnotation:  "3",
+	}))
+
+	g.Expect(setCapacityAnnotations(ms, capacity, &ms.Spec.Template)).To(gomega.BeFalse())
+}
+
+func TestSetCapacityAnnotationsFromTemplate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	ms := &clusterv1.MachineSet{
+		Spec: clusterv1.MachineSetSpec{
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					NodeLabels: map[string]string{
+						"node-role.kubernetes.io/worker": "",
+						"pool":                           "gpu",
+					},
+					NodeTaints: []corev1.Taint{
+						{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+						{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule},
+					},
+				},
+			},
+		},
+	}
+
+	g.Expect(setCapacityAnnotations(ms, nil, &ms.Spec.Template)).To(gomega.BeTrue())
+	g.Expect(ms.Annotations).To(gomega.Equal(map[string]string{
+		clusterv1.CapacityLabelsAnnotation: "node-role.kubernetes.io/worker=,pool=gpu",
+		clusterv1.CapacityTaintsAnnotation: "dedicated=gpu:NoSchedule,spot:PreferNoSchedule",
+	}))
+	g.Expect(validateAutoscalerAnnotations(ms.Annotations)).To(gomega.Succeed())
+
+	labels, err := parseCapacityLabels(ms.Annotations[clusterv1.CapacityLabelsAnnotation])
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(labels).To(gomega.Equal(ms.Spec.Template.Spec.NodeLabels))
+	taints, err := parseCapacityTaints(ms.Annotations[clusterv1.CapacityTaintsAnnotation])
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(taints).To(gomega.Equal(ms.Spec.Template.Spec.NodeTaints))
+
+	g.Expect(setCapacityAnnotations(ms, nil, &ms.Spec.Template)).To(gomega.BeFalse())
+
+	// Removing the labels and taints from the template removes their annotations.
+	ms.Spec.Template.Spec.NodeLabels = nil
+	g.Expect(setCapacityAnnotations(ms, nil, &ms.Spec.Template)).To(gomega.BeTrue())
+	g.Expect(ms.Annotations).To(gomega.Equal(map[string]string{
+		clusterv1.CapacityTaintsAnnotation: "dedicated=gpu:NoSchedule,spot:PreferNoSchedule",
+	}))
+	ms.Spec.Template.Spec.NodeTaints = nil
+	g.Expect(setCapacityAnnotations(ms, nil, &ms.Spec.Template)).To(gomega.BeTrue())
+	g.Expect(ms.Annotations).To(gomega.BeEmpty())
+	g.Expect(setCapacityAnnotations(ms, nil, &ms.Spec.Template)).To(gomega.BeFalse())
+}
+
+func TestValidateAutoscalerAnnotations(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		expectError bool
+	}{
+		{
+			name: "no annotations",
+		},
+		{
+			name: "valid annotations",
+			annotations: map[string]string{
+				clusterv1.AutoscalerMinSizeAnnotation: "0",
+				clusterv1.AutoscalerMaxSizeAnnotation: "10",
+				clusterv1.CapacityCPUAnnotation:       "500m",
+				clusterv1.CapacityMemoryAnnotation:    "4Gi",
+				clusterv1.CapacityGPUCountAnnotation:  "1",
+				clusterv1.CapacityLabelsAnnotation:    "node-role.kubernetes.io/gpu=,zone=a",
+				clusterv1.CapacityTaintsAnnotation:    "dedicated=gpu:NoSchedule, spot:PreferNoSchedule",
+			},
+		},
+		{
+			name: "min size greater than max size",
+			annotations: map[string]string{
+				clusterv1.AutoscalerMinSizeAnnotation: "3",
+				clusterv1.AutoscalerMaxSizeAnnotation: "2",
+			},
+			expectError: true,
+		},
+		{
+			name: "negative max size",
+			annotations: map[string]string{
+				clusterv1.AutoscalerMaxSizeAnnotation: "-1",
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid memory",
+			annotations: map[string]string{
+				clusterv1.CapacityMemoryAnnotation: "lots",
+			},
+			expectError: true,
+		},
+		{
+			name: "label without value",
+			annotations: map[string]string{
+				clusterv1.CapacityLabelsAnnotation: "zone",
+			},
+			expectError: true,
+		},
+		{
+			name: "taint with an invalid effect",
+			annotations: map[string]string{
+				clusterv1.CapacityTaintsAnnotation: "dedicated=gpu:Never",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+
+			err := validateAutoscalerAnnotations(tc.annotations)
+			if tc.expectError {
+				g.Expect(err).To(gomega.HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestParseCapacityTaints(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	taints, err := parseCapacityTaints("dedicated=gpu:NoSchedule,spot:PreferNoSchedule")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(taints).To(gomega.Equal([]corev1.Taint{
+		{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule},
+	}))
+}
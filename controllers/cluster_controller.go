@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"reflect"
 	"sync"
 	"time"
 
@@ -30,13 +31,14 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/client-go/tools/record"
-	"k8s.io/klog"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	logutil "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -49,6 +51,14 @@ const (
 	// deleteRequeueAfter is how long to wait before checking again to see if the cluster still has children during
 	// deletion.
 	deleteRequeueAfter = 5 * time.Second
+
+	// kubeconfigRotationThreshold is how long before its expiration the client certificate
+	// of a Kubeconfig secret created by the Cluster controller gets regenerated.
+	kubeconfigRotationThreshold = 30 * 24 * time.Hour
+
+	// certificateExpiryWarningThreshold is how long before their expiration
+	// warning events are emitted for certificates that are not rotated automatically.
+	certificateExpiryWarningThreshold = 90 * 24 * time.Hour
 )
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
@@ -63,7 +73,6 @@ type ClusterReconciler struct {
 	Log    logr.Logger
 
 	controller       controller.Controller
-	recorder         record.EventRecorder
 	externalWatchers sync.Map
 }
 
@@ -78,13 +87,14 @@ func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controlle
 		Build(r)
 
 	r.controller = c
-	r.recorder = mgr.GetEventRecorderFor("cluster-controller")
 	return err
 }
 
 func (r *ClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
-	ctx := context.Background()
-	_ = r.Log.WithValues("cluster", req.NamespacedName)
+	log := r.Log.WithValues(logutil.NamespaceKey, req.Namespace, logutil.ClusterKey, req.Name)
+	ctx := logutil.IntoContext(context.Background(), log)
+	ctx, span := tracing.Start(ctx, "ClusterReconciler.Reconcile", logutil.NamespaceKey, req.Namespace, logutil.ClusterKey, req.Name)
+	defer func() { tracing.End(ctx, span, reterr) }()
 
 	// Fetch the Cluster instance.
 	cluster := &clusterv1.Cluster{}
@@ -128,6 +138,8 @@ func (r *ClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr e
 
 // reconcile handles cluster reconciliation.
 func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
+	log := logutil.FromContext(ctx)
+
 	// If object doesn't have a finalizer, add one.
 	if !util.Contains(cluster.Finalizers, clusterv1.ClusterFinalizer) {
 		cluster.Finalizers = append(cluster.ObjectMeta.Finalizers, clusterv1.ClusterFinalizer)
@@ -137,6 +149,7 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 	reconciliationErrors := []error{
 		r.reconcileInfrastructure(ctx, cluster),
 		r.reconcileKubeconfig(ctx, cluster),
+		r.reconcileCertificatesExpiry(ctx, cluster),
 		r.reconcileControlPlaneInitialized(ctx, cluster),
 	}
 
@@ -149,7 +162,7 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 			if !res.Requeue {
 				res.Requeue = true
 				res.RequeueAfter = requeueErr.GetRequeueAfter()
-				klog.Infof("Reconciliation for Cluster %q in namespace %q asked to requeue: %v", cluster.Name, cluster.Namespace, err)
+				log.Info("Reconciliation asked to requeue", "requeueAfter", res.RequeueAfter, "error", err)
 			}
 			continue
 		}
@@ -161,21 +174,23 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 
 // reconcileDelete handles cluster deletion.
 func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *clusterv1.Cluster) (reconcile.Result, error) {
+	log := logutil.FromContext(ctx)
+
 	children, err := r.listChildren(ctx, cluster)
 	if err != nil {
-		klog.Errorf("Failed to list children of cluster %s/%s: %v", cluster.Namespace, cluster.Name, err)
+		log.Error(err, "Failed to list children")
 		return reconcile.Result{}, err
 	}
 
 	if len(children) > 0 {
-		klog.Infof("Cluster %s/%s still has %d children - deleting them first", cluster.Namespace, cluster.Name, len(children))
+		log.Info("Cluster still has children - deleting them first", "count", len(children))
 
 		var errs []error
 
 		for _, child := range children {
 			accessor, err := meta.Accessor(child)
 			if err != nil {
-				klog.Errorf("Cluster %s/%s: couldn't create accessor for type %T: %v", cluster.Namespace, cluster.Name, child, err)
+				log.Error(err, "Couldn't create accessor", "type", fmt.Sprintf("%T", child))
 				continue
 			}
 
@@ -185,13 +200,17 @@ func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 			}
 
 			gvk := child.GetObjectKind().GroupVersionKind().String()
+			kind := reflect.Indirect(reflect.ValueOf(child)).Type().Name()
 
-			klog.Infof("Cluster %s/%s: deleting child %s %s", cluster.Namespace, cluster.Name, gvk, accessor.GetName())
-			if err := r.Client.Delete(context.Background(), child); err != nil {
+			log.Info("Deleting child", "gvk", gvk, "name", accessor.GetName())
+			if err := r.Client.Delete(ctx, child); err != nil {
 				err = errors.Wrapf(err, "error deleting cluster %s/%s: failed to delete %s %s", cluster.Namespace, cluster.Name, gvk, accessor.GetName())
-				klog.Errorf(err.Error())
+				log.Error(err, "Failed to delete child", "gvk", gvk, "name", accessor.GetName())
+				record.Warnf(cluster, record.FailedDeleteReason, "Failed to delete %s %q: %v", kind, accessor.GetName(), err)
 				errs = append(errs, err)
+				continue
 			}
+			record.Eventf(cluster, record.SuccessfulDeleteReason, "Deleted %s %q", kind, accessor.GetName())
 		}
 
 		if len(errs) > 0 {
@@ -203,7 +222,7 @@ func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	}
 
 	if cluster.Spec.InfrastructureRef != nil {
-		obj, err := external.Get(r.Client, cluster.Spec.InfrastructureRef, cluster.Namespace)
+		obj, err := external.Get(ctx, r.Client, cluster.Spec.InfrastructureRef, cluster.Namespace)
 		switch {
 		case apierrors.IsNotFound(err):
 			// All good - the infra resource has been deleted
@@ -215,10 +234,14 @@ func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 			// Issue a deletion request for the infrastructure object.
 			// Once it's been deleted, the cluster will get processed again.
 			if err := r.Client.Delete(ctx, obj); err != nil {
+				record.Warnf(cluster, record.FailedDeleteReason, "Failed to delete %s %q: %v", obj.GetKind(), obj.GetName(), err)
 				return ctrl.Result{}, errors.Wrapf(err,
 					"failed to delete %v %q for Cluster %q in namespace %q",
 					obj.GroupVersionKind(), obj.GetName(), cluster.Name, cluster.Namespace)
 			}
+			if obj.GetDeletionTimestamp() == nil {
+				record.Eventf(cluster, record.SuccessfulDeleteReason, "Deleted %s %q", obj.GetKind(), obj.GetName())
+			}
 
 			// Return here so we don't remove the finalizer yet.
 			return ctrl.Result{}, nil
@@ -256,7 +279,7 @@ func (r *ClusterReconciler) listChildren(ctx context.Context, cluster *clusterv1
 	eachFunc := func(o runtime.Object) error {
 		acc, err := meta.Accessor(o)
 		if err != nil {
-			klog.Errorf("Cluster %s/%s: couldn't create accessor for type %T: %v", cluster.Namespace, cluster.Name, o, err)
+			logutil.FromContext(ctx).Error(err, "Couldn't create accessor", "type", fmt.Sprintf("%T", o))
 			return nil
 		}
 
@@ -304,7 +327,7 @@ func (r *ClusterReconciler) reconcileControlPlaneInitialized(ctx context.Context
 
 	machines, err := getActiveMachinesInCluster(ctx, r.Client, cluster.Namespace, cluster.Name)
 	if err != nil {
-		r.Log.Error(err, "error getting machines in cluster", "cluster", cluster.Name, "namespace", cluster.Namespace)
+		logutil.FromContext(ctx).Error(err, "Error getting machines in cluster")
 		return err
 	}
 
@@ -335,7 +358,8 @@ func (r *ClusterReconciler) controlPlaneMachineToCluster(o handler.MapObject) []
 
 	cluster, err := util.GetClusterFromMetadata(context.TODO(), r.Client, m.ObjectMeta)
 	if err != nil {
-		r.Log.Error(err, "failed to get cluster", "machine", m.Name, "cluster", m.ClusterName, "namespace", m.Namespace)
+		r.Log.Error(err, "Failed to get cluster", logutil.NamespaceKey, m.Namespace,
+			logutil.ClusterKey, m.Labels[clusterv1.MachineClusterLabelName], logutil.MachineKey, m.Name)
 		return nil
 	}
 
@@ -26,14 +26,16 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/klog"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	logutil "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/record"
 	"sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -68,7 +70,7 @@ func (r *ClusterReconciler) reconcilePhase(ctx context.Context, cluster *cluster
 
 // reconcileExternal handles generic unstructured objects referenced by a Cluster.
 func (r *ClusterReconciler) reconcileExternal(ctx context.Context, cluster *clusterv1.Cluster, ref *corev1.ObjectReference) (*unstructured.Unstructured, error) {
-	obj, err := external.Get(r.Client, ref, cluster.Namespace)
+	obj, err := external.Get(ctx, r.Client, ref, cluster.Namespace)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil, errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: 30 * time.Second},
@@ -103,7 +105,7 @@ func (r *ClusterReconciler) reconcileExternal(ctx context.Context, cluster *clus
 	// Add watcher for external object, if there isn't one already.
 	_, loaded := r.externalWatchers.LoadOrStore(obj.GroupVersionKind().String(), struct{}{})
 	if !loaded && r.controller != nil {
-		klog.Infof("Adding watcher on external object %q", obj.GroupVersionKind())
+		logutil.FromContext(ctx).Info("Adding watcher on external object", "gvk", obj.GroupVersionKind().String())
 		err := r.controller.Watch(
 			&source.Kind{Type: obj},
 			&handler.EnqueueRequestForOwner{OwnerType: &clusterv1.Cluster{}},
@@ -156,7 +158,7 @@ func (r *ClusterReconciler) reconcileInfrastructure(ctx context.Context, cluster
 		if err != nil {
 			return err
 		} else if !ready {
-			klog.V(3).Infof("Infrastructure provider for Cluster %q in namespace %q is not ready yet", cluster.Name, cluster.Namespace)
+			logutil.FromContext(ctx).V(3).Info("Infrastructure provider is not ready yet")
 			return nil
 		}
 		cluster.Status.InfrastructureReady = true
@@ -181,7 +183,7 @@ func (r *ClusterReconciler) reconcileKubeconfig(ctx context.Context, cluster *cl
 		return nil
 	}
 
-	_, err := secret.Get(r.Client, cluster, secret.Kubeconfig)
+	configSecret, err := secret.Get(r.Client, cluster, secret.Kubeconfig)
 	switch {
 	case apierrors.IsNotFound(err):
 		if err := kubeconfig.CreateSecret(ctx, r.Client, cluster); err != nil {
@@ -194,6 +196,72 @@ func (r *ClusterReconciler) reconcileKubeconfig(ctx context.Context, cluster *cl
 		}
 	case err != nil:
 		return errors.Wrapf(err, "failed to retrieve Kubeconfig Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	default:
+		return r.reconcileKubeconfigRotation(ctx, cluster, configSecret)
 	}
 	return nil
 }
+
+// reconcileKubeconfigRotation records the expiration time of the client certificate in the Kubeconfig secret,
+// and regenerates it before it expires if the secret has been created by the Cluster controller.
+func (r *ClusterReconciler) reconcileKubeconfigRotation(ctx context.Context, cluster *clusterv1.Cluster, configSecret *corev1.Secret) error {
+	expiry, err := kubeconfig.ClientCertificateExpiry(configSecret.Data[secret.KubeconfigDataName])
+	if err != nil {
+		// Kubeconfigs provided by users may not rely on client certificates.
+		logutil.FromContext(ctx).V(4).Info("Unable to determine client certificate expiration for Kubeconfig", "error", err)
+		return nil
+	}
+
+	if time.Now().Add(kubeconfigRotationThreshold).After(expiry) {
+		// Kubeconfigs provided by users are never rotated.
+		if !util.PointsTo(configSecret.OwnerReferences, &cluster.ObjectMeta) {
+			record.Warnf(cluster, record.KubeconfigExpiringSoonReason,
+				"Client certificate in user provided Kubeconfig secret %q expires at %s", configSecret.Name, expiry.UTC().Format(time.RFC3339))
+		} else {
+			if err := kubeconfig.RegenerateSecret(ctx, r.Client, cluster, configSecret); err != nil {
+				record.Warnf(cluster, record.FailedRotateKubeconfigReason, "Failed to rotate Kubeconfig secret %q: %v", configSecret.Name, err)
+				return errors.Wrapf(err, "failed to rotate Kubeconfig Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+			}
+			if expiry, err = kubeconfig.ClientCertificateExpiry(configSecret.Data[secret.KubeconfigDataName]); err != nil {
+				return errors.Wrapf(err, "failed to parse rotated Kubeconfig Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+			}
+			record.Eventf(cluster, record.SuccessfulRotateKubeconfigReason,
+				"Rotated Kubeconfig secret %q, client certificate expires at %s", configSecret.Name, expiry.UTC().Format(time.RFC3339))
+		}
+	}
+
+	if cluster.Status.CertificatesExpiry == nil {
+		cluster.Status.CertificatesExpiry = &clusterv1.CertificatesExpiry{}
+	}
+	cluster.Status.CertificatesExpiry.Kubeconfig = &metav1.Time{Time: expiry}
+	return nil
+}
+
+// reconcileCertificatesExpiry records the expiration time of the cluster CA
+// and emits a warning event if it is about to expire.
+func (r *ClusterReconciler) reconcileCertificatesExpiry(ctx context.Context, cluster *clusterv1.Cluster) error {
+	caSecret, err := secret.Get(r.Client, cluster, secret.ClusterCA)
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return errors.Wrapf(err, "failed to retrieve CA Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	cert, err := certs.DecodeCertPEM(caSecret.Data[secret.TLSCrtDataName])
+	if err != nil || cert == nil {
+		logutil.FromContext(ctx).V(4).Info("Unable to decode CA certificate", "error", err)
+		return nil
+	}
+
+	if time.Now().Add(certificateExpiryWarningThreshold).After(cert.NotAfter) {
+		record.Warnf(cluster, record.ClusterCAExpiringSoonReason,
+			"Cluster CA in secret %q expires at %s and must be rotated manually", caSecret.Name, cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	if cluster.Status.CertificatesExpiry == nil {
+		cluster.Status.CertificatesExpiry = &clusterv1.CertificatesExpiry{}
+	}
+	cluster.Status.CertificatesExpiry.ClusterCA = &metav1.Time{Time: cert.NotAfter}
+	return nil
+}
@@ -17,14 +17,27 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -101,3 +114,237 @@ func TestClusterReconciler_reconcileKubeconfig(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterReconciler_reconcileKubeconfigRotation(t *testing.T) {
+	newCluster := func() *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "test-cluster",
+				UID:  "test-uid",
+			},
+			Status: clusterv1.ClusterStatus{
+				APIEndpoints: []clusterv1.APIEndpoint{{
+					Host: "1.2.3.4",
+					Port: 6443,
+				}},
+			},
+		}
+	}
+	caCert, caKey := newTestCA(t, time.Now().Add(24*time.Hour))
+
+	tests := []struct {
+		name          string
+		expiresIn     time.Duration
+		owned         bool
+		wantRotated   bool
+		wantEventType string
+	}{
+		{
+			name:      "valid kubeconfig is not rotated",
+			expiresIn: 365 * 24 * time.Hour,
+			owned:     true,
+		},
+		{
+			name:          "expiring kubeconfig owned by the cluster is rotated",
+			expiresIn:     time.Hour,
+			owned:         true,
+			wantRotated:   true,
+			wantEventType: corev1.EventTypeNormal,
+		},
+		{
+			name:          "expiring kubeconfig provided by the user is not rotated",
+			expiresIn:     time.Hour,
+			wantEventType: corev1.EventTypeWarning,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newCluster()
+			configSecret := &corev1.Secret{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "test-cluster-kubeconfig",
+				},
+				Data: map[string][]byte{
+					secret.KubeconfigDataName: newTestKubeconfig(t, caCert, caKey, time.Now().Add(tt.expiresIn)),
+				},
+			}
+			original := configSecret.Data[secret.KubeconfigDataName]
+			if tt.owned {
+				configSecret.OwnerReferences = []v1.OwnerReference{{Kind: "Cluster", Name: cluster.Name, UID: cluster.UID}}
+			}
+			caSecret := &corev1.Secret{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "test-cluster-ca",
+				},
+				Data: map[string][]byte{
+					secret.TLSCrtDataName: certs.EncodeCertPEM(caCert),
+					secret.TLSKeyDataName: encodeTestPrivateKey(t, caKey),
+				},
+			}
+
+			testEvents.reset()
+			r := &ClusterReconciler{
+				Client: fake.NewFakeClient(cluster, configSecret, caSecret),
+			}
+			if err := r.reconcileKubeconfig(context.Background(), cluster); err != nil {
+				t.Fatalf("reconcileKubeconfig() error = %v", err)
+			}
+
+			if cluster.Status.CertificatesExpiry == nil || cluster.Status.CertificatesExpiry.Kubeconfig == nil {
+				t.Fatal("expected kubeconfig expiry to be set in status")
+			}
+
+			data, err := kubeconfig.FromSecret(r.Client, cluster)
+			if err != nil {
+				t.Fatalf("FromSecret() error = %v", err)
+			}
+			if rotated := !bytes.Equal(data, original); tt.wantRotated != rotated {
+				t.Errorf("expected rotated = %v, got %v", tt.wantRotated, rotated)
+			}
+			expiry, err := kubeconfig.ClientCertificateExpiry(data)
+			if err != nil {
+				t.Fatalf("ClientCertificateExpiry() error = %v", err)
+			}
+			if !expiry.Equal(cluster.Status.CertificatesExpiry.Kubeconfig.Time) {
+				t.Errorf("expected status expiry %s to match secret expiry %s", cluster.Status.CertificatesExpiry.Kubeconfig, expiry)
+			}
+
+			events := testEvents.list()
+			switch {
+			case len(events) > 0 && tt.wantEventType == "":
+				t.Errorf("unexpected event %q", events[0])
+			case len(events) > 0 && !strings.HasPrefix(events[0], tt.wantEventType):
+				t.Errorf("expected %s event, got %q", tt.wantEventType, events[0])
+			case len(events) == 0 && tt.wantEventType != "":
+				t.Errorf("expected %s event, got none", tt.wantEventType)
+			}
+		})
+	}
+}
+
+func TestClusterReconciler_reconcileCertificatesExpiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresIn time.Duration
+		wantEvent bool
+	}{
+		{
+			name:      "valid CA",
+			expiresIn: 10 * 365 * 24 * time.Hour,
+		},
+		{
+			name:      "expiring CA emits a warning",
+			expiresIn: 24 * time.Hour,
+			wantEvent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "test-cluster",
+				},
+			}
+			caCert, caKey := newTestCA(t, time.Now().Add(tt.expiresIn))
+			caSecret := &corev1.Secret{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "test-cluster-ca",
+				},
+				Data: map[string][]byte{
+					secret.TLSCrtDataName: certs.EncodeCertPEM(caCert),
+					secret.TLSKeyDataName: encodeTestPrivateKey(t, caKey),
+				},
+			}
+
+			testEvents.reset()
+			r := &ClusterReconciler{
+				Client: fake.NewFakeClient(cluster, caSecret),
+			}
+			if err := r.reconcileCertificatesExpiry(context.Background(), cluster); err != nil {
+				t.Fatalf("reconcileCertificatesExpiry() error = %v", err)
+			}
+
+			if cluster.Status.CertificatesExpiry == nil || cluster.Status.CertificatesExpiry.ClusterCA == nil {
+				t.Fatal("expected cluster CA expiry to be set in status")
+			}
+			if !cluster.Status.CertificatesExpiry.ClusterCA.Time.Equal(caCert.NotAfter) {
+				t.Errorf("expected cluster CA expiry %s, got %s", caCert.NotAfter, cluster.Status.CertificatesExpiry.ClusterCA)
+			}
+			if gotEvent := len(testEvents.list()) > 0; gotEvent != tt.wantEvent {
+				t.Errorf("expected event = %v, got %v", tt.wantEvent, gotEvent)
+			}
+		})
+	}
+}
+
+func newTestCA(t *testing.T, notAfter time.Time) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := certs.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber:          new(big.Int).SetInt64(0),
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		NotBefore:             time.Now().Add(-time.Hour).UTC(),
+		NotAfter:              notAfter.UTC(),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	b, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, key
+}
+
+func newTestKubeconfig(t *testing.T, caCert *x509.Certificate, caKey *rsa.PrivateKey, notAfter time.Time) []byte {
+	key, err := certs.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber: new(big.Int).SetInt64(1),
+		Subject:      pkix.Name{CommonName: "kubernetes-admin"},
+		NotBefore:    time.Now().Add(-time.Hour).UTC(),
+		NotAfter:     notAfter.UTC(),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	b, err := x509.CreateCertificate(rand.Reader, &tmpl, caCert, key.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := clientcmd.Write(api.Config{
+		Clusters: map[string]*api.Cluster{
+			"test-cluster": {Server: "https://1.2.3.4:6443", CertificateAuthorityData: certs.EncodeCertPEM(caCert)},
+		},
+		Contexts: map[string]*api.Context{
+			"admin@test-cluster": {Cluster: "test-cluster", AuthInfo: "admin"},
+		},
+		AuthInfos: map[string]*api.AuthInfo{
+			"admin": {ClientCertificateData: certs.EncodeCertPEM(c), ClientKeyData: encodeTestPrivateKey(t, key)},
+		},
+		CurrentContext: "admin@test-cluster",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func encodeTestPrivateKey(t *testing.T, key *rsa.PrivateKey) []byte {
+	out, err := certs.EncodePrivateKeyPEM(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/cluster-api/util/record"
+)
+
+// testEvents collects the events recorded by the controllers under test.
+var testEvents = &eventRecorder{}
+
+func init() {
+	record.InitFromRecorder(testEvents)
+}
+
+// eventRecorder keeps the events it records as "<type> <reason> <message>" strings.
+// Unlike record.FakeRecorder, it never blocks, so that it can be shared by every test of the package.
+type eventRecorder struct {
+	lock   sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, eventtype, reason, message string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, fmt.Sprintf("%s %s %s", eventtype, reason, message))
+}
+
+func (r *eventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *eventRecorder) PastEventf(object runtime.Object, _ metav1.Time, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Eventf(object, eventtype, reason, messageFmt, args...)
+}
+
+func (r *eventRecorder) AnnotatedEventf(object runtime.Object, _ map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Eventf(object, eventtype, reason, messageFmt, args...)
+}
+
+// reset drops the events recorded so far.
+func (r *eventRecorder) reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = nil
+}
+
+// list returns the events recorded since the last reset.
+func (r *eventRecorder) list() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var events []string
+	return append(events, r.events...)
+}
@@ -18,31 +18,41 @@ package external
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	logutil "sigs.k8s.io/cluster-api/util/log"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Get uses the client and reference to get an external, unstructured object.
-func Get(c client.Client, ref *corev1.ObjectReference, namespace string) (*unstructured.Unstructured, error) {
+func Get(ctx context.Context, c client.Reader, ref *corev1.ObjectReference, namespace string) (_ *unstructured.Unstructured, reterr error) {
+	ctx, span := tracing.Start(ctx, "external.Get", "kind", ref.Kind, "name", ref.Name)
+	defer func() { tracing.End(ctx, span, reterr) }()
+
 	obj := new(unstructured.Unstructured)
 	obj.SetAPIVersion(ref.APIVersion)
 	obj.SetKind(ref.Kind)
 	obj.SetName(ref.Name)
 	key := client.ObjectKey{Name: obj.GetName(), Namespace: namespace}
-	if err := c.Get(context.Background(), key, obj); err != nil {
+	logutil.FromContext(ctx).V(4).Info("Getting external object", "kind", ref.Kind, "name", ref.Name)
+	if err := c.Get(ctx, key, obj); err != nil {
 		return nil, err
 	}
 	return obj, nil
 }
 
 // CloneTemplate uses the client and the reference to create a new object from the template.
-func CloneTemplate(c client.Client, ref *corev1.ObjectReference, namespace string) (*unstructured.Unstructured, error) {
-	from, err := Get(c, ref, namespace)
+func CloneTemplate(ctx context.Context, c client.Client, ref *corev1.ObjectReference, namespace string) (*unstructured.Unstructured, error) {
+	from, err := Get(ctx, c, ref, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +63,11 @@ func CloneTemplate(c client.Client, ref *corev1.ObjectReference, namespace strin
 		return nil, errors.Wrapf(err, "failed to retrieve Spec.Template map on %v %q", from.GroupVersionKind(), from.GetName())
 	}
 
+	hash, err := TemplateHash(from)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the unstructured object from the template.
 	to := &unstructured.Unstructured{Object: template}
 	to.SetResourceVersion("")
@@ -64,6 +79,16 @@ func CloneTemplate(c client.Client, ref *corev1.ObjectReference, namespace strin
 	to.SetGenerateName(fmt.Sprintf("%s-", from.GetName()))
 	to.SetNamespace(namespace)
 
+	// Record the template the object was cloned from.
+	annotations := to.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[clusterv1.TemplateClonedFromNameAnnotation] = from.GetName()
+	annotations[clusterv1.TemplateClonedFromGenerationAnnotation] = strconv.FormatInt(from.GetGeneration(), 10)
+	annotations[clusterv1.TemplateClonedFromHashAnnotation] = hash
+	to.SetAnnotations(annotations)
+
 	// Set the object APIVersion.
 	if to.GetAPIVersion() == "" {
 		to.SetAPIVersion(ref.APIVersion)
@@ -75,9 +100,10 @@ func CloneTemplate(c client.Client, ref *corev1.ObjectReference, namespace strin
 	}
 
 	// Create the external clone.
-	if err := c.Create(context.Background(), to); err != nil {
+	if err := c.Create(ctx, to); err != nil {
 		return nil, err
 	}
+	logutil.FromContext(ctx).Info("Cloned external template", "kind", to.GetKind(), "name", to.GetName(), "template", ref.Name)
 	return to, nil
 }
 
@@ -105,3 +131,20 @@ func IsReady(obj *unstructured.Unstructured) (bool, error) {
 	}
 	return ready && found, nil
 }
+
+// TemplateHash returns a hash of the content of a template, its spec.template field, which is what
+// CloneTemplate clones. It changes whenever the template is edited in a way that affects its clones.
+func TemplateHash(template *unstructured.Unstructured) (string, error) {
+	content, _, err := unstructured.NestedFieldNoCopy(template.Object, "spec", "template")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to retrieve Spec.Template of %v %q", template.GroupVersionKind(), template.GetName())
+	}
+	// Maps are marshalled with sorted keys, so equal contents have the same hash.
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal Spec.Template of %v %q", template.GroupVersionKind(), template.GetName())
+	}
+	hasher := fnv.New32a()
+	hasher.Write(data)
+	return strconv.FormatUint(uint64(hasher.Sum32()), 10), nil
+}
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -31,15 +32,17 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/record"
-	"k8s.io/klog"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/metrics"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	kubedrain "sigs.k8s.io/cluster-api/third_party/kubernetes-drain"
 	"sigs.k8s.io/cluster-api/util"
+	logutil "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -64,7 +67,6 @@ type MachineReconciler struct {
 
 	config           *rest.Config
 	controller       controller.Controller
-	recorder         record.EventRecorder
 	externalWatchers sync.Map
 }
 
@@ -75,14 +77,15 @@ func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager, options controlle
 		Build(r)
 
 	r.controller = c
-	r.recorder = mgr.GetEventRecorderFor("machine-controller")
 	r.config = mgr.GetConfig()
 	return err
 }
 
 func (r *MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
-	ctx := context.Background()
-	_ = r.Log.WithValues("machine", req.NamespacedName)
+	log := r.Log.WithValues(logutil.NamespaceKey, req.Namespace, logutil.MachineKey, req.Name)
+	ctx := logutil.IntoContext(context.Background(), log)
+	ctx, span := tracing.Start(ctx, "MachineReconciler.Reconcile", logutil.NamespaceKey, req.Namespace, logutil.MachineKey, req.Name)
+	defer func() { tracing.End(ctx, span, reterr) }()
 
 	// Fetch the Machine instance
 	m := &clusterv1.Machine{}
@@ -97,6 +100,9 @@ func (r *MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr e
 		return ctrl.Result{}, err
 	}
 
+	log = log.WithValues(logutil.ClusterKey, m.Labels[clusterv1.MachineClusterLabelName])
+	ctx = logutil.IntoContext(ctx, log)
+
 	// Initialize the patch helper
 	patchHelper, err := patch.NewHelper(m, r.Client)
 	if err != nil {
@@ -119,8 +125,7 @@ func (r *MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr e
 	// for machine management.
 	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, m.ObjectMeta)
 	if errors.Cause(err) == util.ErrNoCluster {
-		klog.V(2).Infof("Machine %q in namespace %q doesn't specify %q label, assuming nil cluster",
-			m.Name, m.Namespace, clusterv1.MachineClusterLabelName)
+		log.V(2).Info("Machine doesn't specify the cluster label, assuming nil cluster", "label", clusterv1.MachineClusterLabelName)
 	} else if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to get cluster %q for machine %q in namespace %q",
 			m.Labels[clusterv1.MachineClusterLabelName], m.Name, m.Namespace)
@@ -136,6 +141,8 @@ func (r *MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr e
 }
 
 func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
+	log := logutil.FromContext(ctx)
+
 	// If the Machine belongs to a cluster, add an owner reference.
 	if cluster != nil && r.shouldAdopt(m) {
 		m.OwnerReferences = util.EnsureOwnerRef(m.OwnerReferences, metav1.OwnerReference{
@@ -156,6 +163,7 @@ func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 		r.reconcileBootstrap(ctx, m),
 		r.reconcileInfrastructure(ctx, m),
 		r.reconcileNodeRef(ctx, cluster, m),
+		r.reconcileNodeMetadata(ctx, cluster, m),
 	}
 
 	// Parse the errors, making sure we record if there is a RequeueAfterError.
@@ -167,7 +175,7 @@ func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 			if !res.Requeue {
 				res.Requeue = true
 				res.RequeueAfter = requeueErr.GetRequeueAfter()
-				klog.Infof("Reconciliation for Machine %q in namespace %q asked to requeue: %v", m.Name, m.Namespace, err)
+				log.Info("Reconciliation asked to requeue", "requeueAfter", res.RequeueAfter, "error", err)
 			}
 			continue
 		}
@@ -178,27 +186,32 @@ func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 }
 
 func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
+	log := logutil.FromContext(ctx)
+
 	if err := r.isDeleteNodeAllowed(ctx, m); err != nil {
 		switch err {
 		case errNilNodeRef:
-			klog.V(2).Infof("Deleting node is not allowed for machine %q: %v", m.Name, err)
+			log.V(2).Info("Deleting node is not allowed", "error", err)
 		case errNoControlPlaneNodes, errLastControlPlaneNode:
-			klog.V(2).Infof("Deleting node %q is not allowed for machine %q: %v", m.Status.NodeRef.Name, m.Name, err)
+			log.V(2).Info("Deleting node is not allowed", "node", m.Status.NodeRef.Name, "error", err)
 		default:
-			klog.Errorf("IsDeleteNodeAllowed check failed for machine %q: %v", m.Name, err)
+			log.Error(err, "IsDeleteNodeAllowed check failed")
 			return ctrl.Result{}, err
 		}
 	} else {
 		// Drain node before deletion
 		if _, exists := m.ObjectMeta.Annotations[clusterv1.ExcludeNodeDrainingAnnotation]; !exists {
-			klog.Infof("Draining node %q for machine %q", m.Status.NodeRef.Name, m.Name)
-			if err := r.drainNode(ctx, cluster, m.Status.NodeRef.Name, m.Name); err != nil {
-				r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
+			log.Info("Draining node", "node", m.Status.NodeRef.Name)
+			drainStart := time.Now()
+			err := r.drainNode(ctx, cluster, m.Status.NodeRef.Name)
+			metrics.ObserveMachineDrain(m, drainStart, err)
+			if err != nil {
+				record.Warnf(m, record.FailedDrainNodeReason, "Failed to drain Node %q: %v", m.Status.NodeRef.Name, err)
 				return ctrl.Result{}, err
 			}
-			r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
+			record.Eventf(m, record.SuccessfulDrainNodeReason, "Drained Node %q", m.Status.NodeRef.Name)
 		}
-		klog.Infof("Deleting node %q for Machine %s/%s", m.Status.NodeRef.Name, m.Namespace, m.Name)
+		log.Info("Deleting node", "node", m.Status.NodeRef.Name)
 
 		var deleteNodeErr error
 		waitErr := wait.PollImmediate(2*time.Second, 10*time.Second, func() (bool, error) {
@@ -208,9 +221,10 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 			return true, nil
 		})
 		if waitErr != nil {
-			// TODO: remove m.Name after #1203
-			r.Log.Error(deleteNodeErr, "timed out deleting Machine's node, moving on", "node", m.Status.NodeRef.Name, "machine", m.Name)
-			r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDeleteNode", "error deleting Machine's node: %v", deleteNodeErr)
+			log.Error(deleteNodeErr, "Timed out deleting Machine's node, moving on", "node", m.Status.NodeRef.Name)
+			record.Warnf(m, record.FailedDeleteNodeReason, "Failed to delete Node %q: %v", m.Status.NodeRef.Name, deleteNodeErr)
+		} else {
+			record.Eventf(m, record.SuccessfulDeleteNodeReason, "Deleted Node %q", m.Status.NodeRef.Name)
 		}
 	}
 
@@ -256,7 +270,12 @@ func (r *MachineReconciler) isDeleteNodeAllowed(ctx context.Context, machine *cl
 	}
 }
 
-func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cluster, nodeName string, machineName string) error {
+func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cluster, nodeName string) (reterr error) {
+	ctx, span := tracing.Start(ctx, "MachineReconciler.drainNode", "node", nodeName)
+	defer func() { tracing.End(ctx, span, reterr) }()
+
+	log := logutil.FromContext(ctx, "node", nodeName)
+
 	var kubeClient kubernetes.Interface
 	if cluster == nil {
 		var err error
@@ -266,16 +285,14 @@ func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cl
 		}
 	} else {
 		// Otherwise, proceed to get the remote cluster client and get the Node.
-		remoteClient, err := remote.NewClusterClient(r.Client, cluster)
+		remoteClient, err := remote.NewClusterClient(ctx, r.Client, cluster)
 		if err != nil {
-			klog.Errorf("Error creating a remote client for Cluster %q while deleting Machine %s/%s, won't retry: %v",
-				cluster.Name, cluster.Namespace, machineName, err)
+			log.Error(err, "Error creating a remote client while deleting Machine, won't retry")
 			return nil
 		}
 		kubeClient, err = kubernetes.NewForConfig(remoteClient.RESTConfig())
 		if err != nil {
-			klog.Errorf("Error creating a remote client for Cluster %q while deleting Machine %s/%s, won't retry: %v",
-				cluster.Name, cluster.Namespace, machineName, err)
+			log.Error(err, "Error creating a remote client while deleting Machine, won't retry")
 			return nil
 		}
 	}
@@ -284,8 +301,7 @@ func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cl
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			// If an admin deletes the node directly, we'll end up here.
-			klog.Infof("Machine %s/%s: Could not find node %v from noderef, it may have already been deleted: %v",
-				cluster.Namespace, machineName, nodeName, err)
+			log.Info("Could not find node from noderef, it may have already been deleted", "error", err)
 			return nil
 		}
 		return errors.Errorf("unable to get node %q: %v", nodeName, err)
@@ -305,30 +321,35 @@ func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cl
 			if usingEviction {
 				verbStr = "Evicted"
 			}
-			klog.Infof("Machine %s/%s: %s pod %s/%s from Node %q", cluster.Namespace, machineName, verbStr, pod.Namespace, pod.Name, nodeName)
+			log.Info(fmt.Sprintf("%s pod from Node", verbStr), "pod", pod.Namespace+"/"+pod.Name)
 		},
-		Out:    writer{klog.Info},
-		ErrOut: writer{klog.Error},
+		Out: writer{log.Info},
+		ErrOut: writer{func(msg string, keysAndValues ...interface{}) {
+			log.Error(nil, msg, keysAndValues...)
+		}},
 		DryRun: false,
 	}
 
 	if err := kubedrain.RunCordonOrUncordon(drainer, node, true); err != nil {
 		// Machine will be re-reconciled after a cordon failure.
-		klog.Errorf("Machine %s/%s: Cordon failed for node %q: %v", cluster.Namespace, machineName, nodeName, err)
+		log.Error(err, "Cordon failed")
 		return errors.Errorf("unable to cordon node %s: %v", node.Name, err)
 	}
 
 	if err := kubedrain.RunNodeDrain(drainer, node.Name); err != nil {
 		// Machine will be re-reconciled after a drain failure.
-		klog.Warningf("Machine %s/%s: Drain failed for node %q: %v", cluster.Namespace, machineName, nodeName, err)
+		log.Info("Drain failed, retrying", "error", err)
 		return &capierrors.RequeueAfterError{RequeueAfter: 20 * time.Second}
 	}
 
-	klog.Infof("Drain successful for Machine %s/%s", cluster.Namespace, machineName)
+	log.Info("Drain successful")
 	return nil
 }
 
-func (r *MachineReconciler) deleteNode(ctx context.Context, cluster *clusterv1.Cluster, name string) error {
+func (r *MachineReconciler) deleteNode(ctx context.Context, cluster *clusterv1.Cluster, name string) (reterr error) {
+	ctx, span := tracing.Start(ctx, "MachineReconciler.deleteNode", "node", name)
+	defer func() { tracing.End(ctx, span, reterr) }()
+
 	if cluster == nil {
 		// Try to retrieve the Node from the local cluster, if no Cluster reference is found.
 		var node corev1.Node
@@ -339,17 +360,17 @@ func (r *MachineReconciler) deleteNode(ctx context.Context, cluster *clusterv1.C
 	}
 
 	// Otherwise, proceed to get the remote cluster client and get the Node.
-	remoteClient, err := remote.NewClusterClient(r.Client, cluster)
+	log := logutil.FromContext(ctx, "node", name)
+
+	remoteClient, err := remote.NewClusterClient(ctx, r.Client, cluster)
 	if err != nil {
-		klog.Errorf("Error creating a remote client for cluster %q while deleting Machine %q, won't retry: %v",
-			cluster.Name, name, err)
+		log.Error(err, "Error creating a remote client while deleting Machine, won't retry")
 		return nil
 	}
 
 	corev1Remote, err := remoteClient.CoreV1()
 	if err != nil {
-		klog.Errorf("Error creating a remote client for cluster %q while deleting Machine %q, won't retry: %v",
-			cluster.Name, name, err)
+		log.Error(err, "Error creating a remote client while deleting Machine, won't retry")
 		return nil
 	}
 
@@ -370,7 +391,7 @@ func (r *MachineReconciler) reconcileDeleteExternal(ctx context.Context, m *clus
 			continue
 		}
 
-		obj, err := external.Get(r.Client, ref, m.Namespace)
+		obj, err := external.Get(ctx, r.Client, ref, m.Namespace)
 		if err != nil && !apierrors.IsNotFound(err) {
 			return false, errors.Wrapf(err, "failed to get %s %q for Machine %q in namespace %q",
 				ref.GroupVersionKind(), ref.Name, m.Name, m.Namespace)
@@ -383,10 +404,14 @@ func (r *MachineReconciler) reconcileDeleteExternal(ctx context.Context, m *clus
 	// Issue a delete request for any object that has been found.
 	for _, obj := range objects {
 		if err := r.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			record.Warnf(m, record.FailedDeleteReason, "Failed to delete %s %q: %v", obj.GetKind(), obj.GetName(), err)
 			return false, errors.Wrapf(err,
 				"failed to delete %v %q for Machine %q in namespace %q",
 				obj.GroupVersionKind(), obj.GetName(), m.Name, m.Namespace)
 		}
+		if obj.GetDeletionTimestamp() == nil {
+			record.Eventf(m, record.SuccessfulDeleteReason, "Deleted %s %q", obj.GetKind(), obj.GetName())
+		}
 	}
 
 	// Return true if there are no more external objects.
@@ -397,9 +422,9 @@ func (r *MachineReconciler) shouldAdopt(m *clusterv1.Machine) bool {
 	return !util.HasOwner(m.OwnerReferences, clusterv1.GroupVersion.String(), []string{"MachineSet", "Cluster"})
 }
 
-// writer implements io.Writer interface as a pass-through for klog.
+// writer implements io.Writer interface as a pass-through for a logger.
 type writer struct {
-	logFunc func(args ...interface{})
+	logFunc func(msg string, keysAndValues ...interface{})
 }
 
 // Write passes string(p) into writer's logFunc and always returns len(p)
@@ -22,13 +22,19 @@ import (
 
 	"github.com/pkg/errors"
 	apicorev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
-	"k8s.io/klog"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/metrics"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	logutil "sigs.k8s.io/cluster-api/util/log"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 )
 
 var (
@@ -36,6 +42,8 @@ var (
 )
 
 func (r *MachineReconciler) reconcileNodeRef(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) error {
+	log := logutil.FromContext(ctx)
+
 	// Check that the Machine hasn't been deleted or in the process.
 	if !machine.DeletionTimestamp.IsZero() {
 		return nil
@@ -48,13 +56,13 @@ func (r *MachineReconciler) reconcileNodeRef(ctx context.Context, cluster *clust
 
 	// Check that Cluster isn't nil.
 	if cluster == nil {
-		klog.V(2).Infof("Machine %q in namespace %q doesn't have a linked cluster, won't assign NodeRef", machine.Name, machine.Namespace)
+		log.V(2).Info("Machine doesn't have a linked cluster, won't assign NodeRef")
 		return nil
 	}
 
 	// Check that the Machine has a valid ProviderID.
 	if machine.Spec.ProviderID == nil || *machine.Spec.ProviderID == "" {
-		klog.Warningf("Machine %q in namespace %q doesn't have a valid ProviderID yet", machine.Name, machine.Namespace)
+		log.Info("Machine doesn't have a valid ProviderID yet")
 		return nil
 	}
 
@@ -63,7 +71,7 @@ func (r *MachineReconciler) reconcileNodeRef(ctx context.Context, cluster *clust
 		return err
 	}
 
-	clusterClient, err := remote.NewClusterClient(r.Client, cluster)
+	clusterClient, err := remote.NewClusterClient(ctx, r.Client, cluster)
 	if err != nil {
 		return err
 	}
@@ -74,25 +82,142 @@ func (r *MachineReconciler) reconcileNodeRef(ctx context.Context, cluster *clust
 	}
 
 	// Get the Node reference.
-	nodeRef, err := r.getNodeReference(corev1Client, providerID)
+	nodeRef, err := r.getNodeReference(ctx, corev1Client, providerID)
 	if err != nil {
 		if err == ErrNodeNotFound {
 			return errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: 10 * time.Second},
 				"cannot assign NodeRef to Machine %q in namespace %q, no matching Node", machine.Name, machine.Namespace)
 		}
-		klog.Errorf("Failed to assign NodeRef to Machine %q in namespace %q: %v", machine.Name, machine.Namespace, err)
-		r.recorder.Event(machine, apicorev1.EventTypeWarning, "FailedSetNodeRef", err.Error())
+		log.Error(err, "Failed to assign NodeRef")
+		record.Warn(machine, record.FailedSetNodeRefReason, err.Error())
 		return err
 	}
 
 	// Set the Machine NodeRef.
 	machine.Status.NodeRef = nodeRef
-	klog.Infof("Set Machine's (%q in namespace %q) NodeRef to %q", machine.Name, machine.Namespace, machine.Status.NodeRef.Name)
-	r.recorder.Event(machine, apicorev1.EventTypeNormal, "SuccessfulSetNodeRef", machine.Status.NodeRef.Name)
+	metrics.ObserveMachineProvisioned(machine, time.Now())
+	log.Info("Set Machine's NodeRef", "node", machine.Status.NodeRef.Name)
+	record.Event(machine, record.SuccessfulSetNodeRefReason, machine.Status.NodeRef.Name)
+	return nil
+}
+
+// reconcileNodeMetadata keeps the labels, annotations and taints of the Machine's Node in sync with its spec.
+func (r *MachineReconciler) reconcileNodeMetadata(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) error {
+	// Check that the Machine isn't being deleted, has a Node and a linked cluster.
+	if !machine.DeletionTimestamp.IsZero() || machine.Status.NodeRef == nil || cluster == nil {
+		return nil
+	}
+
+	clusterClient, err := remote.NewClusterClient(ctx, r.Client, cluster)
+	if err != nil {
+		return err
+	}
+
+	corev1Client, err := clusterClient.CoreV1()
+	if err != nil {
+		return err
+	}
+
+	return r.updateNodeMetadata(ctx, corev1Client, machine)
+}
+
+func (r *MachineReconciler) updateNodeMetadata(ctx context.Context, client corev1.NodesGetter, machine *clusterv1.Machine) (reterr error) {
+	nodeName := machine.Status.NodeRef.Name
+	ctx, span := tracing.Start(ctx, "MachineReconciler.updateNodeMetadata", "node", nodeName)
+	defer func() { tracing.End(ctx, span, reterr) }()
+
+	node, err := client.Nodes().Get(nodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		logutil.FromContext(ctx).V(2).Info("Machine's Node not found, won't update its metadata", "node", nodeName)
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get Node %q", nodeName)
+	}
+
+	if !applyNodeMetadata(node, &machine.Spec) {
+		return nil
+	}
+	if _, err := client.Nodes().Update(node); err != nil {
+		record.Warnf(machine, record.FailedUpdateNodeReason, "Failed to update Node %q: %v", nodeName, err)
+		return errors.Wrapf(err, "failed to update metadata of Node %q", nodeName)
+	}
+
+	logutil.FromContext(ctx).Info("Updated Node metadata", "node", nodeName)
+	record.Eventf(machine, record.SuccessfulUpdateNodeReason, "Updated labels, annotations and taints of Node %q", nodeName)
 	return nil
 }
 
-func (r *MachineReconciler) getNodeReference(client corev1.NodesGetter, providerID *noderefutil.ProviderID) (*apicorev1.ObjectReference, error) {
+// applyNodeMetadata sets the NodeLabels, NodeAnnotations and NodeTaints of the Machine spec on the Node, and removes
+// the ones previously set from the spec but since removed from it. It returns true if the Node changed.
+// The keys set from the spec are recorded in annotations of the Node, so that labels, annotations and taints
+// set by other means are left untouched.
+func applyNodeMetadata(node *apicorev1.Node, spec *clusterv1.MachineSpec) bool {
+	original := node.DeepCopy()
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+
+	previousLabels := managedKeys(node.Annotations, clusterv1.ManagedNodeLabelsAnnotation)
+	previousAnnotations := managedKeys(node.Annotations, clusterv1.ManagedNodeAnnotationsAnnotation)
+	previousTaints := managedKeys(node.Annotations, clusterv1.ManagedNodeTaintsAnnotation)
+
+	syncManagedMap(node.Labels, spec.NodeLabels, previousLabels)
+	syncManagedMap(node.Annotations, spec.NodeAnnotations, previousAnnotations)
+
+	desiredTaints := map[string]apicorev1.Taint{}
+	for _, taint := range spec.NodeTaints {
+		if _, ok := desiredTaints[nodeTaintKey(taint)]; !ok {
+			desiredTaints[nodeTaintKey(taint)] = taint
+		}
+	}
+	var taints []apicorev1.Taint
+	for _, taint := range node.Spec.Taints {
+		key := nodeTaintKey(taint)
+		if desired, ok := desiredTaints[key]; ok {
+			// Keep the time a NoExecute taint was added, as set by the API server.
+			if desired.TimeAdded == nil {
+				desired.TimeAdded = taint.TimeAdded
+			}
+			taints = append(taints, desired)
+			delete(desiredTaints, key)
+			continue
+		}
+		if previousTaints.Has(key) {
+			continue
+		}
+		taints = append(taints, taint)
+	}
+	managedTaints := sets.NewString()
+	for _, taint := range spec.NodeTaints {
+		key := nodeTaintKey(taint)
+		managedTaints.Insert(key)
+		if desired, ok := desiredTaints[key]; ok {
+			taints = append(taints, desired)
+			delete(desiredTaints, key)
+		}
+	}
+	node.Spec.Taints = taints
+
+	setManagedKeys(node.Annotations, clusterv1.ManagedNodeLabelsAnnotation, sets.StringKeySet(spec.NodeLabels))
+	setManagedKeys(node.Annotations, clusterv1.ManagedNodeAnnotationsAnnotation, sets.StringKeySet(spec.NodeAnnotations))
+	setManagedKeys(node.Annotations, clusterv1.ManagedNodeTaintsAnnotation, managedTaints)
+
+	return !apiequality.Semantic.DeepEqual(original.ObjectMeta, node.ObjectMeta) ||
+		!apiequality.Semantic.DeepEqual(original.Spec.Taints, node.Spec.Taints)
+}
+
+// nodeTaintKey identifies a taint by its key and effect, like the API server does.
+func nodeTaintKey(taint apicorev1.Taint) string {
+	return taint.Key + ":" + string(taint.Effect)
+}
+
+func (r *MachineReconciler) getNodeReference(ctx context.Context, client corev1.NodesGetter, providerID *noderefutil.ProviderID) (_ *apicorev1.ObjectReference, reterr error) {
+	ctx, span := tracing.Start(ctx, "MachineReconciler.getNodeReference", "providerID", providerID.String())
+	defer func() { tracing.End(ctx, span, reterr) }()
+
 	listOpt := metav1.ListOptions{}
 
 	for {
@@ -104,7 +229,7 @@ func (r *MachineReconciler) getNodeReference(client corev1.NodesGetter, provider
 		for _, node := range nodeList.Items {
 			nodeProviderID, err := noderefutil.NewProviderID(node.Spec.ProviderID)
 			if err != nil {
-				klog.V(3).Infof("Failed to parse ProviderID for Node %q: %v", node.Name, err)
+				logutil.FromContext(ctx).V(3).Info("Failed to parse ProviderID", "node", node.Name, "error", err)
 				continue
 			}
 
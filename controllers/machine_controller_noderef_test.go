@@ -17,15 +17,16 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"strings"
 	"testing"
 
+	"github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	fakeclient "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -35,9 +36,8 @@ import (
 func TestGetNodeReference(t *testing.T) {
 	clusterv1.AddToScheme(scheme.Scheme)
 	r := &MachineReconciler{
-		Client:   fake.NewFakeClient(),
-		Log:      log.Log,
-		recorder: record.NewFakeRecorder(32),
+		Client: fake.NewFakeClient(),
+		Log:    log.Log,
 	}
 
 	nodeList := []runtime.Object{
@@ -105,7 +105,7 @@ func TestGetNodeReference(t *testing.T) {
 				t.Fatalf("Expected no error parsing provider id %q, got %v", test.providerID, err)
 			}
 
-			reference, err := r.getNodeReference(coreV1Client, providerID)
+			reference, err := r.getNodeReference(context.Background(), coreV1Client, providerID)
 			if err != nil {
 				if (test.err != nil && !strings.Contains(err.Error(), test.err.Error())) || test.err == nil {
 					t.Fatalf("Expected error %v, got %v", test.err, err)
@@ -127,3 +127,83 @@ func TestGetNodeReference(t *testing.T) {
 
 	}
 }
+
+func TestUpdateNodeMetadata(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	r := &MachineReconciler{
+		Client: fake.NewFakeClient(),
+		Log:    log.Log,
+	}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node-1",
+			Labels:      map[string]string{"kubernetes.io/hostname": "node-1"},
+			Annotations: map[string]string{"node.alpha.kubernetes.io/ttl": "0"},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{Key: "node.kubernetes.io/not-ready", Effect: corev1.TaintEffectNoSchedule}},
+		},
+	}
+	coreV1Client := fakeclient.NewSimpleClientset(node).CoreV1()
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine-1",
+			Namespace: "default",
+		},
+		Spec: clusterv1.MachineSpec{
+			NodeLabels:      map[string]string{"node-role.kubernetes.io/worker": "", "pool": "gpu"},
+			NodeAnnotations: map[string]string{"example.com/owner": "team-a"},
+			NodeTaints:      []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Name: "node-1"},
+		},
+	}
+
+	g.Expect(r.updateNodeMetadata(context.Background(), coreV1Client, machine)).To(gomega.Succeed())
+
+	updated, err := coreV1Client.Nodes().Get("node-1", metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(updated.Labels).To(gomega.Equal(map[string]string{
+		"kubernetes.io/hostname":         "node-1",
+		"node-role.kubernetes.io/worker": "",
+		"pool":                           "gpu",
+	}))
+	g.Expect(updated.Annotations).To(gomega.Equal(map[string]string{
+		"node.alpha.kubernetes.io/ttl":             "0",
+		"example.com/owner":                        "team-a",
+		clusterv1.ManagedNodeLabelsAnnotation:      "node-role.kubernetes.io/worker,pool",
+		clusterv1.ManagedNodeAnnotationsAnnotation: "example.com/owner",
+		clusterv1.ManagedNodeTaintsAnnotation:      "dedicated:NoSchedule",
+	}))
+	g.Expect(updated.Spec.Taints).To(gomega.Equal([]corev1.Taint{
+		{Key: "node.kubernetes.io/not-ready", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+	}))
+
+	// Removing Node metadata from the Machine removes it from the Node, and leaves the rest untouched.
+	machine.Spec.NodeLabels = map[string]string{"pool": "cpu"}
+	machine.Spec.NodeAnnotations = nil
+	machine.Spec.NodeTaints = nil
+	g.Expect(r.updateNodeMetadata(context.Background(), coreV1Client, machine)).To(gomega.Succeed())
+
+	updated, err = coreV1Client.Nodes().Get("node-1", metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(updated.Labels).To(gomega.Equal(map[string]string{
+		"kubernetes.io/hostname": "node-1",
+		"pool":                   "cpu",
+	}))
+	g.Expect(updated.Annotations).To(gomega.Equal(map[string]string{
+		"node.alpha.kubernetes.io/ttl":        "0",
+		clusterv1.ManagedNodeLabelsAnnotation: "pool",
+	}))
+	g.Expect(updated.Spec.Taints).To(gomega.Equal([]corev1.Taint{
+		{Key: "node.kubernetes.io/not-ready", Effect: corev1.TaintEffectNoSchedule},
+	}))
+
+	// A Node already up to date isn't updated.
+	g.Expect(applyNodeMetadata(updated, &machine.Spec)).To(gomega.BeFalse())
+}
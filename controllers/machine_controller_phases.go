@@ -27,12 +27,14 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/klog"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	logutil "sigs.k8s.io/cluster-api/util/log"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -76,7 +78,7 @@ func (r *MachineReconciler) reconcilePhase(ctx context.Context, m *clusterv1.Mac
 
 // reconcileExternal handles generic unstructured objects referenced by a Machine.
 func (r *MachineReconciler) reconcileExternal(ctx context.Context, m *clusterv1.Machine, ref *corev1.ObjectReference) (*unstructured.Unstructured, error) {
-	obj, err := external.Get(r.Client, ref, m.Namespace)
+	obj, err := external.Get(ctx, r.Client, ref, m.Namespace)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil, errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: externalReadyWait},
@@ -96,11 +98,15 @@ func (r *MachineReconciler) reconcileExternal(ctx context.Context, m *clusterv1.
 		UID:        m.UID,
 	}
 
-	if !util.HasOwnerRef(obj.GetOwnerReferences(), machineOwnerRef) {
+	// Record the span of the Machine reconcile claiming the object, so that the reconciles
+	// of its provider can be linked to it.
+	traceInjected := tracing.Inject(ctx, obj)
+
+	if traceInjected || !util.HasOwnerRef(obj.GetOwnerReferences(), machineOwnerRef) {
 		obj.SetOwnerReferences(util.EnsureOwnerRef(obj.GetOwnerReferences(), machineOwnerRef))
 		if err := r.Client.Patch(ctx, obj, objPatch); err != nil {
 			return nil, errors.Wrapf(err,
-				"failed to set OwnerReference on %v %q for Machine %q in namespace %q",
+				"failed to set OwnerReference and trace context on %v %q for Machine %q in namespace %q",
 				obj.GroupVersionKind(), ref.Name, m.Name, m.Namespace)
 		}
 	}
@@ -108,7 +114,7 @@ func (r *MachineReconciler) reconcileExternal(ctx context.Context, m *clusterv1.
 	// Add watcher for external object, if there isn't one already.
 	_, loaded := r.externalWatchers.LoadOrStore(obj.GroupVersionKind().String(), struct{}{})
 	if !loaded && r.controller != nil {
-		klog.Infof("Adding watcher on external object %q", obj.GroupVersionKind())
+		logutil.FromContext(ctx).Info("Adding watcher on external object", "gvk", obj.GroupVersionKind().String())
 		err := r.controller.Watch(
 			&source.Kind{Type: obj},
 			&handler.EnqueueRequestForOwner{OwnerType: &clusterv1.Machine{}},
@@ -134,6 +140,10 @@ func (r *MachineReconciler) reconcileExternal(ctx context.Context, m *clusterv1.
 				obj.GroupVersionKind(), obj.GetName(), errorMessage),
 		)
 	}
+	if errorReason != "" || errorMessage != "" {
+		record.Warnf(m, record.ProviderFailedReason, "%s %q reported a failure: %s",
+			obj.GetKind(), obj.GetName(), strings.TrimSpace(errorReason+" "+errorMessage))
+	}
 
 	return obj, nil
 }
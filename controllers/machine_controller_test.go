@@ -497,13 +497,16 @@ func TestReconcileDeleteExternal(t *testing.T) {
 			clusterv1.AddToScheme(scheme.Scheme)
 
 			objs := []runtime.Object{machine}
+			var expectedEvents []string
 
 			if tc.bootstrapExists {
 				objs = append(objs, bootstrapConfig)
+				expectedEvents = append(expectedEvents, `Normal SuccessfulDelete Deleted BootstrapConfig "delete-bootstrap"`)
 			}
 
 			if tc.infraExists {
 				objs = append(objs, infraConfig)
+				expectedEvents = append(expectedEvents, `Normal SuccessfulDelete Deleted InfrastructureConfig "delete-infra"`)
 			}
 
 			r := &MachineReconciler{
@@ -511,6 +514,7 @@ func TestReconcileDeleteExternal(t *testing.T) {
 				Log:    log.Log,
 			}
 
+			testEvents.reset()
 			ok, err := r.reconcileDeleteExternal(ctx, machine)
 			Expect(ok).To(Equal(tc.expected))
 			if tc.expectError {
@@ -518,6 +522,7 @@ func TestReconcileDeleteExternal(t *testing.T) {
 			} else {
 				Expect(err).To(BeNil())
 			}
+			Expect(testEvents.list()).To(Equal(expectedEvents))
 		})
 	}
 }
@@ -18,9 +18,13 @@ package controllers
 
 import (
 	"context"
+	"strings"
 
 	"github.com/pkg/errors"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -47,3 +51,61 @@ func getActiveMachinesInCluster(ctx context.Context, c client.Client, namespace,
 	}
 	return machines, nil
 }
+
+// applyMachineTemplate sets the labels, annotations and Node metadata of the template on the Machine, and returns
+// true if the Machine changed. Labels and annotations removed from the template since they were last applied are
+// removed from the Machine, others set on the Machine are left untouched. Machines without the managed keys
+// annotations, created before they were recorded, only get them recorded: their labels and annotations from
+// an earlier template can't be told apart from the others.
+func applyMachineTemplate(machine *clusterv1.Machine, template *clusterv1.MachineTemplateSpec) bool {
+	original := machine.DeepCopy()
+	if machine.Labels == nil {
+		machine.Labels = map[string]string{}
+	}
+	if machine.Annotations == nil {
+		machine.Annotations = map[string]string{}
+	}
+
+	previousLabels := managedKeys(machine.Annotations, clusterv1.ManagedLabelsAnnotation)
+	previousAnnotations := managedKeys(machine.Annotations, clusterv1.ManagedAnnotationsAnnotation)
+	syncManagedMap(machine.Labels, template.Labels, previousLabels)
+	syncManagedMap(machine.Annotations, template.Annotations, previousAnnotations)
+	setManagedKeys(machine.Annotations, clusterv1.ManagedLabelsAnnotation, sets.StringKeySet(template.Labels))
+	setManagedKeys(machine.Annotations, clusterv1.ManagedAnnotationsAnnotation, sets.StringKeySet(template.Annotations))
+
+	mdutil.SyncNodeMetadata(&machine.Spec, &template.Spec)
+
+	return !apiequality.Semantic.DeepEqual(original, machine)
+}
+
+// syncManagedMap sets the desired entries in values, and removes the previously managed ones which aren't desired anymore.
+func syncManagedMap(values, desired map[string]string, previous sets.String) {
+	for key := range previous {
+		if _, ok := desired[key]; !ok {
+			delete(values, key)
+		}
+	}
+	for key, value := range desired {
+		values[key] = value
+	}
+}
+
+// managedKeys returns the keys recorded as comma separated values in the annotation.
+func managedKeys(annotations map[string]string, annotation string) sets.String {
+	keys := sets.NewString()
+	for _, key := range strings.Split(annotations[annotation], ",") {
+		if key != "" {
+			keys.Insert(key)
+		}
+	}
+	return keys
+}
+
+// setManagedKeys records the keys as comma separated values in the annotation, or removes it if there are none.
+func setManagedKeys(annotations map[string]string, annotation string, keys sets.String) {
+	if keys.Len() == 0 {
+		delete(annotations, annotation)
+		return
+	}
+	annotations[annotation] = strings.Join(keys.List(), ",")
+}
@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -121,3 +122,37 @@ func Test_getActiveMachinesInCluster(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyMachineTemplate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	template := &clusterv1.MachineTemplateSpec{
+		ObjectMeta: clusterv1.ObjectMeta{
+			Labels:      map[string]string{"pool": "gpu", "cost-center": "42"},
+			Annotations: map[string]string{"owner": "team-a"},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"set-by-user": "true"},
+		},
+	}
+
+	g.Expect(applyMachineTemplate(machine, template)).To(gomega.BeTrue())
+	g.Expect(machine.Labels).To(gomega.Equal(map[string]string{"pool": "gpu", "cost-center": "42", "set-by-user": "true"}))
+	g.Expect(machine.Annotations).To(gomega.Equal(map[string]string{
+		"owner":                                "team-a",
+		clusterv1.ManagedLabelsAnnotation:      "cost-center,pool",
+		clusterv1.ManagedAnnotationsAnnotation: "owner",
+	}))
+	g.Expect(applyMachineTemplate(machine, template)).To(gomega.BeFalse())
+
+	// Labels and annotations removed from the template are removed from the Machine.
+	template.Labels = map[string]string{"pool": "gpu"}
+	template.Annotations = nil
+	g.Expect(applyMachineTemplate(machine, template)).To(gomega.BeTrue())
+	g.Expect(machine.Labels).To(gomega.Equal(map[string]string{"pool": "gpu", "set-by-user": "true"}))
+	g.Expect(machine.Annotations).To(gomega.Equal(map[string]string{
+		clusterv1.ManagedLabelsAnnotation: "pool",
+	}))
+}
@@ -19,18 +19,22 @@ package controllers
 import (
 	"context"
 	"reflect"
+	"sync"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/tools/record"
-	"k8s.io/klog"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/util"
+	logutil "sigs.k8s.io/cluster-api/util/log"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -54,11 +58,13 @@ type MachineDeploymentReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
-	recorder record.EventRecorder
+	controller       controller.Controller
+	templateReader   client.Reader
+	templateWatchers sync.Map
 }
 
 func (r *MachineDeploymentReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
-	err := ctrl.NewControllerManagedBy(mgr).
+	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&clusterv1.MachineDeployment{}).
 		Owns(&clusterv1.MachineSet{}).
 		Watches(
@@ -66,15 +72,21 @@ func (r *MachineDeploymentReconciler) SetupWithManager(mgr ctrl.Manager, options
 			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.MachineSetToDeployments)},
 		).
 		WithOptions(options).
-		Complete(r)
+		Build(r)
 
-	r.recorder = mgr.GetEventRecorderFor("machinedeployment-controller")
+	r.controller = c
+	r.templateReader = mgr.GetCache()
 	return err
 }
 
 func (r *MachineDeploymentReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	ctx := context.Background()
-	_ = r.Log.WithValues("machinedeployment", req.NamespacedName)
+	log := r.Log.WithValues(logutil.NamespaceKey, req.Namespace, logutil.MachineDeploymentKey, req.Name)
+	ctx := logutil.IntoContext(context.Background(), log)
+	ctx, span := tracing.Start(ctx, "MachineDeploymentReconciler.Reconcile", logutil.NamespaceKey, req.Namespace, logutil.MachineDeploymentKey, req.Name)
+
+	// Errors are not returned to the manager, so the span records the error of reconcile itself.
+	var reconcileErr error
+	defer func() { tracing.End(ctx, span, reconcileErr) }()
 
 	// Fetch the MachineDeployment instance
 	d := &clusterv1.MachineDeployment{}
@@ -88,22 +100,26 @@ func (r *MachineDeploymentReconciler) Reconcile(req ctrl.Request) (ctrl.Result,
 		return ctrl.Result{}, err
 	}
 
+	log = log.WithValues(logutil.ClusterKey, d.Labels[clusterv1.MachineClusterLabelName])
+	ctx = logutil.IntoContext(ctx, log)
+
 	// Ignore deleted MachineDeployments, this can happen when foregroundDeletion
 	// is enabled
 	if d.DeletionTimestamp != nil {
 		return ctrl.Result{}, nil
 	}
 
-	result, err := r.reconcile(ctx, d)
-	if err != nil {
-		klog.Errorf("Failed to reconcile MachineDeployment %q: %v", req.NamespacedName, err)
-		r.recorder.Eventf(d, corev1.EventTypeWarning, "ReconcileError", "%v", err)
+	result, reconcileErr := r.reconcile(ctx, d)
+	if reconcileErr != nil {
+		log.Error(reconcileErr, "Failed to reconcile MachineDeployment")
 	}
 
 	return result, nil
 }
 
 func (r *MachineDeploymentReconciler) reconcile(ctx context.Context, d *clusterv1.MachineDeployment) (ctrl.Result, error) {
+	log := logutil.FromContext(ctx)
+
 	clusterv1.PopulateDefaultsMachineDeployment(d)
 
 	// Test for an empty LabelSelector and short circuit if that is the case
@@ -114,7 +130,7 @@ func (r *MachineDeploymentReconciler) reconcile(ctx context.Context, d *clusterv
 			patch := client.MergeFrom(d.DeepCopy())
 			d.Status.ObservedGeneration = d.Generation
 			if err := r.Client.Status().Patch(ctx, d, patch); err != nil {
-				klog.Warningf("Failed to patch status for MachineDeployment %q: %v", d.Name, err)
+				log.Error(err, "Failed to patch status")
 				return ctrl.Result{}, err
 			}
 		}
@@ -132,6 +148,12 @@ func (r *MachineDeploymentReconciler) reconcile(ctx context.Context, d *clusterv
 		return ctrl.Result{}, errors.Errorf("failed validation on MachineDeployment %q label selector, cannot match Machine template labels", d.Name)
 	}
 
+	// Malformed autoscaler annotations only affect the cluster autoscaler, so keep managing the Machines.
+	if err := validateAutoscalerAnnotations(d.Annotations); err != nil {
+		log.Info("Invalid autoscaler annotations", "error", err.Error())
+		record.Warnf(d, record.InvalidAutoscalerAnnotationsReason, "Invalid autoscaler annotations: %v", err)
+	}
+
 	// Copy label selector to its status counterpart in string format.
 	// This is necessary for CRDs including scale subresources.
 	d.Status.Selector = selector.String()
@@ -140,7 +162,7 @@ func (r *MachineDeploymentReconciler) reconcile(ctx context.Context, d *clusterv
 	// for machine management.
 	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, d.ObjectMeta)
 	if errors.Cause(err) == util.ErrNoCluster {
-		klog.V(2).Infof("MachineDeployment %q in namespace %q doesn't specify %q label, assuming nil Cluster", d.Name, d.Namespace, clusterv1.MachineClusterLabelName)
+		log.V(2).Info("MachineDeployment doesn't specify the cluster label, assuming nil Cluster", "label", clusterv1.MachineClusterLabelName)
 	} else if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -159,33 +181,58 @@ func (r *MachineDeploymentReconciler) reconcile(ctx context.Context, d *clusterv
 		}
 	}
 
-	msList, err := r.getMachineSetsForDeployment(d)
+	if err := r.reconcileCapacity(ctx, d); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	msList, err := r.getMachineSetsForDeployment(ctx, d)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	machineMap, err := r.getMachineMapForDeployment(d, msList)
+	machineMap, err := r.getMachineMapForDeployment(ctx, d, msList)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
 	if d.Spec.Paused {
-		return ctrl.Result{}, r.sync(d, msList, machineMap)
+		return ctrl.Result{}, r.sync(ctx, d, msList, machineMap)
 	}
 
 	switch d.Spec.Strategy.Type {
 	case clusterv1.RollingUpdateMachineDeploymentStrategyType:
-		return ctrl.Result{}, r.rolloutRolling(d, msList, machineMap)
+		return ctrl.Result{}, r.rolloutRolling(ctx, d, msList, machineMap)
 	}
 
 	return ctrl.Result{}, errors.Errorf("unexpected deployment strategy type: %s", d.Spec.Strategy.Type)
 }
 
+// reconcileCapacity records the capacity, labels and taints of the Nodes of the MachineDeployment in its annotations,
+// so that the cluster autoscaler can scale it up from zero replicas.
+func (r *MachineDeploymentReconciler) reconcileCapacity(ctx context.Context, d *clusterv1.MachineDeployment) error {
+	capacity, err := getTemplateCapacity(ctx, r.Client, &d.Spec.Template, d.Namespace)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(d.DeepCopy())
+	if !setCapacityAnnotations(d, capacity, &d.Spec.Template) {
+		return nil
+	}
+	// Patch using a deep copy to avoid overwriting any unexpected Status changes from the returned result
+	if err := r.Client.Patch(ctx, d.DeepCopy(), patch); err != nil {
+		return errors.Wrapf(err, "failed to set capacity annotations on MachineDeployment %s/%s", d.Namespace, d.Name)
+	}
+	return nil
+}
+
 // getMachineSetsForDeployment returns a list of MachineSets associated with a MachineDeployment.
-func (r *MachineDeploymentReconciler) getMachineSetsForDeployment(d *clusterv1.MachineDeployment) ([]*clusterv1.MachineSet, error) {
+func (r *MachineDeploymentReconciler) getMachineSetsForDeployment(ctx context.Context, d *clusterv1.MachineDeployment) ([]*clusterv1.MachineSet, error) {
+	log := logutil.FromContext(ctx)
+
 	// List all MachineSets to find those we own but that no longer match our selector.
 	machineSets := &clusterv1.MachineSetList{}
-	if err := r.Client.List(context.Background(), machineSets, client.InNamespace(d.Namespace)); err != nil {
+	if err := r.Client.List(ctx, machineSets, client.InNamespace(d.Namespace)); err != nil {
 		return nil, err
 	}
 
@@ -195,30 +242,30 @@ func (r *MachineDeploymentReconciler) getMachineSetsForDeployment(d *clusterv1.M
 
 		selector, err := metav1.LabelSelectorAsSelector(&d.Spec.Selector)
 		if err != nil {
-			klog.Errorf("Skipping MachineSet %q, failed to get label selector from spec selector: %v", ms.Name, err)
+			log.Error(err, "Skipping MachineSet, failed to get label selector from spec selector", logutil.MachineSetKey, ms.Name)
 			continue
 		}
 
 		// If a MachineDeployment with a nil or empty selector creeps in, it should match nothing, not everything.
 		if selector.Empty() {
-			klog.Warningf("Skipping MachineSet %q as the selector is empty", ms.Name)
+			log.Info("Skipping MachineSet as the selector is empty", logutil.MachineSetKey, ms.Name)
 			continue
 		}
 
 		// Skip this MachineSet unless either selector matches or it has a controller ref pointing to this MachineDeployment
 		if !selector.Matches(labels.Set(ms.Labels)) && !metav1.IsControlledBy(ms, d) {
-			klog.V(4).Infof("Skipping MachineSet %v, label mismatch", ms.Name)
+			log.V(4).Info("Skipping MachineSet, label mismatch", logutil.MachineSetKey, ms.Name)
 			continue
 		}
 
 		// Attempt to adopt machine if it meets previous conditions and it has no controller references.
 		if metav1.GetControllerOf(ms) == nil {
-			if err := r.adoptOrphan(d, ms); err != nil {
-				r.recorder.Eventf(d, corev1.EventTypeWarning, "FailedAdopt", "Failed to adopt MachineSet %q: %v", ms.Name, err)
-				klog.Warningf("Failed to adopt MachineSet %q into MachineDeployment %q: %v", ms.Name, d.Name, err)
+			if err := r.adoptOrphan(ctx, d, ms); err != nil {
+				record.Warnf(d, record.FailedAdoptReason, "Failed to adopt MachineSet %q: %v", ms.Name, err)
+				log.Error(err, "Failed to adopt MachineSet", logutil.MachineSetKey, ms.Name)
 				continue
 			}
-			r.recorder.Eventf(d, corev1.EventTypeNormal, "SuccessfulAdopt", "Adopted MachineSet %q", ms.Name)
+			record.Eventf(d, record.SuccessfulAdoptReason, "Adopted MachineSet %q", ms.Name)
 		}
 
 		if !metav1.IsControlledBy(ms, d) {
@@ -232,18 +279,18 @@ func (r *MachineDeploymentReconciler) getMachineSetsForDeployment(d *clusterv1.M
 }
 
 // adoptOrphan sets the MachineDeployment as a controller OwnerReference to the MachineSet.
-func (r *MachineDeploymentReconciler) adoptOrphan(deployment *clusterv1.MachineDeployment, machineSet *clusterv1.MachineSet) error {
+func (r *MachineDeploymentReconciler) adoptOrphan(ctx context.Context, deployment *clusterv1.MachineDeployment, machineSet *clusterv1.MachineSet) error {
 	patch := client.MergeFrom(machineSet.DeepCopy())
 	newRef := *metav1.NewControllerRef(deployment, machineDeploymentKind)
 	machineSet.OwnerReferences = append(machineSet.OwnerReferences, newRef)
-	return r.Client.Patch(context.Background(), machineSet, patch)
+	return r.Client.Patch(ctx, machineSet, patch)
 }
 
 // getMachineMapForDeployment returns the Machines managed by a Deployment.
 //
 // It returns a map from MachineSet UID to a list of Machines controlled by that MachineSet,
 // according to the Machine's ControllerRef.
-func (r *MachineDeploymentReconciler) getMachineMapForDeployment(d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) (map[types.UID]*clusterv1.MachineList, error) {
+func (r *MachineDeploymentReconciler) getMachineMapForDeployment(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) (map[types.UID]*clusterv1.MachineList, error) {
 	// TODO(droot): double check if previous selector maps correctly to new one.
 	// _, err := metav1.LabelSelectorAsSelector(&d.Spec.Selector)
 
@@ -254,7 +301,7 @@ func (r *MachineDeploymentReconciler) getMachineMapForDeployment(d *clusterv1.Ma
 	}
 
 	machines := &clusterv1.MachineList{}
-	if err = r.Client.List(context.Background(), machines, client.InNamespace(d.Namespace), client.MatchingLabels(selector)); err != nil {
+	if err = r.Client.List(ctx, machines, client.InNamespace(d.Namespace), client.MatchingLabels(selector)); err != nil {
 		return nil, err
 	}
 
@@ -284,15 +331,15 @@ func (r *MachineDeploymentReconciler) getMachineMapForDeployment(d *clusterv1.Ma
 }
 
 // getMachineDeploymentsForMachineSet returns a list of MachineDeployments that could potentially match a MachineSet.
-func (r *MachineDeploymentReconciler) getMachineDeploymentsForMachineSet(ms *clusterv1.MachineSet) []*clusterv1.MachineDeployment {
+func (r *MachineDeploymentReconciler) getMachineDeploymentsForMachineSet(log logr.Logger, ms *clusterv1.MachineSet) []*clusterv1.MachineDeployment {
 	if len(ms.Labels) == 0 {
-		klog.Warningf("No MachineDeployments found for MachineSet %q because it has no labels", ms.Name)
+		log.Info("No MachineDeployments found for MachineSet because it has no labels")
 		return nil
 	}
 
 	dList := &clusterv1.MachineDeploymentList{}
 	if err := r.Client.List(context.Background(), dList, client.InNamespace(ms.Namespace)); err != nil {
-		klog.Warningf("Failed to list MachineDeployments: %v", err)
+		log.Error(err, "Failed to list MachineDeployments")
 		return nil
 	}
 
@@ -321,7 +368,7 @@ func (r *MachineDeploymentReconciler) MachineSetToDeployments(o handler.MapObjec
 
 	ms, ok := o.Object.(*clusterv1.MachineSet)
 	if !ok {
-		klog.Errorf("Expected a MachineSet but got %T instead: %v", o.Object, o.Object)
+		r.Log.Error(errors.Errorf("expected a MachineSet but got a %T", o.Object), "Failed to map object to MachineDeployments")
 		return nil
 	}
 
@@ -333,9 +380,10 @@ func (r *MachineDeploymentReconciler) MachineSetToDeployments(o handler.MapObjec
 		}
 	}
 
-	mds := r.getMachineDeploymentsForMachineSet(ms)
+	log := r.Log.WithValues(logutil.NamespaceKey, ms.Namespace, logutil.MachineSetKey, ms.Name)
+	mds := r.getMachineDeploymentsForMachineSet(log, ms)
 	if len(mds) == 0 {
-		klog.V(4).Infof("Found no MachineDeployment for MachineSet: %v", ms.Name)
+		log.V(4).Info("Found no MachineDeployment for MachineSet")
 		return nil
 	}
 
@@ -347,6 +395,61 @@ func (r *MachineDeploymentReconciler) MachineSetToDeployments(o handler.MapObjec
 	return result
 }
 
+// TemplateToDeployments is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// for the MachineDeployments referencing an infrastructure or bootstrap template.
+func (r *MachineDeploymentReconciler) TemplateToDeployments(o handler.MapObject) []ctrl.Request {
+	gvk := o.Object.GetObjectKind().GroupVersionKind()
+	deployments := &clusterv1.MachineDeploymentList{}
+	if err := r.Client.List(context.Background(), deployments, client.InNamespace(o.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "Failed to list MachineDeployments", "kind", gvk.Kind, "name", o.Meta.GetName())
+		return nil
+	}
+
+	var result []ctrl.Request
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		for _, ref := range []*corev1.ObjectReference{&d.Spec.Template.Spec.InfrastructureRef, d.Spec.Template.Spec.Bootstrap.ConfigRef} {
+			if ref != nil && ref.Kind == gvk.Kind && ref.Name == o.Meta.GetName() && ref.GroupVersionKind().Group == gvk.Group {
+				result = append(result, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: d.Namespace, Name: d.Name}})
+				break
+			}
+		}
+	}
+	return result
+}
+
+// watchTemplate adds a watch on the kind of the template, if there isn't one already,
+// so that changes to the content of the templates trigger a rollout without waiting for a resync.
+func (r *MachineDeploymentReconciler) watchTemplate(ctx context.Context, ref *corev1.ObjectReference) error {
+	gvk := ref.GroupVersionKind()
+	if _, loaded := r.templateWatchers.LoadOrStore(gvk.String(), struct{}{}); loaded || r.controller == nil {
+		return nil
+	}
+
+	logutil.FromContext(ctx).Info("Adding watcher on template", "gvk", gvk.String())
+	template := &unstructured.Unstructured{}
+	template.SetGroupVersionKind(gvk)
+	if err := r.controller.Watch(
+		&source.Kind{Type: template},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.TemplateToDeployments)},
+	); err != nil {
+		r.templateWatchers.Delete(gvk.String())
+		return errors.Wrapf(err, "failed to add watcher on template %q", gvk)
+	}
+	return nil
+}
+
+// getTemplate returns the template, from the cache populated by the template watches once the controller runs.
+func (r *MachineDeploymentReconciler) getTemplate(ctx context.Context, ref *corev1.ObjectReference, namespace string) (*unstructured.Unstructured, error) {
+	if err := r.watchTemplate(ctx, ref); err != nil {
+		return nil, err
+	}
+	if r.templateReader == nil {
+		return external.Get(ctx, r.Client, ref, namespace)
+	}
+	return external.Get(ctx, r.templateReader, ref, namespace)
+}
+
 func (r *MachineDeploymentReconciler) shouldAdopt(md *clusterv1.MachineDeployment) bool {
 	return !util.HasOwner(md.OwnerReferences, clusterv1.GroupVersion.String(), []string{"Cluster"})
 }
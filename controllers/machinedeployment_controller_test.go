@@ -17,6 +17,7 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -26,9 +27,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -162,7 +164,7 @@ var _ = Describe("MachineDeployment Reconciler", func() {
 		//
 		secondMachineSet := machineSets.Items[0]
 		By("Scaling the MachineDeployment to 3 replicas")
-		err := updateMachineDeployment(k8sClient, deployment, func(d *clusterv1.MachineDeployment) { d.Spec.Replicas = pointer.Int32Ptr(3) })
+		err := updateMachineDeployment(ctx, k8sClient, deployment, func(d *clusterv1.MachineDeployment) { d.Spec.Replicas = pointer.Int32Ptr(3) })
 		Expect(err).ToNot(HaveOccurred())
 		Eventually(func() int {
 			key := client.ObjectKey{Name: secondMachineSet.Name, Namespace: secondMachineSet.Namespace}
@@ -173,10 +175,26 @@ var _ = Describe("MachineDeployment Reconciler", func() {
 		}, timeout).Should(BeEquivalentTo(3))
 
 		//
-		// Update a MachineDeployment, expect Reconcile to be called and a new MachineSet to appear.
+		// Set a label on the MachineDeployment template, expect it to be propagated to the existing MachineSet.
 		//
 		By("Setting a label on the MachineDeployment")
-		err = updateMachineDeployment(k8sClient, deployment, func(d *clusterv1.MachineDeployment) { d.Spec.Template.Labels["updated"] = "true" })
+		err = updateMachineDeployment(ctx, k8sClient, deployment, func(d *clusterv1.MachineDeployment) { d.Spec.Template.Labels["updated"] = "true" })
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(func() string {
+			key := client.ObjectKey{Name: secondMachineSet.Name, Namespace: secondMachineSet.Namespace}
+			if err := k8sClient.Get(ctx, key, &secondMachineSet); err != nil {
+				return ""
+			}
+			return secondMachineSet.Spec.Template.Labels["updated"]
+		}, timeout).Should(Equal("true"))
+		Expect(k8sClient.List(ctx, machineSets, msListOpts...)).To(Succeed())
+		Expect(machineSets.Items).To(HaveLen(1))
+
+		//
+		// Update the version of a MachineDeployment, expect Reconcile to be called and a new MachineSet to appear.
+		//
+		By("Updating the version of the MachineDeployment")
+		err = updateMachineDeployment(ctx, k8sClient, deployment, func(d *clusterv1.MachineDeployment) { d.Spec.Template.Spec.Version = pointer.StringPtr("1.10.4") })
 		Expect(err).ToNot(HaveOccurred())
 		Eventually(func() int {
 			if err := k8sClient.List(ctx, machineSets, msListOpts...); err != nil {
@@ -246,7 +264,7 @@ var _ = Describe("MachineDeployment Reconciler", func() {
 		}
 
 		By("Updating MachineDeployment label")
-		err = updateMachineDeployment(k8sClient, deployment, func(d *clusterv1.MachineDeployment) {
+		err = updateMachineDeployment(ctx, k8sClient, deployment, func(d *clusterv1.MachineDeployment) {
 			d.Spec.Selector.MatchLabels = newLabels
 			d.Spec.Template.Labels = newLabels
 		})
@@ -398,9 +416,8 @@ func TestMachineSetToDeployments(t *testing.T) {
 
 	clusterv1.AddToScheme(scheme.Scheme)
 	r := &MachineDeploymentReconciler{
-		Client:   fake.NewFakeClient(machineDeplopymentList),
-		Log:      log.Log,
-		recorder: record.NewFakeRecorder(32),
+		Client: fake.NewFakeClient(machineDeplopymentList),
+		Log:    log.Log,
 	}
 
 	for _, tc := range testsCases {
@@ -411,6 +428,83 @@ func TestMachineSetToDeployments(t *testing.T) {
 	}
 }
 
+func TestTemplateToDeployments(t *testing.T) {
+	deployment := func(name, namespace, infraName, bootstrapName string) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: clusterv1.MachineDeploymentSpec{
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						InfrastructureRef: corev1.ObjectReference{
+							APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+							Kind:       "InfrastructureMachineTemplate",
+							Name:       infraName,
+						},
+						Bootstrap: clusterv1.Bootstrap{
+							ConfigRef: &corev1.ObjectReference{
+								APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha3",
+								Kind:       "BootstrapConfigTemplate",
+								Name:       bootstrapName,
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	template := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		u.SetNamespace("test")
+		return u
+	}
+
+	clusterv1.AddToScheme(scheme.Scheme)
+	r := &MachineDeploymentReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme,
+			deployment("uses-infra", "test", "infra", "other"),
+			deployment("uses-bootstrap", "test", "other", "bootstrap"),
+			deployment("uses-neither", "test", "other", "other"),
+			deployment("other-namespace", "other", "infra", "bootstrap"),
+		),
+		Log: log.Log,
+	}
+
+	testCases := []struct {
+		name     string
+		template *unstructured.Unstructured
+		expected []reconcile.Request
+	}{
+		{
+			name:     "infrastructure template",
+			template: template("infrastructure.cluster.x-k8s.io/v1alpha3", "InfrastructureMachineTemplate", "infra"),
+			expected: []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: "test", Name: "uses-infra"}}},
+		},
+		{
+			name:     "bootstrap template",
+			template: template("bootstrap.cluster.x-k8s.io/v1alpha3", "BootstrapConfigTemplate", "bootstrap"),
+			expected: []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: "test", Name: "uses-bootstrap"}}},
+		},
+		{
+			name:     "template of another group",
+			template: template("other.x-k8s.io/v1alpha3", "InfrastructureMachineTemplate", "infra"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := r.TemplateToDeployments(handler.MapObject{Meta: tc.template, Object: tc.template})
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Got: %v, expected: %v", got, tc.expected)
+			}
+		})
+	}
+}
+
 func TestGetMachineDeploymentsForMachineSet(t *testing.T) {
 	machineDeployment := clusterv1.MachineDeployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -473,13 +567,12 @@ func TestGetMachineDeploymentsForMachineSet(t *testing.T) {
 	}
 	clusterv1.AddToScheme(scheme.Scheme)
 	r := &MachineDeploymentReconciler{
-		Client:   fake.NewFakeClient(&ms1, &ms2, machineDeplopymentList),
-		Log:      log.Log,
-		recorder: record.NewFakeRecorder(32),
+		Client: fake.NewFakeClient(&ms1, &ms2, machineDeplopymentList),
+		Log:    log.Log,
 	}
 
 	for _, tc := range testCases {
-		got := r.getMachineDeploymentsForMachineSet(&tc.machineSet)
+		got := r.getMachineDeploymentsForMachineSet(r.Log, &tc.machineSet)
 		if !reflect.DeepEqual(got, tc.expected) {
 			t.Errorf("Case %s. Got: %v, expected %v", tc.machineSet.Name, got, tc.expected)
 		}
@@ -635,12 +728,11 @@ func TestGetMachineSetsForDeployment(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			clusterv1.AddToScheme(scheme.Scheme)
 			r := &MachineDeploymentReconciler{
-				Client:   fake.NewFakeClient(machineSetList),
-				Log:      log.Log,
-				recorder: record.NewFakeRecorder(32),
+				Client: fake.NewFakeClient(machineSetList),
+				Log:    log.Log,
 			}
 
-			got, err := r.getMachineSetsForDeployment(&tc.machineDeployment)
+			got, err := r.getMachineSetsForDeployment(context.Background(), &tc.machineDeployment)
 			if err != nil {
 				t.Errorf("Failed running getMachineSetsForDeployment: %v", err)
 			}
@@ -657,3 +749,95 @@ func TestGetMachineSetsForDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAllMachineSetsDetectsTemplateChanges(t *testing.T) {
+	RegisterTestingT(t)
+
+	infraTmpl := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"size": "3xlarge",
+					},
+				},
+			},
+		},
+	}
+	infraTmpl.SetKind("InfrastructureMachineTemplate")
+	infraTmpl.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1alpha3")
+	infraTmpl.SetName("md-template")
+	infraTmpl.SetNamespace("default")
+
+	deployment := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "md",
+			Namespace: "default",
+			UID:       "md-uid",
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			MinReadySeconds: pointer.Int32Ptr(0),
+			Replicas:        pointer.Int32Ptr(1),
+			Selector:        metav1.LabelSelector{MatchLabels: map[string]string{"pool": "a"}},
+			Strategy: &clusterv1.MachineDeploymentStrategy{
+				Type: clusterv1.RollingUpdateMachineDeploymentStrategyType,
+				RollingUpdate: &clusterv1.MachineRollingUpdateDeployment{
+					MaxUnavailable: intOrStrPtr(0),
+					MaxSurge:       intOrStrPtr(1),
+				},
+			},
+			Template: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{"pool": "a"},
+				},
+				Spec: clusterv1.MachineSpec{
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+						Kind:       "InfrastructureMachineTemplate",
+						Name:       "md-template",
+					},
+				},
+			},
+		},
+	}
+	ms := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "md-stale",
+			Namespace: "default",
+			UID:       "ms-uid",
+			Annotations: map[string]string{
+				mdutil.RevisionAnnotation:                   "1",
+				mdutil.InfrastructureTemplateHashAnnotation: "stale",
+			},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, machineDeploymentKind)},
+		},
+		Spec: clusterv1.MachineSetSpec{
+			Replicas: pointer.Int32Ptr(1),
+			Selector: deployment.Spec.Selector,
+			Template: deployment.Spec.Template,
+		},
+	}
+
+	r := &MachineDeploymentReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, deployment, ms, infraTmpl),
+		Log:    log.Log,
+	}
+
+	// The machine set created from a previous content of the infrastructure template is an old one.
+	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(context.Background(), deployment, []*clusterv1.MachineSet{ms}, nil, false)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(newMS).To(BeNil())
+	Expect(oldMSs).To(Equal([]*clusterv1.MachineSet{ms}))
+
+	// A new machine set is rolled out, recording the current hash of the template.
+	newMS, oldMSs, err = r.getAllMachineSetsAndSyncRevision(context.Background(), deployment, []*clusterv1.MachineSet{ms}, nil, true)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(newMS).NotTo(BeNil())
+	Expect(newMS.Name).NotTo(Equal(ms.Name))
+	Expect(oldMSs).To(Equal([]*clusterv1.MachineSet{ms}))
+
+	hash, err := external.TemplateHash(infraTmpl)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(newMS.Annotations).To(HaveKeyWithValue(mdutil.InfrastructureTemplateHashAnnotation, hash))
+	Expect(newMS.Annotations).To(HaveKeyWithValue(mdutil.RevisionAnnotation, "2"))
+}
@@ -32,21 +32,23 @@ import (
 	apirand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/retry"
-	"k8s.io/klog"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	logutil "sigs.k8s.io/cluster-api/util/log"
+	"sigs.k8s.io/cluster-api/util/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // sync is responsible for reconciling deployments on scaling events or when they
 // are paused.
-func (r *MachineDeploymentReconciler) sync(d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet, machineMap map[types.UID]*clusterv1.MachineList) error {
-	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(d, msList, machineMap, false)
+func (r *MachineDeploymentReconciler) sync(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet, machineMap map[types.UID]*clusterv1.MachineList) error {
+	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(ctx, d, msList, machineMap, false)
 	if err != nil {
 		return err
 	}
 
-	if err := r.scale(d, newMS, oldMSs); err != nil {
+	if err := r.scale(ctx, d, newMS, oldMSs); err != nil {
 		// If we get an error while trying to scale, the deployment will be requeued
 		// so we can abort this resync
 		return err
@@ -56,7 +58,7 @@ func (r *MachineDeploymentReconciler) sync(d *clusterv1.MachineDeployment, msLis
 	// // TODO: Clean up the deployment when it's paused and no rollback is in flight.
 	//
 	allMSs := append(oldMSs, newMS)
-	return r.syncDeploymentStatus(allMSs, newMS, d)
+	return r.syncDeploymentStatus(ctx, allMSs, newMS, d)
 }
 
 // getAllMachineSetsAndSyncRevision returns all the machine sets for the provided deployment (new and all old), with new MS's and deployment's revision updated.
@@ -71,11 +73,24 @@ func (r *MachineDeploymentReconciler) sync(d *clusterv1.MachineDeployment, msLis
 //
 // Note that currently the deployment controller is using caches to avoid querying the server for reads.
 // This may lead to stale reads of machine sets, thus incorrect deployment status.
-func (r *MachineDeploymentReconciler) getAllMachineSetsAndSyncRevision(d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet, machineMap map[types.UID]*clusterv1.MachineList, createIfNotExisted bool) (*clusterv1.MachineSet, []*clusterv1.MachineSet, error) {
-	_, allOldMSs := mdutil.FindOldMachineSets(d, msList)
+func (r *MachineDeploymentReconciler) getAllMachineSetsAndSyncRevision(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet, machineMap map[types.UID]*clusterv1.MachineList, createIfNotExisted bool) (*clusterv1.MachineSet, []*clusterv1.MachineSet, error) {
+	templateHashes, err := r.getTemplateHashes(ctx, d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Machine sets created from a previous content of the referenced templates are old, even if their machine template
+	// is the same as the deployment's.
+	currentMSs := mdutil.FilterMachineSets(msList, func(ms *clusterv1.MachineSet) bool {
+		return mdutil.EqualTemplateHashes(ms, templateHashes)
+	})
+	existingNewMS := mdutil.FindNewMachineSet(d, currentMSs)
+	allOldMSs := mdutil.FilterMachineSets(msList, func(ms *clusterv1.MachineSet) bool {
+		return existingNewMS == nil || ms.UID != existingNewMS.UID
+	})
 
 	// Get new machine set with the updated revision number
-	newMS, err := r.getNewMachineSet(d, msList, allOldMSs, createIfNotExisted)
+	newMS, err := r.getNewMachineSet(ctx, d, existingNewMS, allOldMSs, templateHashes, createIfNotExisted)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -83,16 +98,45 @@ func (r *MachineDeploymentReconciler) getAllMachineSetsAndSyncRevision(d *cluste
 	return newMS, allOldMSs, nil
 }
 
+// getTemplateHashes returns the hashes of the content of the infrastructure and bootstrap templates referenced
+// by the machine template of the deployment, keyed by the annotations recording them in its machine sets.
+// Templates which don't exist are skipped, the creation of machines from them fails anyway.
+func (r *MachineDeploymentReconciler) getTemplateHashes(ctx context.Context, d *clusterv1.MachineDeployment) (map[string]string, error) {
+	refs := map[string]*corev1.ObjectReference{
+		mdutil.InfrastructureTemplateHashAnnotation: &d.Spec.Template.Spec.InfrastructureRef,
+		mdutil.BootstrapTemplateHashAnnotation:      d.Spec.Template.Spec.Bootstrap.ConfigRef,
+	}
+
+	templateHashes := map[string]string{}
+	for annotation, ref := range refs {
+		if ref == nil || ref.Name == "" {
+			continue
+		}
+		template, err := r.getTemplate(ctx, ref, d.Namespace)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to get %s %q referenced by MachineDeployment %q", ref.Kind, ref.Name, d.Name)
+		}
+		hash, err := external.TemplateHash(template)
+		if err != nil {
+			return nil, err
+		}
+		templateHashes[annotation] = hash
+	}
+	return templateHashes, nil
+}
+
 // Returns a machine set that matches the intent of the given deployment. Returns nil if the new machine set doesn't exist yet.
 // 1. Get existing new MS (the MS that the given deployment targets, whose machine template is the same as deployment's).
 // 2. If there's existing new MS, update its revision number if it's smaller than (maxOldRevision + 1), where maxOldRevision is the max revision number among all old MSes.
 // 3. If there's no existing new MS and createIfNotExisted is true, create one with appropriate revision number (maxOldRevision + 1) and replicas.
 // Note that the machine-template-hash will be added to adopted MSes and machines.
-func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeployment, msList, oldMSs []*clusterv1.MachineSet, createIfNotExisted bool) (*clusterv1.MachineSet, error) {
-	existingNewMS := mdutil.FindNewMachineSet(d, msList)
+func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *clusterv1.MachineDeployment, existingNewMS *clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet, templateHashes map[string]string, createIfNotExisted bool) (*clusterv1.MachineSet, error) {
+	log := logutil.FromContext(ctx)
 
 	// Calculate the max revision number among all old MSes
-	maxOldRevision := mdutil.MaxRevision(oldMSs)
+	maxOldRevision := mdutil.MaxRevision(oldMSs, log)
 
 	// Calculate revision number for this new machine set
 	newRevision := strconv.FormatInt(maxOldRevision+1, 10)
@@ -106,16 +150,23 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 		patch := client.MergeFrom(msCopy.DeepCopy())
 
 		// Set existing new machine set's annotation
-		annotationsUpdated := mdutil.SetNewMachineSetAnnotations(d, msCopy, newRevision, true)
+		annotationsUpdated := mdutil.SetNewMachineSetAnnotations(d, msCopy, newRevision, true, log)
+
+		// Propagate the changes of the deployment template which don't need new machines, such as
+		// labels and annotations, to the template of the existing new machine set.
+		templateUpdated := mdutil.SyncMachineTemplate(&msCopy.Spec.Template, &d.Spec.Template)
+
+		// Record the hashes of the referenced templates missing from machine sets created before they were.
+		templateHashesUpdated := mdutil.SetTemplateHashes(msCopy, templateHashes)
 
 		minReadySecondsNeedsUpdate := msCopy.Spec.MinReadySeconds != *d.Spec.MinReadySeconds
-		if annotationsUpdated || minReadySecondsNeedsUpdate {
+		if annotationsUpdated || templateUpdated || templateHashesUpdated || minReadySecondsNeedsUpdate {
 			msCopy.Spec.MinReadySeconds = *d.Spec.MinReadySeconds
-			return nil, r.Client.Patch(context.Background(), msCopy, patch)
+			return nil, r.Client.Patch(ctx, msCopy, patch)
 		}
 
 		// Apply revision annotation from existingNewMS if it is missing from the deployment.
-		err := r.updateMachineDeployment(d, func(innerDeployment *clusterv1.MachineDeployment) {
+		err := r.updateMachineDeployment(ctx, d, func(innerDeployment *clusterv1.MachineDeployment) {
 			mdutil.SetDeploymentRevision(d, msCopy.Annotations[mdutil.RevisionAnnotation])
 		})
 		return msCopy, err
@@ -127,7 +178,7 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 
 	// new MachineSet does not exist, create one.
 	newMSTemplate := *d.Spec.Template.DeepCopy()
-	machineTemplateSpecHash := fmt.Sprintf("%d", mdutil.ComputeHash(&newMSTemplate))
+	machineTemplateSpecHash := fmt.Sprintf("%d", mdutil.ComputeHashWithTemplates(&newMSTemplate, templateHashes))
 	newMSTemplate.Labels = mdutil.CloneAndAddLabel(d.Spec.Template.Labels,
 		mdutil.DefaultMachineDeploymentUniqueLabelKey, machineTemplateSpecHash)
 
@@ -171,12 +222,13 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 	*(newMS.Spec.Replicas) = newReplicasCount
 
 	// Set new machine set's annotation
-	mdutil.SetNewMachineSetAnnotations(d, &newMS, newRevision, false)
+	mdutil.SetNewMachineSetAnnotations(d, &newMS, newRevision, false, log)
+	mdutil.SetTemplateHashes(&newMS, templateHashes)
 	// Create the new MachineSet. If it already exists, then we need to check for possible
 	// hash collisions. If there is any other error, we need to report it in the status of
 	// the Deployment.
 	alreadyExists := false
-	err = r.Client.Create(context.Background(), &newMS)
+	err = r.Client.Create(ctx, &newMS)
 	createdMS := &newMS
 	switch {
 	// We may end up hitting this due to a slow cache or a fast resync of the Deployment.
@@ -184,7 +236,7 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 		alreadyExists = true
 
 		ms := &clusterv1.MachineSet{}
-		msErr := r.Client.Get(context.Background(), client.ObjectKey{Namespace: newMS.Namespace, Name: newMS.Name}, ms)
+		msErr := r.Client.Get(ctx, client.ObjectKey{Namespace: newMS.Namespace, Name: newMS.Name}, ms)
 		if msErr != nil {
 			return nil, msErr
 		}
@@ -194,24 +246,25 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 		// Otherwise, this is a hash collision and we need to increment the collisionCount field in
 		// the status of the Deployment and requeue to try the creation in the next sync.
 		controllerRef := metav1.GetControllerOf(ms)
-		if controllerRef != nil && controllerRef.UID == d.UID && mdutil.EqualIgnoreHash(&d.Spec.Template, &ms.Spec.Template) {
+		if controllerRef != nil && controllerRef.UID == d.UID && mdutil.EqualIgnoreHash(&d.Spec.Template, &ms.Spec.Template) &&
+			mdutil.EqualTemplateHashes(ms, templateHashes) {
 			createdMS = ms
 			break
 		}
 
 		return nil, err
 	case err != nil:
-		klog.V(4).Infof("Failed to create new machine set %q: %v", newMS.Name, err)
-		r.recorder.Eventf(d, corev1.EventTypeWarning, "FailedCreate", "Failed to create MachineSet %q: %v", newMS.Name, err)
+		log.Error(err, "Failed to create new machine set", logutil.MachineSetKey, newMS.Name)
+		record.Warnf(d, record.FailedCreateReason, "Failed to create MachineSet %q: %v", newMS.Name, err)
 		return nil, err
 	}
 
 	if !alreadyExists {
-		klog.V(4).Infof("Created new machine set %q", createdMS.Name)
-		r.recorder.Eventf(d, corev1.EventTypeNormal, "SuccessfulCreate", "Created MachineSet %q", newMS.Name)
+		log.V(4).Info("Created new machine set", logutil.MachineSetKey, createdMS.Name)
+		record.Eventf(d, record.SuccessfulCreateReason, "Created MachineSet %q", newMS.Name)
 	}
 
-	err = r.updateMachineDeployment(d, func(innerDeployment *clusterv1.MachineDeployment) {
+	err = r.updateMachineDeployment(ctx, d, func(innerDeployment *clusterv1.MachineDeployment) {
 		mdutil.SetDeploymentRevision(d, newRevision)
 	})
 
@@ -223,7 +276,7 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 // have the effect of hastening the rollout progress, which could produce a higher proportion of unavailable
 // replicas in the event of a problem with the rolled out template. Should run only on scaling events or
 // when a deployment is paused and not during the normal rollout process.
-func (r *MachineDeploymentReconciler) scale(deployment *clusterv1.MachineDeployment, newMS *clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet) error {
+func (r *MachineDeploymentReconciler) scale(ctx context.Context, deployment *clusterv1.MachineDeployment, newMS *clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet) error {
 	if deployment.Spec.Replicas == nil {
 		return errors.Errorf("spec replicas for deployment %v is nil, this is unexpected", deployment.Name)
 	}
@@ -239,7 +292,7 @@ func (r *MachineDeploymentReconciler) scale(deployment *clusterv1.MachineDeploym
 			return nil
 		}
 
-		_, err := r.scaleMachineSet(activeOrLatest, *(deployment.Spec.Replicas), deployment)
+		_, err := r.scaleMachineSet(ctx, activeOrLatest, *(deployment.Spec.Replicas), deployment)
 		return err
 	}
 
@@ -247,7 +300,7 @@ func (r *MachineDeploymentReconciler) scale(deployment *clusterv1.MachineDeploym
 	// This case handles machine set adoption during a saturated new machine set.
 	if mdutil.IsSaturated(deployment, newMS) {
 		for _, old := range mdutil.FilterActiveMachineSets(oldMSs) {
-			if _, err := r.scaleMachineSet(old, 0, deployment); err != nil {
+			if _, err := r.scaleMachineSet(ctx, old, 0, deployment); err != nil {
 				return err
 			}
 		}
@@ -294,14 +347,15 @@ func (r *MachineDeploymentReconciler) scale(deployment *clusterv1.MachineDeploym
 		for i := range allMSs {
 			ms := allMSs[i]
 			if ms.Spec.Replicas == nil {
-				klog.Errorf("spec replicas for machine set %v is nil, this is unexpected.", ms.Name)
+				logutil.FromContext(ctx).Error(errors.Errorf("spec replicas for machine set %v is nil, this is unexpected", ms.Name),
+					"Skipping MachineSet", logutil.MachineSetKey, ms.Name)
 				continue
 			}
 
 			// Estimate proportions if we have replicas to add, otherwise simply populate
 			// nameToSize with the current sizes for each machine set.
 			if deploymentReplicasToAdd != 0 {
-				proportion := mdutil.GetProportion(ms, *deployment, deploymentReplicasToAdd, deploymentReplicasAdded)
+				proportion := mdutil.GetProportion(ms, *deployment, deploymentReplicasToAdd, deploymentReplicasAdded, logutil.FromContext(ctx))
 				nameToSize[ms.Name] = *(ms.Spec.Replicas) + proportion
 				deploymentReplicasAdded += proportion
 			} else {
@@ -323,7 +377,7 @@ func (r *MachineDeploymentReconciler) scale(deployment *clusterv1.MachineDeploym
 			}
 
 			// TODO: Use transactions when we have them.
-			if _, err := r.scaleMachineSetOperation(ms, nameToSize[ms.Name], deployment, scalingOperation); err != nil {
+			if _, err := r.scaleMachineSetOperation(ctx, ms, nameToSize[ms.Name], deployment, scalingOperation); err != nil {
 				// Return as soon as we fail, the deployment is requeued
 				return err
 			}
@@ -334,7 +388,7 @@ func (r *MachineDeploymentReconciler) scale(deployment *clusterv1.MachineDeploym
 }
 
 // syncDeploymentStatus checks if the status is up-to-date and sync it if necessary
-func (r *MachineDeploymentReconciler) syncDeploymentStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, d *clusterv1.MachineDeployment) error {
+func (r *MachineDeploymentReconciler) syncDeploymentStatus(ctx context.Context, allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, d *clusterv1.MachineDeployment) error {
 	newStatus := calculateStatus(allMSs, newMS, d)
 	if reflect.DeepEqual(d.Status, newStatus) {
 		return nil
@@ -343,7 +397,7 @@ func (r *MachineDeploymentReconciler) syncDeploymentStatus(allMSs []*clusterv1.M
 	patch := client.MergeFrom(d.DeepCopy())
 	d.Status = newStatus
 	// Patch using a deep copy to avoid overwriting any unexpected Spec/Metadata changes from the returned result
-	return r.Client.Status().Patch(context.Background(), d.DeepCopy(), patch)
+	return r.Client.Status().Patch(ctx, d.DeepCopy(), patch)
 }
 
 // calculateStatus calculates the latest status for the provided deployment by looking into the provided machine sets.
@@ -371,7 +425,7 @@ func calculateStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet
 	return status
 }
 
-func (r *MachineDeploymentReconciler) scaleMachineSet(ms *clusterv1.MachineSet, newScale int32, deployment *clusterv1.MachineDeployment) (bool, error) {
+func (r *MachineDeploymentReconciler) scaleMachineSet(ctx context.Context, ms *clusterv1.MachineSet, newScale int32, deployment *clusterv1.MachineDeployment) (bool, error) {
 	if ms.Spec.Replicas == nil {
 		return false, errors.Errorf("spec replicas for machine set %v is nil, this is unexpected", ms.Name)
 	}
@@ -388,10 +442,10 @@ func (r *MachineDeploymentReconciler) scaleMachineSet(ms *clusterv1.MachineSet,
 		scalingOperation = "down"
 	}
 
-	return r.scaleMachineSetOperation(ms, newScale, deployment, scalingOperation)
+	return r.scaleMachineSetOperation(ctx, ms, newScale, deployment, scalingOperation)
 }
 
-func (r *MachineDeploymentReconciler) scaleMachineSetOperation(ms *clusterv1.MachineSet, newScale int32, deployment *clusterv1.MachineDeployment, scaleOperation string) (bool, error) {
+func (r *MachineDeploymentReconciler) scaleMachineSetOperation(ctx context.Context, ms *clusterv1.MachineSet, newScale int32, deployment *clusterv1.MachineDeployment, scaleOperation string) (bool, error) {
 	if ms.Spec.Replicas == nil {
 		return false, errors.Errorf("spec replicas for machine set %v is nil, this is unexpected", ms.Name)
 	}
@@ -415,12 +469,12 @@ func (r *MachineDeploymentReconciler) scaleMachineSetOperation(ms *clusterv1.Mac
 		*(ms.Spec.Replicas) = newScale
 		mdutil.SetReplicasAnnotations(ms, *(deployment.Spec.Replicas), *(deployment.Spec.Replicas)+mdutil.MaxSurge(*deployment))
 
-		err = r.Client.Patch(context.Background(), ms, patch)
+		err = r.Client.Patch(ctx, ms, patch)
 		if err != nil {
-			r.recorder.Eventf(deployment, corev1.EventTypeWarning, "FailedScale", "Failed to scale MachineSet %q: %v", ms.Name, err)
+			record.Warnf(deployment, record.FailedScaleReason, "Failed to scale MachineSet %q: %v", ms.Name, err)
 		} else if sizeNeedsUpdate {
 			scaled = true
-			r.recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulScale", "Scaled %s MachineSet %q to %d", scaleOperation, ms.Name, newScale)
+			record.Eventf(deployment, record.SuccessfulScaleReason, "Scaled %s MachineSet %q to %d", scaleOperation, ms.Name, newScale)
 		}
 	}
 
@@ -430,7 +484,7 @@ func (r *MachineDeploymentReconciler) scaleMachineSetOperation(ms *clusterv1.Mac
 // cleanupDeployment is responsible for cleaning up a deployment i.e. retains all but the latest N old machine sets
 // where N=d.Spec.RevisionHistoryLimit. Old machine sets are older versions of the machinetemplate of a deployment kept
 // around by default 1) for historical reasons and 2) for the ability to rollback a deployment.
-func (r *MachineDeploymentReconciler) cleanupDeployment(oldMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) error {
+func (r *MachineDeploymentReconciler) cleanupDeployment(ctx context.Context, oldMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) error {
 	if deployment.Spec.RevisionHistoryLimit == nil {
 		return nil
 	}
@@ -448,7 +502,8 @@ func (r *MachineDeploymentReconciler) cleanupDeployment(oldMSs []*clusterv1.Mach
 	}
 
 	sort.Sort(mdutil.MachineSetsByCreationTimestamp(cleanableMSes))
-	klog.V(4).Infof("Looking to cleanup old machine sets for deployment %q", deployment.Name)
+	log := logutil.FromContext(ctx)
+	log.V(4).Info("Looking to cleanup old machine sets")
 
 	for i := int32(0); i < diff; i++ {
 		ms := cleanableMSes[i]
@@ -461,25 +516,25 @@ func (r *MachineDeploymentReconciler) cleanupDeployment(oldMSs []*clusterv1.Mach
 			continue
 		}
 
-		klog.V(4).Infof("Trying to cleanup machine set %q for deployment %q", ms.Name, deployment.Name)
-		if err := r.Client.Delete(context.Background(), ms); err != nil && !apierrors.IsNotFound(err) {
+		log.V(4).Info("Trying to cleanup machine set", logutil.MachineSetKey, ms.Name)
+		if err := r.Client.Delete(ctx, ms); err != nil && !apierrors.IsNotFound(err) {
 			// Return error instead of aggregating and continuing DELETEs on the theory
 			// that we may be overloading the api server.
-			r.recorder.Eventf(deployment, corev1.EventTypeWarning, "FailedDelete", "Failed to delete MachineSet %q: %v", ms.Name, err)
+			record.Warnf(deployment, record.FailedDeleteReason, "Failed to delete MachineSet %q: %v", ms.Name, err)
 			return err
 		}
-		r.recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted MachineSet %q", ms.Name)
+		record.Eventf(deployment, record.SuccessfulDeleteReason, "Deleted MachineSet %q", ms.Name)
 	}
 
 	return nil
 }
 
-func (r *MachineDeploymentReconciler) updateMachineDeployment(d *clusterv1.MachineDeployment, modify func(*clusterv1.MachineDeployment)) error {
-	return updateMachineDeployment(r.Client, d, modify)
+func (r *MachineDeploymentReconciler) updateMachineDeployment(ctx context.Context, d *clusterv1.MachineDeployment, modify func(*clusterv1.MachineDeployment)) error {
+	return updateMachineDeployment(ctx, r.Client, d, modify)
 }
 
 // We have this as standalone variant to be able to use it from the tests
-func updateMachineDeployment(c client.Client, d *clusterv1.MachineDeployment, modify func(*clusterv1.MachineDeployment)) error {
+func updateMachineDeployment(ctx context.Context, c client.Client, d *clusterv1.MachineDeployment, modify func(*clusterv1.MachineDeployment)) error {
 	dCopy := d.DeepCopy()
 	modify(dCopy)
 	if equality.Semantic.DeepEqual(dCopy, d) {
@@ -487,7 +542,7 @@ func updateMachineDeployment(c client.Client, d *clusterv1.MachineDeployment, mo
 	}
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		// Get latest version.
-		if err := c.Get(context.Background(), types.NamespacedName{Namespace: d.Namespace, Name: d.Name}, d); err != nil {
+		if err := c.Get(ctx, types.NamespacedName{Namespace: d.Namespace, Name: d.Name}, d); err != nil {
 			return err
 		}
 		// Save patch.
@@ -497,6 +552,6 @@ func updateMachineDeployment(c client.Client, d *clusterv1.MachineDeployment, mo
 		// Apply modifications.
 		modify(d)
 		// Patch using a deep copy to avoid overwriting any unexpected Spec/Metadata changes from the returned result
-		return c.Patch(context.Background(), d.DeepCopy(), patch)
+		return c.Patch(ctx, d.DeepCopy(), patch)
 	})
 }
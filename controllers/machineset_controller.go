@@ -30,11 +30,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/client-go/tools/record"
-	"k8s.io/klog"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/util"
+	logutil "sigs.k8s.io/cluster-api/util/log"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -64,8 +66,6 @@ var (
 type MachineSetReconciler struct {
 	Client client.Client
 	Log    logr.Logger
-
-	recorder record.EventRecorder
 }
 
 func (r *MachineSetReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -79,13 +79,14 @@ func (r *MachineSetReconciler) SetupWithManager(mgr ctrl.Manager, options contro
 		WithOptions(options).
 		Complete(r)
 
-	r.recorder = mgr.GetEventRecorderFor("machineset-controller")
 	return err
 }
 
-func (r *MachineSetReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	ctx := context.Background()
-	_ = r.Log.WithValues("machineset", req.NamespacedName)
+func (r *MachineSetReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
+	log := r.Log.WithValues(logutil.NamespaceKey, req.Namespace, logutil.MachineSetKey, req.Name)
+	ctx := logutil.IntoContext(context.Background(), log)
+	ctx, span := tracing.Start(ctx, "MachineSetReconciler.Reconcile", logutil.NamespaceKey, req.Namespace, logutil.MachineSetKey, req.Name)
+	defer func() { tracing.End(ctx, span, reterr) }()
 
 	machineSet := &clusterv1.MachineSet{}
 	if err := r.Client.Get(ctx, req.NamespacedName, machineSet); err != nil {
@@ -98,6 +99,9 @@ func (r *MachineSetReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 		return ctrl.Result{}, err
 	}
 
+	log = log.WithValues(logutil.ClusterKey, machineSet.Labels[clusterv1.MachineClusterLabelName])
+	ctx = logutil.IntoContext(ctx, log)
+
 	// Ignore deleted MachineSets, this can happen when foregroundDeletion
 	// is enabled
 	if machineSet.DeletionTimestamp != nil {
@@ -106,14 +110,33 @@ func (r *MachineSetReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 
 	result, err := r.reconcile(ctx, machineSet)
 	if err != nil {
-		klog.Errorf("Failed to reconcile MachineSet %q: %v", req.NamespacedName, err)
-		r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "ReconcileError", "%v", err)
+		log.Error(err, "Failed to reconcile MachineSet")
 	}
 	return result, err
 }
 
+// reconcileCapacity records the capacity, labels and taints of the Nodes of the MachineSet in its annotations,
+// so that the cluster autoscaler can scale it up from zero replicas.
+func (r *MachineSetReconciler) reconcileCapacity(ctx context.Context, ms *clusterv1.MachineSet) error {
+	capacity, err := getTemplateCapacity(ctx, r.Client, &ms.Spec.Template, ms.Namespace)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(ms.DeepCopy())
+	if !setCapacityAnnotations(ms, capacity, &ms.Spec.Template) {
+		return nil
+	}
+	// Patch using a deep copy to avoid overwriting any unexpected Status changes from the returned result
+	if err := r.Client.Patch(ctx, ms.DeepCopy(), patch); err != nil {
+		return errors.Wrapf(err, "failed to set capacity annotations on MachineSet %s/%s", ms.Namespace, ms.Name)
+	}
+	return nil
+}
+
 func (r *MachineSetReconciler) reconcile(ctx context.Context, machineSet *clusterv1.MachineSet) (ctrl.Result, error) {
-	klog.V(4).Infof("Reconcile MachineSet %q in namespace %q", machineSet.Name, machineSet.Namespace)
+	log := logutil.FromContext(ctx)
+	log.V(4).Info("Reconcile MachineSet")
 
 	// Make sure that label selector can match template's labels.
 	// TODO(vincepri): Move to a validation (admission) webhook when supported.
@@ -126,6 +149,12 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, machineSet *cluste
 		return ctrl.Result{}, errors.Errorf("failed validation on MachineSet %q label selector, cannot match any machines ", machineSet.Name)
 	}
 
+	// Malformed autoscaler annotations only affect the cluster autoscaler, so keep managing the Machines.
+	if err := validateAutoscalerAnnotations(machineSet.Annotations); err != nil {
+		log.Info("Invalid autoscaler annotations", "error", err.Error())
+		record.Warnf(machineSet, record.InvalidAutoscalerAnnotationsReason, "Invalid autoscaler annotations: %v", err)
+	}
+
 	selectorMap, err := metav1.LabelSelectorAsMap(&machineSet.Spec.Selector)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to convert MachineSet %q label selector to a map", machineSet.Name)
@@ -138,7 +167,7 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, machineSet *cluste
 	// Get all Machines linked to this MachineSet.
 	allMachines := &clusterv1.MachineList{}
 	err = r.Client.List(
-		context.Background(), allMachines,
+		ctx, allMachines,
 		client.InNamespace(machineSet.Namespace),
 		client.MatchingLabels(selectorMap),
 	)
@@ -150,7 +179,7 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, machineSet *cluste
 	// for machine management.
 	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, machineSet.ObjectMeta)
 	if errors.Cause(err) == util.ErrNoCluster {
-		klog.V(2).Infof("MachineSet %q in namespace %q doesn't specify %q label, assuming nil cluster", machineSet.Name, machineSet.Namespace, clusterv1.MachineClusterLabelName)
+		log.V(2).Info("MachineSet doesn't specify the cluster label, assuming nil cluster", "label", clusterv1.MachineClusterLabelName)
 	} else if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -174,35 +203,41 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, machineSet *cluste
 		}
 	}
 
+	if err := r.reconcileCapacity(ctx, machineSet); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Filter out irrelevant machines (deleting/mismatch labels) and claim orphaned machines.
 	filteredMachines := make([]*clusterv1.Machine, 0, len(allMachines.Items))
 	for idx := range allMachines.Items {
 		machine := &allMachines.Items[idx]
 		if shouldExcludeMachine(machineSet, machine) {
+			log.V(4).Info("Excluding Machine controlled by another owner or being deleted", logutil.MachineKey, machine.Name)
 			continue
 		}
 
 		// Attempt to adopt machine if it meets previous conditions and it has no controller references.
 		if metav1.GetControllerOf(machine) == nil {
-			if err := r.adoptOrphan(machineSet, machine); err != nil {
-				klog.Warningf("Failed to adopt Machine %q into MachineSet %q: %v", machine.Name, machineSet.Name, err)
-				r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "FailedAdopt", "Failed to adopt Machine %q: %v", machine.Name, err)
+			if err := r.adoptOrphan(ctx, machineSet, machine); err != nil {
+				log.Error(err, "Failed to adopt Machine", logutil.MachineKey, machine.Name)
+				record.Warnf(machineSet, record.FailedAdoptReason, "Failed to adopt Machine %q: %v", machine.Name, err)
 				continue
 			}
-			klog.Infof("Adopted Machine %q into MachineSet %q", machine.Name, machineSet.Name)
-			r.recorder.Eventf(machineSet, corev1.EventTypeNormal, "SuccessfulAdopt", "Adopted Machine %q", machine.Name)
+			log.Info("Adopted Machine", logutil.MachineKey, machine.Name)
+			record.Eventf(machineSet, record.SuccessfulAdoptReason, "Adopted Machine %q", machine.Name)
 		}
 
 		filteredMachines = append(filteredMachines, machine)
 	}
 
-	syncErr := r.syncReplicas(machineSet, filteredMachines)
+	syncMachinesErr := r.syncMachines(ctx, machineSet, filteredMachines)
+	syncErr := r.syncReplicas(ctx, machineSet, filteredMachines)
 
 	ms := machineSet.DeepCopy()
-	newStatus := r.calculateStatus(ms, filteredMachines)
+	newStatus := r.calculateStatus(ctx, ms, filteredMachines)
 
 	// Always updates status as machines come up or die.
-	updatedMS, err := updateMachineSetStatus(r.Client, machineSet, newStatus)
+	updatedMS, err := updateMachineSetStatus(ctx, r.Client, machineSet, newStatus)
 	if err != nil {
 		if syncErr != nil {
 			return ctrl.Result{}, errors.Wrapf(err, "failed to sync machines: %v. failed to update machine set status", syncErr)
@@ -214,6 +249,10 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, machineSet *cluste
 		return ctrl.Result{}, errors.Wrapf(syncErr, "failed to sync Machineset replicas")
 	}
 
+	if syncMachinesErr != nil {
+		return ctrl.Result{}, syncMachinesErr
+	}
+
 	var replicas int32
 	if updatedMS.Spec.Replicas != nil {
 		replicas = *updatedMS.Spec.Replicas
@@ -235,15 +274,35 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, machineSet *cluste
 
 	// Quickly rereconcile until the nodes become Ready.
 	if updatedMS.Status.ReadyReplicas != replicas {
-		klog.V(4).Info("Some nodes are not ready yet, requeuing until they are ready")
+		log.V(4).Info("Some nodes are not ready yet, requeuing until they are ready")
 		return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// syncMachines propagates the labels, annotations and Node metadata of the MachineSet template
+// to its existing Machines, which don't need to be replaced when these change.
+func (r *MachineSetReconciler) syncMachines(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
+	var errs []error
+	for _, machine := range machines {
+		patch := client.MergeFrom(machine.DeepCopy())
+		if !applyMachineTemplate(machine, &ms.Spec.Template) {
+			continue
+		}
+		if err := r.Client.Patch(ctx, machine, patch); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to update Machine %q from the MachineSet template", machine.Name))
+			continue
+		}
+		logutil.FromContext(ctx).V(2).Info("Updated Machine from the MachineSet template", logutil.MachineKey, machine.Name)
+	}
+	return kerrors.NewAggregate(errs)
+}
+
 // syncReplicas scales Machine resources up or down.
-func (r *MachineSetReconciler) syncReplicas(ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
+func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
+	log := logutil.FromContext(ctx)
+
 	if ms.Spec.Replicas == nil {
 		return errors.Errorf("the Replicas field in Spec for machineset %v is nil, this should not be allowed", ms.Name)
 	}
@@ -252,14 +311,13 @@ func (r *MachineSetReconciler) syncReplicas(ms *clusterv1.MachineSet, machines [
 
 	if diff < 0 {
 		diff *= -1
-		klog.Infof("Too few replicas for %v %s/%s, need %d, creating %d",
-			machineSetKind, ms.Namespace, ms.Name, *(ms.Spec.Replicas), diff)
+		log.Info("Too few replicas", "need", *(ms.Spec.Replicas), "creating", diff)
 
 		var machineList []*clusterv1.Machine
 		var errstrings []string
 		for i := 0; i < diff; i++ {
-			klog.Infof("Creating machine %d of %d, ( spec.replicas(%d) > currentMachineCount(%d) )",
-				i+1, diff, *(ms.Spec.Replicas), len(machines))
+			log.Info(fmt.Sprintf("Creating machine %d of %d, ( spec.replicas(%d) > currentMachineCount(%d) )",
+				i+1, diff, *(ms.Spec.Replicas), len(machines)))
 
 			machine := r.getNewMachine(ms)
 
@@ -269,7 +327,7 @@ func (r *MachineSetReconciler) syncReplicas(ms *clusterv1.MachineSet, machines [
 				err                          error
 			)
 
-			infraConfig, err = external.CloneTemplate(r.Client, &machine.Spec.InfrastructureRef, machine.Namespace)
+			infraConfig, err = external.CloneTemplate(ctx, r.Client, &machine.Spec.InfrastructureRef, machine.Namespace)
 			if err != nil {
 				return errors.Wrapf(err, "failed to clone infrastructure configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
 			}
@@ -281,7 +339,7 @@ func (r *MachineSetReconciler) syncReplicas(ms *clusterv1.MachineSet, machines [
 			}
 
 			if machine.Spec.Bootstrap.ConfigRef != nil {
-				bootstrapConfig, err = external.CloneTemplate(r.Client, machine.Spec.Bootstrap.ConfigRef, machine.Namespace)
+				bootstrapConfig, err = external.CloneTemplate(ctx, r.Client, machine.Spec.Bootstrap.ConfigRef, machine.Namespace)
 				if err != nil {
 					return errors.Wrapf(err, "failed to clone bootstrap configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
 				}
@@ -293,22 +351,22 @@ func (r *MachineSetReconciler) syncReplicas(ms *clusterv1.MachineSet, machines [
 				}
 			}
 
-			if err := r.Client.Create(context.TODO(), machine); err != nil {
-				klog.Errorf("Unable to create Machine %q: %v", machine.Name, err)
-				r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedCreate", "Failed to create machine %q: %v", machine.Name, err)
+			if err := r.Client.Create(ctx, machine); err != nil {
+				log.Error(err, "Unable to create Machine")
+				record.Warnf(ms, record.FailedCreateReason, "Failed to create machine %q: %v", machine.Name, err)
 				errstrings = append(errstrings, err.Error())
-				if err := r.Client.Delete(context.TODO(), infraConfig); !apierrors.IsNotFound(err) {
-					klog.Errorf("Failed to cleanup infrastructure configuration object after Machine creation error: %v", err)
+				if err := r.Client.Delete(ctx, infraConfig); !apierrors.IsNotFound(err) {
+					log.Error(err, "Failed to cleanup infrastructure configuration object after Machine creation error")
 				}
 				if bootstrapConfig != nil {
-					if err := r.Client.Delete(context.TODO(), bootstrapConfig); !apierrors.IsNotFound(err) {
-						klog.Errorf("Failed to cleanup bootstrap configuration object after Machine creation error: %v", err)
+					if err := r.Client.Delete(ctx, bootstrapConfig); !apierrors.IsNotFound(err) {
+						log.Error(err, "Failed to cleanup bootstrap configuration object after Machine creation error")
 					}
 				}
 				continue
 			}
-			klog.Infof("Created machine %d of %d with name %q", i+1, diff, machine.Name)
-			r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulCreate", "Created machine %q", machine.Name)
+			log.Info(fmt.Sprintf("Created machine %d of %d", i+1, diff), logutil.MachineKey, machine.Name)
+			record.Eventf(ms, record.SuccessfulCreateReason, "Created machine %q", machine.Name)
 
 			machineList = append(machineList, machine)
 		}
@@ -317,16 +375,15 @@ func (r *MachineSetReconciler) syncReplicas(ms *clusterv1.MachineSet, machines [
 			return errors.New(strings.Join(errstrings, "; "))
 		}
 
-		return r.waitForMachineCreation(machineList)
+		return r.waitForMachineCreation(ctx, machineList)
 	} else if diff > 0 {
-		klog.Infof("Too many replicas for %v %s/%s, need %d, deleting %d",
-			machineSetKind, ms.Namespace, ms.Name, *(ms.Spec.Replicas), diff)
+		log.Info("Too many replicas", "need", *(ms.Spec.Replicas), "deleting", diff)
 
 		deletePriorityFunc, err := getDeletePriorityFunc(ms)
 		if err != nil {
 			return err
 		}
-		klog.Infof("Found %q delete policy", ms.Spec.DeletePolicy)
+		log.Info("Found delete policy", "deletePolicy", ms.Spec.DeletePolicy)
 		// Choose which Machines to delete.
 		machinesToDelete := getMachinesToDeletePrioritized(machines, diff, deletePriorityFunc)
 
@@ -336,14 +393,14 @@ func (r *MachineSetReconciler) syncReplicas(ms *clusterv1.MachineSet, machines [
 		for _, machine := range machinesToDelete {
 			go func(targetMachine *clusterv1.Machine) {
 				defer wg.Done()
-				err := r.Client.Delete(context.Background(), targetMachine)
+				err := r.Client.Delete(ctx, targetMachine)
 				if err != nil {
-					klog.Errorf("Unable to delete Machine %s: %v", targetMachine.Name, err)
-					r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedDelete", "Failed to delete machine %q: %v", targetMachine.Name, err)
+					log.Error(err, "Unable to delete Machine", logutil.MachineKey, targetMachine.Name)
+					record.Warnf(ms, record.FailedDeleteReason, "Failed to delete machine %q: %v", targetMachine.Name, err)
 					errCh <- err
 				}
-				klog.Infof("Deleted machine %q", targetMachine.Name)
-				r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted machine %q", targetMachine.Name)
+				log.Info("Deleted machine", logutil.MachineKey, targetMachine.Name)
+				record.Eventf(ms, record.SuccessfulDeleteReason, "Deleted machine %q", targetMachine.Name)
 			}(machine)
 		}
 		wg.Wait()
@@ -357,7 +414,7 @@ func (r *MachineSetReconciler) syncReplicas(ms *clusterv1.MachineSet, machines [
 		default:
 		}
 
-		return r.waitForMachineDeletion(machinesToDelete)
+		return r.waitForMachineDeletion(ctx, machinesToDelete)
 	}
 
 	return nil
@@ -372,12 +429,9 @@ func (r *MachineSetReconciler) getNewMachine(machineSet *clusterv1.MachineSet) *
 			Kind:       gv.WithKind("Machine").Kind,
 			APIVersion: gv.String(),
 		},
-		ObjectMeta: metav1.ObjectMeta{
-			Labels:      machineSet.Spec.Template.Labels,
-			Annotations: machineSet.Spec.Template.Annotations,
-		},
 		Spec: machineSet.Spec.Template.Spec,
 	}
+	applyMachineTemplate(machine, &machineSet.Spec.Template)
 	machine.ObjectMeta.GenerateName = fmt.Sprintf("%s-", machineSet.Name)
 	machine.ObjectMeta.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(machineSet, machineSetKind)}
 	machine.Namespace = machineSet.Namespace
@@ -387,30 +441,31 @@ func (r *MachineSetReconciler) getNewMachine(machineSet *clusterv1.MachineSet) *
 // shouldExcludeMachine returns true if the machine should be filtered out, false otherwise.
 func shouldExcludeMachine(machineSet *clusterv1.MachineSet, machine *clusterv1.Machine) bool {
 	if metav1.GetControllerOf(machine) != nil && !metav1.IsControlledBy(machine, machineSet) {
-		klog.V(4).Infof("%s not controlled by %v", machine.Name, machineSet.Name)
 		return true
 	}
 	return machine.ObjectMeta.DeletionTimestamp != nil
 }
 
 // adoptOrphan sets the MachineSet as a controller OwnerReference to the Machine.
-func (r *MachineSetReconciler) adoptOrphan(machineSet *clusterv1.MachineSet, machine *clusterv1.Machine) error {
+func (r *MachineSetReconciler) adoptOrphan(ctx context.Context, machineSet *clusterv1.MachineSet, machine *clusterv1.Machine) error {
 	patch := client.MergeFrom(machine.DeepCopy())
 	newRef := *metav1.NewControllerRef(machineSet, machineSetKind)
 	machine.OwnerReferences = append(machine.OwnerReferences, newRef)
-	return r.Client.Patch(context.Background(), machine, patch)
+	return r.Client.Patch(ctx, machine, patch)
 }
 
-func (r *MachineSetReconciler) waitForMachineCreation(machineList []*clusterv1.Machine) error {
+func (r *MachineSetReconciler) waitForMachineCreation(ctx context.Context, machineList []*clusterv1.Machine) error {
+	log := logutil.FromContext(ctx)
+
 	for _, machine := range machineList {
 		pollErr := util.PollImmediate(stateConfirmationInterval, stateConfirmationTimeout, func() (bool, error) {
 			key := client.ObjectKey{Namespace: machine.Namespace, Name: machine.Name}
 
-			if err := r.Client.Get(context.Background(), key, &clusterv1.Machine{}); err != nil {
+			if err := r.Client.Get(ctx, key, &clusterv1.Machine{}); err != nil {
 				if apierrors.IsNotFound(err) {
 					return false, nil
 				}
-				klog.Error(err)
+				log.Error(err, "Failed to get Machine", logutil.MachineKey, machine.Name)
 				return false, err
 			}
 
@@ -418,7 +473,7 @@ func (r *MachineSetReconciler) waitForMachineCreation(machineList []*clusterv1.M
 		})
 
 		if pollErr != nil {
-			klog.Error(pollErr)
+			log.Error(pollErr, "Failed waiting for Machine to be created", logutil.MachineKey, machine.Name)
 			return errors.Wrap(pollErr, "failed waiting for machine object to be created")
 		}
 	}
@@ -426,13 +481,15 @@ func (r *MachineSetReconciler) waitForMachineCreation(machineList []*clusterv1.M
 	return nil
 }
 
-func (r *MachineSetReconciler) waitForMachineDeletion(machineList []*clusterv1.Machine) error {
+func (r *MachineSetReconciler) waitForMachineDeletion(ctx context.Context, machineList []*clusterv1.Machine) error {
+	log := logutil.FromContext(ctx)
+
 	for _, machine := range machineList {
 		pollErr := util.PollImmediate(stateConfirmationInterval, stateConfirmationTimeout, func() (bool, error) {
 			m := &clusterv1.Machine{}
 			key := client.ObjectKey{Namespace: machine.Namespace, Name: machine.Name}
 
-			err := r.Client.Get(context.Background(), key, m)
+			err := r.Client.Get(ctx, key, m)
 			if apierrors.IsNotFound(err) || !m.DeletionTimestamp.IsZero() {
 				return true, nil
 			}
@@ -441,7 +498,7 @@ func (r *MachineSetReconciler) waitForMachineDeletion(machineList []*clusterv1.M
 		})
 
 		if pollErr != nil {
-			klog.Error(pollErr)
+			log.Error(pollErr, "Failed waiting for Machine to be deleted", logutil.MachineKey, machine.Name)
 			return errors.Wrap(pollErr, "failed waiting for machine object to be deleted")
 		}
 	}
@@ -455,7 +512,7 @@ func (r *MachineSetReconciler) MachineToMachineSets(o handler.MapObject) []ctrl.
 
 	m, ok := o.Object.(*clusterv1.Machine)
 	if !ok {
-		klog.Errorf("expected a Machine but got a %T", o.Object)
+		r.Log.Error(errors.Errorf("expected a Machine but got a %T", o.Object), "Failed to map object to MachineSets")
 		return nil
 	}
 
@@ -467,9 +524,10 @@ func (r *MachineSetReconciler) MachineToMachineSets(o handler.MapObject) []ctrl.
 		}
 	}
 
-	mss := r.getMachineSetsForMachine(m)
+	log := r.Log.WithValues(logutil.NamespaceKey, m.Namespace, logutil.MachineKey, m.Name)
+	mss := r.getMachineSetsForMachine(log, m)
 	if len(mss) == 0 {
-		klog.V(4).Infof("Found no MachineSet for Machine %q", m.Name)
+		log.V(4).Info("Found no MachineSet for Machine")
 		return nil
 	}
 
@@ -481,23 +539,23 @@ func (r *MachineSetReconciler) MachineToMachineSets(o handler.MapObject) []ctrl.
 	return result
 }
 
-func (r *MachineSetReconciler) getMachineSetsForMachine(m *clusterv1.Machine) []*clusterv1.MachineSet {
+func (r *MachineSetReconciler) getMachineSetsForMachine(log logr.Logger, m *clusterv1.Machine) []*clusterv1.MachineSet {
 	if len(m.Labels) == 0 {
-		klog.Warningf("No machine sets found for Machine %v because it has no labels", m.Name)
+		log.Info("No machine sets found for Machine because it has no labels")
 		return nil
 	}
 
 	msList := &clusterv1.MachineSetList{}
 	err := r.Client.List(context.Background(), msList, client.InNamespace(m.Namespace))
 	if err != nil {
-		klog.Errorf("Failed to list machine sets, %v", err)
+		log.Error(err, "Failed to list machine sets")
 		return nil
 	}
 
 	var mss []*clusterv1.MachineSet
 	for idx := range msList.Items {
 		ms := &msList.Items[idx]
-		if r.hasMatchingLabels(ms, m) {
+		if r.hasMatchingLabels(log, ms, m) {
 			mss = append(mss, ms)
 		}
 	}
@@ -505,21 +563,21 @@ func (r *MachineSetReconciler) getMachineSetsForMachine(m *clusterv1.Machine) []
 	return mss
 }
 
-func (r *MachineSetReconciler) hasMatchingLabels(machineSet *clusterv1.MachineSet, machine *clusterv1.Machine) bool {
+func (r *MachineSetReconciler) hasMatchingLabels(log logr.Logger, machineSet *clusterv1.MachineSet, machine *clusterv1.Machine) bool {
 	selector, err := metav1.LabelSelectorAsSelector(&machineSet.Spec.Selector)
 	if err != nil {
-		klog.Warningf("unable to convert selector: %v", err)
+		log.Error(err, "Unable to convert selector", logutil.MachineSetKey, machineSet.Name)
 		return false
 	}
 
 	// If a deployment with a nil or empty selector creeps in, it should match nothing, not everything.
 	if selector.Empty() {
-		klog.V(2).Infof("%v machineset has empty selector", machineSet.Name)
+		log.V(2).Info("MachineSet has empty selector", logutil.MachineSetKey, machineSet.Name)
 		return false
 	}
 
 	if !selector.Matches(labels.Set(machine.Labels)) {
-		klog.V(4).Infof("%v machine has mismatch labels", machine.Name)
+		log.V(4).Info("Machine has mismatch labels", logutil.MachineSetKey, machineSet.Name)
 		return false
 	}
 
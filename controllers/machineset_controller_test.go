@@ -30,7 +30,6 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -282,8 +281,7 @@ func TestMachineSetOwnerReference(t *testing.T) {
 					ms2,
 					ms3,
 				),
-				Log:      log.Log,
-				recorder: record.NewFakeRecorder(32),
+				Log: log.Log,
 			}
 
 			_, err := msr.Reconcile(tc.request)
@@ -553,7 +551,7 @@ func TestAdoptOrphan(t *testing.T) {
 		Log:    log.Log,
 	}
 	for _, tc := range testCases {
-		err := r.adoptOrphan(tc.machineSet.DeepCopy(), tc.machine.DeepCopy())
+		err := r.adoptOrphan(context.Background(), tc.machineSet.DeepCopy(), tc.machine.DeepCopy())
 		Expect(err).ToNot(HaveOccurred())
 
 		key := client.ObjectKey{Namespace: tc.machine.Namespace, Name: tc.machine.Name}
@@ -567,6 +565,53 @@ func TestAdoptOrphan(t *testing.T) {
 	}
 }
 
+func TestSyncMachines(t *testing.T) {
+	RegisterTestingT(t)
+
+	m := clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine",
+			Namespace: "default",
+			Labels:    map[string]string{"pool": "cpu"},
+		},
+		Spec: clusterv1.MachineSpec{
+			NodeLabels: map[string]string{"pool": "cpu"},
+		},
+	}
+	ms := clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machineset",
+			Namespace: "default",
+		},
+		Spec: clusterv1.MachineSetSpec{
+			Template: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels:      map[string]string{"pool": "gpu"},
+					Annotations: map[string]string{"cost-center": "42"},
+				},
+				Spec: clusterv1.MachineSpec{
+					NodeLabels: map[string]string{"pool": "gpu"},
+					NodeTaints: []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+				},
+			},
+		},
+	}
+
+	clusterv1.AddToScheme(scheme.Scheme)
+	r := &MachineSetReconciler{
+		Client: fake.NewFakeClient(&m),
+		Log:    log.Log,
+	}
+	Expect(r.syncMachines(context.Background(), &ms, []*clusterv1.Machine{m.DeepCopy()})).To(Succeed())
+
+	var got clusterv1.Machine
+	Expect(r.Client.Get(context.Background(), client.ObjectKey{Namespace: m.Namespace, Name: m.Name}, &got)).To(Succeed())
+	Expect(got.Labels).To(Equal(ms.Spec.Template.Labels))
+	Expect(got.Annotations).To(HaveKeyWithValue("cost-center", "42"))
+	Expect(got.Spec.NodeLabels).To(Equal(ms.Spec.Template.Spec.NodeLabels))
+	Expect(got.Spec.NodeTaints).To(Equal(ms.Spec.Template.Spec.NodeTaints))
+}
+
 func TestHasMatchingLabels(t *testing.T) {
 	r := &MachineSetReconciler{}
 
@@ -618,7 +663,7 @@ func TestHasMatchingLabels(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		got := r.hasMatchingLabels(&tc.machineSet, &tc.machine)
+		got := r.hasMatchingLabels(log.Log, &tc.machineSet, &tc.machine)
 		if tc.expected != got {
 			t.Errorf("Case %s. Got: %v, expected %v", tc.machine.Name, got, tc.expected)
 		}
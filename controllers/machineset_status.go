@@ -23,11 +23,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/klog"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util"
+	logutil "sigs.k8s.io/cluster-api/util/log"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -36,7 +36,8 @@ const (
 	statusUpdateRetries = 1
 )
 
-func (r *MachineSetReconciler) calculateStatus(ms *clusterv1.MachineSet, filteredMachines []*clusterv1.Machine) clusterv1.MachineSetStatus {
+func (r *MachineSetReconciler) calculateStatus(ctx context.Context, ms *clusterv1.MachineSet, filteredMachines []*clusterv1.Machine) clusterv1.MachineSetStatus {
+	log := logutil.FromContext(ctx)
 	newStatus := ms.Status
 
 	// Count the number of machines that have labels matching the labels of the machine
@@ -50,7 +51,7 @@ func (r *MachineSetReconciler) calculateStatus(ms *clusterv1.MachineSet, filtere
 	templateLabel := labels.Set(ms.Spec.Template.Labels).AsSelectorPreValidated()
 
 	// Retrieve Cluster, if any.
-	cluster, _ := util.GetClusterFromMetadata(context.Background(), r.Client, ms.ObjectMeta)
+	cluster, _ := util.GetClusterFromMetadata(ctx, r.Client, ms.ObjectMeta)
 
 	for _, machine := range filteredMachines {
 		if templateLabel.Matches(labels.Set(machine.Labels)) {
@@ -58,15 +59,13 @@ func (r *MachineSetReconciler) calculateStatus(ms *clusterv1.MachineSet, filtere
 		}
 
 		if machine.Status.NodeRef == nil {
-			klog.Warningf("Unable to retrieve Node status for Machine %q in namespace %q: missing NodeRef",
-				machine.Name, machine.Namespace)
+			log.Info("Unable to retrieve Node status for Machine: missing NodeRef", logutil.MachineKey, machine.Name)
 			continue
 		}
 
-		node, err := r.getMachineNode(cluster, machine)
+		node, err := r.getMachineNode(ctx, cluster, machine)
 		if err != nil {
-			klog.Warningf("Unable to retrieve Node status for Machine %q in namespace %q: %v",
-				machine.Name, machine.Namespace, err)
+			log.Info("Unable to retrieve Node status for Machine", logutil.MachineKey, machine.Name, "error", err)
 			continue
 		}
 
@@ -86,7 +85,7 @@ func (r *MachineSetReconciler) calculateStatus(ms *clusterv1.MachineSet, filtere
 }
 
 // updateMachineSetStatus attempts to update the Status.Replicas of the given MachineSet, with a single GET/PUT retry.
-func updateMachineSetStatus(c client.Client, ms *clusterv1.MachineSet, newStatus clusterv1.MachineSetStatus) (*clusterv1.MachineSet, error) {
+func updateMachineSetStatus(ctx context.Context, c client.Client, ms *clusterv1.MachineSet, newStatus clusterv1.MachineSetStatus) (*clusterv1.MachineSet, error) {
 	// This is the steady state. It happens when the MachineSet doesn't have any expectations, since
 	// we do a periodic relist every 10 minutes. If the generations differ but the replicas are
 	// the same, a caller might've resized to the same replica count.
@@ -110,7 +109,7 @@ func updateMachineSetStatus(c client.Client, ms *clusterv1.MachineSet, newStatus
 		if ms.Spec.Replicas != nil {
 			replicas = *ms.Spec.Replicas
 		}
-		klog.V(4).Infof(fmt.Sprintf("Updating status for %v: %s/%s, ", ms.Kind, ms.Namespace, ms.Name) +
+		logutil.FromContext(ctx).V(4).Info("Updating status: " +
 			fmt.Sprintf("replicas %d->%d (need %d), ", ms.Status.Replicas, newStatus.Replicas, replicas) +
 			fmt.Sprintf("fullyLabeledReplicas %d->%d, ", ms.Status.FullyLabeledReplicas, newStatus.FullyLabeledReplicas) +
 			fmt.Sprintf("readyReplicas %d->%d, ", ms.Status.ReadyReplicas, newStatus.ReadyReplicas) +
@@ -118,7 +117,7 @@ func updateMachineSetStatus(c client.Client, ms *clusterv1.MachineSet, newStatus
 			fmt.Sprintf("sequence No: %v->%v", ms.Status.ObservedGeneration, newStatus.ObservedGeneration))
 
 		ms.Status = newStatus
-		updateErr = c.Status().Update(context.Background(), ms)
+		updateErr = c.Status().Update(ctx, ms)
 		if updateErr == nil {
 			return ms, nil
 		}
@@ -127,7 +126,7 @@ func updateMachineSetStatus(c client.Client, ms *clusterv1.MachineSet, newStatus
 			break
 		}
 		// Update the MachineSet with the latest resource version for the next poll
-		if getErr = c.Get(context.Background(), client.ObjectKey{Namespace: ms.Namespace, Name: ms.Name}, ms); getErr != nil {
+		if getErr = c.Get(ctx, client.ObjectKey{Namespace: ms.Namespace, Name: ms.Name}, ms); getErr != nil {
 			// If the GET fails we can't trust status.Replicas anymore. This error
 			// is bound to be more interesting than the update failure.
 			return nil, getErr
@@ -137,16 +136,16 @@ func updateMachineSetStatus(c client.Client, ms *clusterv1.MachineSet, newStatus
 	return nil, updateErr
 }
 
-func (r *MachineSetReconciler) getMachineNode(cluster *clusterv1.Cluster, machine *clusterv1.Machine) (*corev1.Node, error) {
+func (r *MachineSetReconciler) getMachineNode(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) (*corev1.Node, error) {
 	if cluster == nil {
 		// Try to retrieve the Node from the local cluster, if no Cluster reference is found.
 		node := &corev1.Node{}
-		err := r.Client.Get(context.Background(), client.ObjectKey{Name: machine.Status.NodeRef.Name}, node)
+		err := r.Client.Get(ctx, client.ObjectKey{Name: machine.Status.NodeRef.Name}, node)
 		return node, err
 	}
 
 	// Otherwise, proceed to get the remote cluster client and get the Node.
-	remoteClient, err := remote.NewClusterClient(r.Client, cluster)
+	remoteClient, err := remote.NewClusterClient(ctx, r.Client, cluster)
 	if err != nil {
 		return nil, err
 	}
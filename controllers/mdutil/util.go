@@ -25,13 +25,13 @@ import (
 	"strings"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/klog"
 	"k8s.io/utils/integer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 )
@@ -51,6 +51,13 @@ const (
 	// is machinedeployment.spec.replicas + maxSurge. Used by the underlying machine sets to estimate their
 	// proportions in case the deployment has surge replicas.
 	MaxReplicasAnnotation = "machinedeployment.clusters.k8s.io/max-replicas"
+	// InfrastructureTemplateHashAnnotation is the hash of the content of the infrastructure template referenced by the
+	// machine template of a machine set, recorded in the machine set. A machine set created from a previous content of
+	// the template isn't the new machine set of its deployment anymore, which rolls out a new one.
+	InfrastructureTemplateHashAnnotation = "machinedeployment.clusters.k8s.io/infrastructure-template-hash"
+	// BootstrapTemplateHashAnnotation is the hash of the content of the bootstrap template referenced by the
+	// machine template of a machine set, recorded in the machine set.
+	BootstrapTemplateHashAnnotation = "machinedeployment.clusters.k8s.io/bootstrap-template-hash"
 
 	// FailedMSCreateReason is added in a machine deployment when it cannot create a new machine set.
 	FailedMSCreateReason = "MachineSetCreateError"
@@ -124,12 +131,13 @@ func SetDeploymentRevision(deployment *clusterv1.MachineDeployment, revision str
 }
 
 // MaxRevision finds the highest revision in the machine sets
-func MaxRevision(allMSs []*clusterv1.MachineSet) int64 {
+func MaxRevision(allMSs []*clusterv1.MachineSet, logger logr.Logger) int64 {
 	max := int64(0)
 	for _, ms := range allMSs {
 		if v, err := Revision(ms); err != nil {
 			// Skip the machine sets when it failed to parse their revision information
-			klog.V(4).Infof("Error: %v. Couldn't parse revision for machine set %#v, deployment controller will skip it when reconciling revisions.", err, ms)
+			logger.V(4).Info("Couldn't parse revision for machine set, deployment controller will skip it when reconciling revisions",
+				"machineSet", ms.Name, "error", err)
 		} else if v > max {
 			max = v
 		}
@@ -156,6 +164,13 @@ var annotationsToSkip = map[string]bool{
 	RevisionHistoryAnnotation:      true,
 	DesiredReplicasAnnotation:      true,
 	MaxReplicasAnnotation:          true,
+
+	InfrastructureTemplateHashAnnotation: true,
+	BootstrapTemplateHashAnnotation:      true,
+
+	// The size of the node group of a MachineDeployment is not the size of its MachineSets.
+	clusterv1.AutoscalerMinSizeAnnotation: true,
+	clusterv1.AutoscalerMaxSizeAnnotation: true,
 }
 
 // skipCopyAnnotation returns true if we should skip copying the annotation with the given annotation key
@@ -186,18 +201,19 @@ func copyDeploymentAnnotationsToMachineSet(deployment *clusterv1.MachineDeployme
 	return msAnnotationsChanged
 }
 
-func getMaxReplicasAnnotation(ms *clusterv1.MachineSet) (int32, bool) {
-	return getIntFromAnnotation(ms, MaxReplicasAnnotation)
+func getMaxReplicasAnnotation(ms *clusterv1.MachineSet, logger logr.Logger) (int32, bool) {
+	return getIntFromAnnotation(ms, MaxReplicasAnnotation, logger)
 }
 
-func getIntFromAnnotation(ms *clusterv1.MachineSet, annotationKey string) (int32, bool) {
+func getIntFromAnnotation(ms *clusterv1.MachineSet, annotationKey string, logger logr.Logger) (int32, bool) {
 	annotationValue, ok := ms.Annotations[annotationKey]
 	if !ok {
 		return int32(0), false
 	}
 	intValue, err := strconv.Atoi(annotationValue)
 	if err != nil {
-		klog.V(2).Infof("Cannot convert the value %q with annotation key %q for the machine set %q", annotationValue, annotationKey, ms.Name)
+		logger.V(2).Info("Cannot convert the annotation value to an integer", "machineSet", ms.Name,
+			"annotation", annotationKey, "value", annotationValue)
 		return int32(0), false
 	}
 	return int32(intValue), true
@@ -205,7 +221,7 @@ func getIntFromAnnotation(ms *clusterv1.MachineSet, annotationKey string) (int32
 
 // SetNewMachineSetAnnotations sets new machine set's annotations appropriately by updating its revision and
 // copying required deployment annotations to it; it returns true if machine set's annotation is changed.
-func SetNewMachineSetAnnotations(deployment *clusterv1.MachineDeployment, newMS *clusterv1.MachineSet, newRevision string, exists bool) bool {
+func SetNewMachineSetAnnotations(deployment *clusterv1.MachineDeployment, newMS *clusterv1.MachineSet, newRevision string, exists bool, logger logr.Logger) bool {
 	// First, copy deployment's annotations (except for apply and revision annotations)
 	annotationChanged := copyDeploymentAnnotationsToMachineSet(deployment, newMS)
 	// Then, update machine set's revision annotation
@@ -220,7 +236,7 @@ func SetNewMachineSetAnnotations(deployment *clusterv1.MachineDeployment, newMS
 	oldRevisionInt, err := strconv.ParseInt(oldRevision, 10, 64)
 	if err != nil {
 		if oldRevision != "" {
-			klog.Warningf("Updating machine set revision OldRevision not int %s", err)
+			logger.Error(err, "Updating machine set revision OldRevision not int")
 			return false
 		}
 		//If the MS annotation is empty then initialise it to 0
@@ -228,13 +244,13 @@ func SetNewMachineSetAnnotations(deployment *clusterv1.MachineDeployment, newMS
 	}
 	newRevisionInt, err := strconv.ParseInt(newRevision, 10, 64)
 	if err != nil {
-		klog.Warningf("Updating machine set revision NewRevision not int %s", err)
+		logger.Error(err, "Updating machine set revision NewRevision not int")
 		return false
 	}
 	if oldRevisionInt < newRevisionInt {
 		newMS.Annotations[RevisionAnnotation] = newRevision
 		annotationChanged = true
-		klog.V(4).Infof("Updating machine set %q revision to %s", newMS.Name, newRevision)
+		logger.V(4).Info("Updating machine set revision", "machineSet", newMS.Name, "revision", newRevision)
 	}
 	// If a revision annotation already existed and this machine set was updated with a new revision
 	// then that means we are rolling back to this machine set. We need to preserve the old revisions
@@ -342,12 +358,12 @@ func MaxSurge(deployment clusterv1.MachineDeployment) int32 {
 // GetProportion will estimate the proportion for the provided machine set using 1. the current size
 // of the parent deployment, 2. the replica count that needs be added on the machine sets of the
 // deployment, and 3. the total replicas added in the machine sets of the deployment so far.
-func GetProportion(ms *clusterv1.MachineSet, d clusterv1.MachineDeployment, deploymentReplicasToAdd, deploymentReplicasAdded int32) int32 {
+func GetProportion(ms *clusterv1.MachineSet, d clusterv1.MachineDeployment, deploymentReplicasToAdd, deploymentReplicasAdded int32, logger logr.Logger) int32 {
 	if ms == nil || *(ms.Spec.Replicas) == 0 || deploymentReplicasToAdd == 0 || deploymentReplicasToAdd == deploymentReplicasAdded {
 		return int32(0)
 	}
 
-	msFraction := getMachineSetFraction(*ms, d)
+	msFraction := getMachineSetFraction(*ms, d, logger)
 	allowed := deploymentReplicasToAdd - deploymentReplicasAdded
 
 	if deploymentReplicasToAdd > 0 {
@@ -364,14 +380,14 @@ func GetProportion(ms *clusterv1.MachineSet, d clusterv1.MachineDeployment, depl
 
 // getMachineSetFraction estimates the fraction of replicas a machine set can have in
 // 1. a scaling event during a rollout or 2. when scaling a paused deployment.
-func getMachineSetFraction(ms clusterv1.MachineSet, d clusterv1.MachineDeployment) int32 {
+func getMachineSetFraction(ms clusterv1.MachineSet, d clusterv1.MachineDeployment, logger logr.Logger) int32 {
 	// If we are scaling down to zero then the fraction of this machine set is its whole size (negative)
 	if *(d.Spec.Replicas) == int32(0) {
 		return -*(ms.Spec.Replicas)
 	}
 
 	deploymentReplicas := *(d.Spec.Replicas) + MaxSurge(d)
-	annotatedReplicas, ok := getMaxReplicasAnnotation(&ms)
+	annotatedReplicas, ok := getMaxReplicasAnnotation(&ms, logger)
 	if !ok {
 		// If we cannot find the annotation then fallback to the current deployment size. Note that this
 		// will not be an accurate proportion estimation in case other machine sets have different values
@@ -386,25 +402,78 @@ func getMachineSetFraction(ms clusterv1.MachineSet, d clusterv1.MachineDeploymen
 	return integer.RoundToInt32(newMSsize) - *(ms.Spec.Replicas)
 }
 
-// EqualIgnoreHash returns true if two given machineTemplateSpec are equal, ignoring the diff in value of Labels[machine-template-hash]
+// EqualIgnoreHash returns true if two given machineTemplateSpec are equal, ignoring the fields which are updated
+// in place on existing Machines: the labels and annotations of the template, including Labels[machine-template-hash],
+// and the Node metadata of the Machine spec.
 // We ignore machine-template-hash because:
 // 1. The hash result would be different upon machineTemplateSpec API changes
 //    (e.g. the addition of a new field will cause the hash code to change)
 // 2. The deployment template won't have hash labels
 func EqualIgnoreHash(template1, template2 *clusterv1.MachineTemplateSpec) bool {
-	t1Copy := template1.DeepCopy()
-	t2Copy := template2.DeepCopy()
-	// Remove hash labels from template.Labels before comparing
-	delete(t1Copy.Labels, DefaultMachineDeploymentUniqueLabelKey)
-	delete(t2Copy.Labels, DefaultMachineDeploymentUniqueLabelKey)
-	return apiequality.Semantic.DeepEqual(t1Copy, t2Copy)
+	return apiequality.Semantic.DeepEqual(hashedFields(template1), hashedFields(template2))
+}
+
+// SyncMachineTemplate copies the labels, annotations and Node metadata of src to dst, keeping the
+// machine-template-hash label of dst, and returns true if dst changed. These fields are propagated
+// to existing Machines in place, instead of rolling out new ones.
+func SyncMachineTemplate(dst, src *clusterv1.MachineTemplateSpec) bool {
+	updated := dst.DeepCopy()
+	src.ObjectMeta.DeepCopyInto(&updated.ObjectMeta)
+	if hash, ok := dst.Labels[DefaultMachineDeploymentUniqueLabelKey]; ok {
+		updated.Labels = CloneAndAddLabel(updated.Labels, DefaultMachineDeploymentUniqueLabelKey, hash)
+	}
+	SyncNodeMetadata(&updated.Spec, &src.Spec)
+
+	if apiequality.Semantic.DeepEqual(dst, updated) {
+		return false
+	}
+	*dst = *updated
+	return true
+}
+
+// SyncNodeMetadata copies the Node labels, annotations and taints of src to dst, and returns true if dst changed.
+func SyncNodeMetadata(dst, src *clusterv1.MachineSpec) bool {
+	if apiequality.Semantic.DeepEqual(dst.NodeLabels, src.NodeLabels) &&
+		apiequality.Semantic.DeepEqual(dst.NodeAnnotations, src.NodeAnnotations) &&
+		apiequality.Semantic.DeepEqual(dst.NodeTaints, src.NodeTaints) {
+		return false
+	}
+	srcCopy := src.DeepCopy()
+	dst.NodeLabels = srcCopy.NodeLabels
+	dst.NodeAnnotations = srcCopy.NodeAnnotations
+	dst.NodeTaints = srcCopy.NodeTaints
+	return true
+}
+
+// equalSelectorIgnoreHash returns true if two given label selectors are equal, ignoring the
+// machine-template-hash label. Machine sets with a different selector can't adopt the labels
+// of the deployment template in place.
+func equalSelectorIgnoreHash(selector1, selector2 *metav1.LabelSelector) bool {
+	s1Copy := selector1.DeepCopy()
+	s2Copy := selector2.DeepCopy()
+	delete(s1Copy.MatchLabels, DefaultMachineDeploymentUniqueLabelKey)
+	delete(s2Copy.MatchLabels, DefaultMachineDeploymentUniqueLabelKey)
+	return apiequality.Semantic.DeepEqual(s1Copy, s2Copy)
+}
+
+// hashedFields returns a copy of the template with only the fields which need new Machines when they change,
+// which are the bootstrap configuration, the infrastructure reference and the Kubernetes version of its spec.
+func hashedFields(template *clusterv1.MachineTemplateSpec) *clusterv1.MachineTemplateSpec {
+	return &clusterv1.MachineTemplateSpec{
+		Spec: clusterv1.MachineSpec{
+			Bootstrap:         *template.Spec.Bootstrap.DeepCopy(),
+			InfrastructureRef: template.Spec.InfrastructureRef,
+			Version:           template.Spec.Version,
+		},
+	}
 }
 
 // FindNewMachineSet returns the new MS this given deployment targets (the one with the same machine template).
 func FindNewMachineSet(deployment *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) *clusterv1.MachineSet {
 	sort.Sort(MachineSetsByCreationTimestamp(msList))
 	for i := range msList {
-		if EqualIgnoreHash(&msList[i].Spec.Template, &deployment.Spec.Template) {
+		if EqualIgnoreHash(&msList[i].Spec.Template, &deployment.Spec.Template) &&
+			equalSelectorIgnoreHash(&msList[i].Spec.Selector, &deployment.Spec.Selector) {
 			// In rare cases, such as after cluster upgrades, Deployment may end up with
 			// having more than one new MachineSets that have the same template,
 			// see https://github.com/kubernetes/kubernetes/issues/40415
@@ -416,6 +485,35 @@ func FindNewMachineSet(deployment *clusterv1.MachineDeployment, msList []*cluste
 	return nil
 }
 
+// EqualTemplateHashes returns true if the hashes of the referenced templates recorded in the machine set match the
+// given ones, keyed by annotation. Hashes not recorded in the machine set, which was created before they were
+// computed, match any value.
+func EqualTemplateHashes(ms *clusterv1.MachineSet, templateHashes map[string]string) bool {
+	for annotation, hash := range templateHashes {
+		if recorded, ok := ms.Annotations[annotation]; ok && recorded != hash {
+			return false
+		}
+	}
+	return true
+}
+
+// SetTemplateHashes records the hashes of the referenced templates in the machine set, and returns true if
+// the annotations of the machine set changed.
+func SetTemplateHashes(ms *clusterv1.MachineSet, templateHashes map[string]string) bool {
+	changed := false
+	for annotation, hash := range templateHashes {
+		if ms.Annotations[annotation] == hash {
+			continue
+		}
+		if ms.Annotations == nil {
+			ms.Annotations = map[string]string{}
+		}
+		ms.Annotations[annotation] = hash
+		changed = true
+	}
+	return changed
+}
+
 // FindOldMachineSets returns the old machine sets targeted by the given Deployment, with the given slice of MSes.
 // Returns two list of machine sets
 //  - the first contains all old machine sets with all non-zero replicas
@@ -680,9 +778,25 @@ func DeepHashObject(hasher hash.Hash, objectToWrite interface{}) {
 	printer.Fprintf(hasher, "%#v", objectToWrite)
 }
 
+// ComputeHashWithTemplates returns a hash of the fields of the Machine template which need new Machines when they
+// change, and of the content of the templates it references. It's the same as ComputeHash without templateHashes.
+func ComputeHashWithTemplates(template *clusterv1.MachineTemplateSpec, templateHashes map[string]string) uint32 {
+	if len(templateHashes) == 0 {
+		return ComputeHash(template)
+	}
+	machineTemplateSpecHasher := fnv.New32a()
+	DeepHashObject(machineTemplateSpecHasher, struct {
+		Template       clusterv1.MachineTemplateSpec
+		TemplateHashes map[string]string
+	}{*hashedFields(template), templateHashes})
+
+	return machineTemplateSpecHasher.Sum32()
+}
+
+// ComputeHash returns a hash of the fields of the Machine template which need new Machines when they change.
 func ComputeHash(template *clusterv1.MachineTemplateSpec) uint32 {
 	machineTemplateSpecHasher := fnv.New32a()
-	DeepHashObject(machineTemplateSpecHasher, *template)
+	DeepHashObject(machineTemplateSpecHasher, *hashedFields(template))
 
 	return machineTemplateSpecHasher.Sum32()
 }
@@ -25,11 +25,14 @@ import (
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apiserver/pkg/storage/names"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func newDControllerRef(d *clusterv1.MachineDeployment) *metav1.OwnerReference {
@@ -86,14 +89,16 @@ func generateDeployment(image string) clusterv1.MachineDeployment {
 	}
 }
 
-func generateMachineTemplateSpec(name, nodeName string, annotations, labels map[string]string) clusterv1.MachineTemplateSpec {
+func generateMachineTemplateSpec(name, version string, annotations, labels map[string]string) clusterv1.MachineTemplateSpec {
 	return clusterv1.MachineTemplateSpec{
 		ObjectMeta: clusterv1.ObjectMeta{
 			Name:        name,
 			Annotations: annotations,
 			Labels:      labels,
 		},
-		Spec: clusterv1.MachineSpec{},
+		Spec: clusterv1.MachineSpec{
+			Version: &version,
+		},
 	}
 }
 
@@ -105,62 +110,62 @@ func TestEqualIgnoreHash(t *testing.T) {
 	}{
 		{
 			"Same spec, same labels",
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
 			true,
 		},
 		{
 			"Same spec, only machine-template-hash label value is different",
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2", "something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2", "something": "else"}),
 			true,
 		},
 		{
 			"Same spec, the former doesn't have machine-template-hash label",
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{"something": "else"}),
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2", "something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{"something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2", "something": "else"}),
 			true,
 		},
 		{
 			"Same spec, the label is different, the former doesn't have machine-template-hash label, same number of labels",
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{"something": "else"}),
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2"}),
-			false,
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{"something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2"}),
+			true,
 		},
 		{
-			"Same spec, the label is different, the latter doesn't have machine-template-hash label, same number of labels",
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1"}),
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{"something": "else"}),
-			false,
+			"Same spec, the label is different, and the machine-template-hash label value is the same",
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
+			true,
 		},
 		{
-			"Same spec, the label is different, and the machine-template-hash label value is the same",
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1"}),
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
-			false,
+			"Same spec, different annotations and name",
+			generateMachineTemplateSpec("foo-1", "v1.16.0", map[string]string{"former": "value"}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
+			generateMachineTemplateSpec("foo-2", "v1.16.0", map[string]string{"latter": "value"}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
+			true,
 		},
 		{
 			"Different spec, same labels",
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{"former": "value"}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
-			generateMachineTemplateSpec("foo", "foo-node", map[string]string{"latter": "value"}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.1", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
 			false,
 		},
 		{
 			"Different spec, different machine-template-hash label value",
-			generateMachineTemplateSpec("foo-1", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
-			generateMachineTemplateSpec("foo-2", "foo-node", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2", "something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.1", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2", "something": "else"}),
 			false,
 		},
 		{
 			"Different spec, the former doesn't have machine-template-hash label",
-			generateMachineTemplateSpec("foo-1", "foo-node-1", map[string]string{}, map[string]string{"something": "else"}),
-			generateMachineTemplateSpec("foo-2", "foo-node-2", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2", "something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{"something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.1", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2", "something": "else"}),
 			false,
 		},
 		{
 			"Different spec, different labels",
-			generateMachineTemplateSpec("foo", "foo-node-1", map[string]string{}, map[string]string{"something": "else"}),
-			generateMachineTemplateSpec("foo", "foo-node-2", map[string]string{}, map[string]string{"nothing": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{"something": "else"}),
+			generateMachineTemplateSpec("foo", "v1.16.1", map[string]string{}, map[string]string{"nothing": "else"}),
 			false,
 		},
 	}
@@ -190,6 +195,69 @@ func TestEqualIgnoreHash(t *testing.T) {
 	}
 }
 
+func TestSyncMachineTemplate(t *testing.T) {
+	template := generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"})
+	updated := generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{"cost-center": "42"}, map[string]string{"something": "different"})
+	updated.Spec.NodeLabels = map[string]string{"node-role.kubernetes.io/worker": ""}
+	updated.Spec.NodeTaints = []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+
+	if !EqualIgnoreHash(&template, &updated) {
+		t.Errorf("expected templates differing only by metadata to be equal")
+	}
+	if ComputeHash(&template) != ComputeHash(&updated) {
+		t.Errorf("expected templates differing only by metadata to have the same hash")
+	}
+
+	if !SyncMachineTemplate(&template, &updated) {
+		t.Errorf("expected the template to be updated")
+	}
+	expected := updated.DeepCopy()
+	expected.Labels[DefaultMachineDeploymentUniqueLabelKey] = "value-1"
+	if !reflect.DeepEqual(&template, expected) {
+		t.Errorf("expected %v, got %v", expected, template)
+	}
+	if SyncMachineTemplate(&template, &updated) {
+		t.Errorf("expected the template to be up to date")
+	}
+
+	updated.Spec.Version = pointer.StringPtr("v1.16.1")
+	if EqualIgnoreHash(&template, &updated) {
+		t.Errorf("expected templates with different versions not to be equal")
+	}
+	if ComputeHash(&template) == ComputeHash(&updated) {
+		t.Errorf("expected templates with different versions to have different hashes")
+	}
+}
+
+func TestTemplateHashes(t *testing.T) {
+	template := generateMachineTemplateSpec("foo", "v1.16.0", map[string]string{}, map[string]string{})
+	hashes := map[string]string{InfrastructureTemplateHashAnnotation: "1234"}
+
+	ms := generateMS(generateDeployment("nginx"))
+	if !EqualTemplateHashes(&ms, hashes) {
+		t.Errorf("expected a machine set without recorded hashes to match any template")
+	}
+	if !SetTemplateHashes(&ms, hashes) {
+		t.Errorf("expected the machine set hashes to be updated")
+	}
+	if SetTemplateHashes(&ms, hashes) {
+		t.Errorf("expected the machine set hashes to be up to date")
+	}
+	if !EqualTemplateHashes(&ms, hashes) {
+		t.Errorf("expected the machine set to match the recorded hashes")
+	}
+	if EqualTemplateHashes(&ms, map[string]string{InfrastructureTemplateHashAnnotation: "5678"}) {
+		t.Errorf("expected the machine set not to match a changed template")
+	}
+
+	if ComputeHashWithTemplates(&template, nil) != ComputeHash(&template) {
+		t.Errorf("expected the hash without templates to be the template hash")
+	}
+	if ComputeHashWithTemplates(&template, hashes) == ComputeHashWithTemplates(&template, map[string]string{InfrastructureTemplateHashAnnotation: "5678"}) {
+		t.Errorf("expected different template contents to have different hashes")
+	}
+}
+
 func TestFindNewMachineSet(t *testing.T) {
 	now := metav1.Now()
 	later := metav1.Time{Time: now.Add(time.Minute)}
@@ -204,7 +272,7 @@ func TestFindNewMachineSet(t *testing.T) {
 	newMSDup.CreationTimestamp = now
 
 	oldDeployment := generateDeployment("nginx")
-	oldDeployment.Spec.Template.Name = "nginx-old-1"
+	oldDeployment.Spec.Template.Spec.Version = pointer.StringPtr("v1.15.0")
 	oldMS := generateMS(oldDeployment)
 	oldMS.Status.FullyLabeledReplicas = *(oldMS.Spec.Replicas)
 
@@ -259,7 +327,7 @@ func TestFindOldMachineSets(t *testing.T) {
 	newMSDup.CreationTimestamp = now
 
 	oldDeployment := generateDeployment("nginx")
-	oldDeployment.Spec.Template.Name = "nginx-old-1"
+	oldDeployment.Spec.Template.Spec.Version = pointer.StringPtr("v1.15.0")
 	oldMS := generateMS(oldDeployment)
 	oldMS.Status.FullyLabeledReplicas = *(oldMS.Spec.Replicas)
 	oldMS.CreationTimestamp = before
@@ -644,7 +712,7 @@ func TestAnnotationUtils(t *testing.T) {
 		for i := 0; i < 20; i++ {
 
 			nextRevision := fmt.Sprintf("%d", i+1)
-			SetNewMachineSetAnnotations(&tDeployment, &tMS, nextRevision, true)
+			SetNewMachineSetAnnotations(&tDeployment, &tMS, nextRevision, true, log.Log)
 			//Now the MachineSets Revision Annotation should be i+1
 
 			if tMS.Annotations[RevisionAnnotation] != nextRevision {
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics implements the Prometheus metrics of the Cluster API controllers.
+// The metrics are registered with Register, on the controller-runtime metrics registry
+// in the controller manager, and are exposed by the manager along with the controller-runtime ones.
+package metrics
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	namespace = "capi"
+
+	clusterLabel   = "cluster"
+	namespaceLabel = "namespace"
+)
+
+var (
+	// MachineProvisioningDuration is the time from the creation of a Machine to its NodeRef being set.
+	MachineProvisioningDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "machine",
+		Name:      "provisioning_duration_seconds",
+		Help:      "Time from the creation of a Machine to its Node being referenced.",
+		Buckets:   []float64{30, 60, 120, 180, 300, 600, 900, 1200, 1800, 3600},
+	}, []string{namespaceLabel, clusterLabel})
+
+	// MachineDrainDuration is the duration of the successful drains of Machine Nodes.
+	MachineDrainDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "machine",
+		Name:      "drain_duration_seconds",
+		Help:      "Duration of the successful drains of the Nodes of deleted Machines.",
+		Buckets:   []float64{1, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{namespaceLabel, clusterLabel})
+
+	// MachineDrainFailures is the number of failed drains of Machine Nodes.
+	MachineDrainFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "machine",
+		Name:      "drain_failures_total",
+		Help:      "Number of failed drains of the Nodes of deleted Machines.",
+	}, []string{namespaceLabel, clusterLabel})
+
+	// RemoteClientErrors is the number of errors creating clients of workload clusters.
+	RemoteClientErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "remote_cluster",
+		Name:      "client_errors_total",
+		Help:      "Number of errors creating clients of workload clusters.",
+	}, []string{namespaceLabel, clusterLabel})
+)
+
+// Register registers the metrics recorded by the controllers with the registry, along with a StateCollector
+// reading objects with the given client.
+func Register(registry prometheus.Registerer, c client.Reader, log logr.Logger) error {
+	for _, collector := range []prometheus.Collector{
+		MachineProvisioningDuration,
+		MachineDrainDuration,
+		MachineDrainFailures,
+		RemoteClientErrors,
+		NewStateCollector(c, log),
+	} {
+		if err := registry.Register(collector); err != nil {
+			return errors.Wrap(err, "failed to register metrics collector")
+		}
+	}
+	return nil
+}
+
+// ObserveMachineProvisioned records the provisioning duration of a Machine whose NodeRef has just been set.
+func ObserveMachineProvisioned(machine *clusterv1.Machine, now time.Time) {
+	MachineProvisioningDuration.
+		WithLabelValues(machine.Namespace, machine.Labels[clusterv1.MachineClusterLabelName]).
+		Observe(now.Sub(machine.CreationTimestamp.Time).Seconds())
+}
+
+// ObserveMachineDrain records the outcome of draining the Node of a Machine which started at the given time.
+func ObserveMachineDrain(machine *clusterv1.Machine, start time.Time, err error) {
+	cluster := machine.Labels[clusterv1.MachineClusterLabelName]
+	if err != nil {
+		MachineDrainFailures.WithLabelValues(machine.Namespace, cluster).Inc()
+		return
+	}
+	MachineDrainDuration.WithLabelValues(machine.Namespace, cluster).Observe(time.Since(start).Seconds())
+}
+
+// IncRemoteClientErrors records an error creating a client of the workload cluster of the Cluster.
+func IncRemoteClientErrors(cluster *clusterv1.Cluster) {
+	RemoteClientErrors.WithLabelValues(cluster.Namespace, cluster.Name).Inc()
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestRegister(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(runtime.NewScheme())
+
+	// Each registry gets all the collectors, e.g. one per test environment.
+	for i := 0; i < 2; i++ {
+		if err := Register(prometheus.NewRegistry(), c, log.NullLogger{}); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := Register(registry, c, log.NullLogger{}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := Register(registry, c, log.NullLogger{}); err == nil {
+		t.Error("expected an error registering the collectors twice with the same registry")
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	clustersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "clusters"),
+		"Current phase of each Cluster; the value is always 1.",
+		[]string{namespaceLabel, clusterLabel, "phase"}, nil,
+	)
+	machinesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "machines"),
+		"Number of Machines of each Cluster by phase.",
+		[]string{namespaceLabel, clusterLabel, "phase"}, nil,
+	)
+	machineDeploymentLabels       = []string{namespaceLabel, clusterLabel, "machinedeployment"}
+	machineDeploymentReplicasDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "machinedeployment", "replicas"),
+		"Number of desired replicas of a MachineDeployment.",
+		machineDeploymentLabels, nil,
+	)
+	machineDeploymentReadyReplicasDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "machinedeployment", "ready_replicas"),
+		"Number of ready replicas of a MachineDeployment.",
+		machineDeploymentLabels, nil,
+	)
+	machineDeploymentAvailableReplicasDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "machinedeployment", "available_replicas"),
+		"Number of available replicas of a MachineDeployment.",
+		machineDeploymentLabels, nil,
+	)
+)
+
+// StateCollector exports the state of the Cluster API objects, read from the client on each scrape.
+type StateCollector struct {
+	client client.Reader
+	log    logr.Logger
+}
+
+// NewStateCollector returns a StateCollector reading objects with the given client,
+// which should be backed by the manager cache.
+func NewStateCollector(c client.Reader, log logr.Logger) *StateCollector {
+	return &StateCollector{
+		client: c,
+		log:    log,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *StateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clustersDesc
+	ch <- machinesDesc
+	ch <- machineDeploymentReplicasDesc
+	ch <- machineDeploymentReadyReplicasDesc
+	ch <- machineDeploymentAvailableReplicasDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *StateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	clusters := &clusterv1.ClusterList{}
+	if err := c.client.List(ctx, clusters); err != nil {
+		c.log.Error(err, "failed to list Clusters")
+	}
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		ch <- prometheus.MustNewConstMetric(clustersDesc, prometheus.GaugeValue, 1,
+			cluster.Namespace, cluster.Name, string(cluster.Status.GetTypedPhase()))
+	}
+
+	machines := &clusterv1.MachineList{}
+	if err := c.client.List(ctx, machines); err != nil {
+		c.log.Error(err, "failed to list Machines")
+	}
+	type machineKey struct {
+		namespace, cluster string
+		phase              clusterv1.MachinePhase
+	}
+	machineCounts := map[machineKey]int{}
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		machineCounts[machineKey{
+			namespace: machine.Namespace,
+			cluster:   machine.Labels[clusterv1.MachineClusterLabelName],
+			phase:     machine.Status.GetTypedPhase(),
+		}]++
+	}
+	for key, count := range machineCounts {
+		ch <- prometheus.MustNewConstMetric(machinesDesc, prometheus.GaugeValue, float64(count),
+			key.namespace, key.cluster, string(key.phase))
+	}
+
+	deployments := &clusterv1.MachineDeploymentList{}
+	if err := c.client.List(ctx, deployments); err != nil {
+		c.log.Error(err, "failed to list MachineDeployments")
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		labels := []string{deployment.Namespace, deployment.Labels[clusterv1.MachineClusterLabelName], deployment.Name}
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		ch <- prometheus.MustNewConstMetric(machineDeploymentReplicasDesc, prometheus.GaugeValue, float64(desired), labels...)
+		ch <- prometheus.MustNewConstMetric(machineDeploymentReadyReplicasDesc, prometheus.GaugeValue, float64(deployment.Status.ReadyReplicas), labels...)
+		ch <- prometheus.MustNewConstMetric(machineDeploymentAvailableReplicasDesc, prometheus.GaugeValue, float64(deployment.Status.AvailableReplicas), labels...)
+	}
+}
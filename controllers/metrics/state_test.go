@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestStateCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	clusterLabels := map[string]string{clusterv1.MachineClusterLabelName: "test-cluster"}
+	objs := []runtime.Object{
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+			Status:     clusterv1.ClusterStatus{Phase: string(clusterv1.ClusterPhaseProvisioned)},
+		},
+		&clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "default", Labels: clusterLabels},
+			Status:     clusterv1.MachineStatus{Phase: string(clusterv1.MachinePhaseRunning)},
+		},
+		&clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "machine-2", Namespace: "default", Labels: clusterLabels},
+			Status:     clusterv1.MachineStatus{Phase: string(clusterv1.MachinePhaseRunning)},
+		},
+		&clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "machine-3", Namespace: "default", Labels: clusterLabels},
+			Status:     clusterv1.MachineStatus{Phase: string(clusterv1.MachinePhaseProvisioning)},
+		},
+		&clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "default", Labels: clusterLabels},
+			Spec:       clusterv1.MachineDeploymentSpec{Replicas: pointer.Int32Ptr(3)},
+			Status:     clusterv1.MachineDeploymentStatus{ReadyReplicas: 2, AvailableReplicas: 1},
+		},
+	}
+
+	collector := NewStateCollector(fake.NewFakeClientWithScheme(scheme, objs...), log.NullLogger{})
+
+	expected := `
+# HELP capi_clusters Current phase of each Cluster; the value is always 1.
+# TYPE capi_clusters gauge
+capi_clusters{cluster="test-cluster",namespace="default",phase="Provisioned"} 1
+# HELP capi_machines Number of Machines of each Cluster by phase.
+# TYPE capi_machines gauge
+capi_machines{cluster="test-cluster",namespace="default",phase="Provisioning"} 1
+capi_machines{cluster="test-cluster",namespace="default",phase="Running"} 2
+# HELP capi_machinedeployment_available_replicas Number of available replicas of a MachineDeployment.
+# TYPE capi_machinedeployment_available_replicas gauge
+capi_machinedeployment_available_replicas{cluster="test-cluster",machinedeployment="md",namespace="default"} 1
+# HELP capi_machinedeployment_ready_replicas Number of ready replicas of a MachineDeployment.
+# TYPE capi_machinedeployment_ready_replicas gauge
+capi_machinedeployment_ready_replicas{cluster="test-cluster",machinedeployment="md",namespace="default"} 2
+# HELP capi_machinedeployment_replicas Number of desired replicas of a MachineDeployment.
+# TYPE capi_machinedeployment_replicas gauge
+capi_machinedeployment_replicas{cluster="test-cluster",machinedeployment="md",namespace="default"} 3
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
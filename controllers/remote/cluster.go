@@ -17,12 +17,17 @@ limitations under the License.
 package remote
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/metrics"
 	kcfg "sigs.k8s.io/cluster-api/util/kubeconfig"
+	logutil "sigs.k8s.io/cluster-api/util/log"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -40,15 +45,21 @@ type clusterClient struct {
 }
 
 // NewClusterClient creates a new ClusterClient.
-func NewClusterClient(c client.Client, cluster *clusterv1.Cluster) (ClusterClient, error) {
+func NewClusterClient(ctx context.Context, c client.Client, cluster *clusterv1.Cluster) (_ ClusterClient, reterr error) {
+	ctx, span := tracing.Start(ctx, "remote.NewClusterClient", logutil.NamespaceKey, cluster.Namespace, logutil.ClusterKey, cluster.Name)
+	defer func() { tracing.End(ctx, span, reterr) }()
+
+	logutil.FromContext(ctx).V(4).Info("Creating remote cluster client")
 	kubeconfig, err := kcfg.FromSecret(c, cluster)
 	if err != nil {
+		metrics.IncRemoteClientErrors(cluster)
 		return nil, errors.Wrapf(err, "failed to retrieve kubeconfig secret for Cluster %q in namespace %q",
 			cluster.Name, cluster.Namespace)
 	}
 
 	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
 	if err != nil {
+		metrics.IncRemoteClientErrors(cluster)
 		return nil, errors.Wrapf(err, "failed to create client configuration for Cluster %q in namespace %q",
 			cluster.Name, cluster.Namespace)
 	}
@@ -66,5 +77,10 @@ func (c *clusterClient) RESTConfig() *restclient.Config {
 
 // CoreV1 returns a new Kubernetes CoreV1 client.
 func (c *clusterClient) CoreV1() (corev1.CoreV1Interface, error) {
-	return corev1.NewForConfig(c.RESTConfig())
+	client, err := corev1.NewForConfig(c.RESTConfig())
+	if err != nil {
+		metrics.IncRemoteClientErrors(c.cluster)
+		return nil, err
+	}
+	return client, nil
 }
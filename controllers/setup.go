@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/cluster-api/controllers/metrics"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// ManagerOptions configures the controllers registered by SetupWithManager.
+type ManagerOptions struct {
+	// ClusterConcurrency is the number of Clusters to process simultaneously.
+	ClusterConcurrency int
+
+	// MachineConcurrency is the number of Machines to process simultaneously.
+	MachineConcurrency int
+
+	// MachineSetConcurrency is the number of MachineSets to process simultaneously.
+	MachineSetConcurrency int
+
+	// MachineDeploymentConcurrency is the number of MachineDeployments to process simultaneously.
+	MachineDeploymentConcurrency int
+
+	// MetricsRegistry is the registry of the controller metrics, the controller-runtime one if nil.
+	MetricsRegistry prometheus.Registerer
+}
+
+// SetupWithManager registers the Cluster API controllers with the manager, along with their metrics.
+// It is shared by the controller manager and the test/helpers/envtest harness. The controllers record
+// their events through util/record, whose process-wide recorder must be initialized by the caller.
+func SetupWithManager(mgr ctrl.Manager, opts ManagerOptions) error {
+	log := ctrl.Log.WithName("controllers")
+	if err := (&ClusterReconciler{
+		Client: mgr.GetClient(),
+		Log:    log.WithName("Cluster"),
+	}).SetupWithManager(mgr, concurrency(opts.ClusterConcurrency)); err != nil {
+		return errors.Wrap(err, "failed to set up the Cluster controller")
+	}
+	if err := (&MachineReconciler{
+		Client: mgr.GetClient(),
+		Log:    log.WithName("Machine"),
+	}).SetupWithManager(mgr, concurrency(opts.MachineConcurrency)); err != nil {
+		return errors.Wrap(err, "failed to set up the Machine controller")
+	}
+	if err := (&MachineSetReconciler{
+		Client: mgr.GetClient(),
+		Log:    log.WithName("MachineSet"),
+	}).SetupWithManager(mgr, concurrency(opts.MachineSetConcurrency)); err != nil {
+		return errors.Wrap(err, "failed to set up the MachineSet controller")
+	}
+	if err := (&MachineDeploymentReconciler{
+		Client: mgr.GetClient(),
+		Log:    log.WithName("MachineDeployment"),
+	}).SetupWithManager(mgr, concurrency(opts.MachineDeploymentConcurrency)); err != nil {
+		return errors.Wrap(err, "failed to set up the MachineDeployment controller")
+	}
+
+	registry := opts.MetricsRegistry
+	if registry == nil {
+		registry = crmetrics.Registry
+	}
+	if err := metrics.Register(registry, mgr.GetClient(), ctrl.Log.WithName("metrics")); err != nil {
+		return errors.Wrap(err, "failed to register the controller metrics")
+	}
+	return nil
+}
+
+// concurrency returns the controller options processing c objects simultaneously, at least one.
+func concurrency(c int) controller.Options {
+	if c < 1 {
+		c = 1
+	}
+	return controller.Options{MaxConcurrentReconciles: c}
+}
@@ -104,25 +104,21 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(err).NotTo(HaveOccurred())
 	k8sClient = mgr.GetClient()
 	clusterReconciler = &ClusterReconciler{
-		Client:   k8sClient,
-		Log:      log.Log,
-		recorder: mgr.GetEventRecorderFor("cluster-controller"),
+		Client: k8sClient,
+		Log:    log.Log,
 	}
 	Expect(clusterReconciler.SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: 1})).NotTo(HaveOccurred())
 	Expect((&MachineReconciler{
-		Client:   k8sClient,
-		Log:      log.Log,
-		recorder: mgr.GetEventRecorderFor("machine-controller"),
+		Client: k8sClient,
+		Log:    log.Log,
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: 1})).NotTo(HaveOccurred())
 	Expect((&MachineSetReconciler{
-		Client:   k8sClient,
-		Log:      log.Log,
-		recorder: mgr.GetEventRecorderFor("machineset-controller"),
+		Client: k8sClient,
+		Log:    log.Log,
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: 1})).NotTo(HaveOccurred())
 	Expect((&MachineDeploymentReconciler{
-		Client:   k8sClient,
-		Log:      log.Log,
-		recorder: mgr.GetEventRecorderFor("machinedeployment-controller"),
+		Client: k8sClient,
+		Log:    log.Log,
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: 1})).NotTo(HaveOccurred())
 
 	By("starting the manager")
@@ -5,26 +5,35 @@ go 1.12
 require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/go-logr/logr v0.1.0
-	github.com/gogo/protobuf v1.2.1 // indirect
 	github.com/gophercloud/gophercloud v0.3.0 // indirect
 	github.com/imdario/mergo v0.3.7 // indirect
 	github.com/onsi/ginkgo v1.8.0
 	github.com/onsi/gomega v1.5.0
 	github.com/pkg/errors v0.8.1
+	github.com/prometheus/client_golang v1.0.0
 	github.com/sergi/go-diff v1.0.0
 	github.com/spf13/cobra v0.0.3
 	github.com/spf13/pflag v1.0.3
-	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2 // indirect
+	go.opentelemetry.io/otel v0.4.3
+	go.opentelemetry.io/otel/exporters/otlp v0.4.3
+	go.uber.org/zap v1.9.1
 	golang.org/x/oauth2 v0.0.0-20190604053449-0f29369cfe45 // indirect
 	golang.org/x/time v0.0.0-20190308202827-9d24e82272b4
+	google.golang.org/grpc v1.27.1
 	k8s.io/api v0.0.0-20190918195907-bd6ac527cfd2
 	k8s.io/apiextensions-apiserver v0.0.0-20190918201827-3de75813f604
 	k8s.io/apimachinery v0.0.0-20190817020851-f2f3a405f61d
 	k8s.io/apiserver v0.0.0-20190918200908-1e17798da8c1
-	k8s.io/client-go v0.0.0-20190918200256-06eb1244587a
+	k8s.io/client-go v11.0.0+incompatible
 	k8s.io/component-base v0.0.0-20190918200425-ed2f0867c778
 	k8s.io/klog v0.4.0
 	k8s.io/utils v0.0.0-20190809000727-6c36bc71fc4a
 	sigs.k8s.io/controller-runtime v0.3.0
+	sigs.k8s.io/kind v0.5.1
 	sigs.k8s.io/testing_frameworks v0.1.2-0.20190130140139-57f07443c2d4 // indirect
+	sigs.k8s.io/yaml v1.1.0
 )
+
+// sigs.k8s.io/kind v0.5.1 depends on kustomize, which requires k8s.io/client-go v11.0.0+incompatible.
+// Keep the client-go version matching the other k8s.io modules.
+replace k8s.io/client-go => k8s.io/client-go v0.0.0-20190918200256-06eb1244587a
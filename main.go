@@ -19,6 +19,7 @@ import (
 	"flag"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -28,9 +29,11 @@ import (
 	clusterv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	clusterv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers"
+	logutil "sigs.k8s.io/cluster-api/util/log"
+	"sigs.k8s.io/cluster-api/util/record"
 	"sigs.k8s.io/cluster-api/util/restmapper"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -59,6 +62,9 @@ func main() {
 		machineSetConcurrency        int
 		machineDeploymentConcurrency int
 		syncPeriod                   time.Duration
+		logFormat                    string
+		tracingExporter              string
+		tracingEndpoint              string
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080",
@@ -88,14 +94,39 @@ func main() {
 	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
+	flag.StringVar(&logFormat, "log-format", "text",
+		"The format of the logs, either text or json. JSON logs are written one object per line, at the verbosity set by -v")
+
+	flag.StringVar(&tracingExporter, "tracing-exporter", tracing.ExporterNone,
+		"Where to export the spans of the reconcile loops: none, stdout or otlp")
+
+	flag.StringVar(&tracingEndpoint, "tracing-endpoint", "localhost:55680",
+		"The address of the OpenTelemetry collector receiving spans when --tracing-exporter is otlp")
+
 	flag.Parse()
 
-	ctrl.SetLogger(klogr.New())
+	switch logFormat {
+	case "text":
+		ctrl.SetLogger(klogr.New())
+	case "json":
+		verbosity, _ := strconv.Atoi(flag.Lookup("v").Value.String())
+		ctrl.SetLogger(logutil.NewJSONLogger(verbosity))
+	default:
+		klog.Errorf("Invalid log format %q, must be text or json", logFormat)
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(tracingExporter, tracingEndpoint, "cluster-api-controller-manager")
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer shutdownTracing()
 
 	if profilerAddress != "" {
-		klog.Infof("Profiler listening for requests at %s", profilerAddress)
+		setupLog.Info("Profiler listening for requests", "address", profilerAddress)
 		go func() {
-			klog.Info(http.ListenAndServe(profilerAddress, nil))
+			setupLog.Error(http.ListenAndServe(profilerAddress, nil), "Profiler stopped")
 		}()
 	}
 
@@ -113,32 +144,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controllers.ClusterReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Cluster"),
-	}).SetupWithManager(mgr, concurrency(clusterConcurrency)); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Cluster")
-		os.Exit(1)
-	}
-	if err = (&controllers.MachineReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Machine"),
-	}).SetupWithManager(mgr, concurrency(machineConcurrency)); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Machine")
-		os.Exit(1)
-	}
-	if err = (&controllers.MachineSetReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("MachineSet"),
-	}).SetupWithManager(mgr, concurrency(machineSetConcurrency)); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "MachineSet")
-		os.Exit(1)
-	}
-	if err = (&controllers.MachineDeploymentReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("MachineDeployment"),
-	}).SetupWithManager(mgr, concurrency(machineDeploymentConcurrency)); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "MachineDeployment")
+	// All controllers record their events through util/record, which drops the repeats of an event.
+	record.InitFromRecorder(record.NewDeduplicatingRecorder(mgr.GetEventRecorderFor("cluster-api-controller-manager"), record.DefaultDeduplicationWindow))
+
+	if err := controllers.SetupWithManager(mgr, controllers.ManagerOptions{
+		ClusterConcurrency:           clusterConcurrency,
+		MachineConcurrency:           machineConcurrency,
+		MachineSetConcurrency:        machineSetConcurrency,
+		MachineDeploymentConcurrency: machineDeploymentConcurrency,
+	}); err != nil {
+		setupLog.Error(err, "unable to create controllers")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
@@ -149,7 +164,3 @@ func main() {
 		os.Exit(1)
 	}
 }
-
-func concurrency(c int) controller.Options {
-	return controller.Options{MaxConcurrentReconciles: c}
-}
@@ -0,0 +1,252 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package envtest provides a harness for controller integration tests running against real API servers.
+//
+// An Environment starts a management cluster API server with the Cluster API CRDs installed and all the
+// Cluster API controllers running, and a second API server acting as the workload cluster of the
+// Clusters under test:
+//
+//	env, err := envtest.New(envtest.Options{
+//		CRDDirectoryPaths: []string{fake.CRDPath()},
+//		AddToScheme:       []func(*runtime.Scheme) error{fake.AddToScheme},
+//		SetupWithManager: []func(manager.Manager) error{func(mgr manager.Manager) error {
+//			return fake.SetupWithManager(mgr, controller.Options{}, true)
+//		}},
+//	})
+//	...
+//	Expect(env.CreateKubeconfigSecret(ctx, cluster)).To(Succeed())
+//	env.EventuallyMachinePhase(ctx, key).Should(Equal(clusterv1.MachinePhaseRunning))
+//
+// Only one Environment can be created per process: the controllers record their events through the
+// process-wide recorder of util/record, which is initialized once with the recorder of the Environment manager.
+package envtest
+
+import (
+	"context"
+	"path/filepath"
+	goruntime "runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/secret"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// defaultSyncPeriod is the resync period of the manager cache, short enough for tests relying on resyncs.
+const defaultSyncPeriod = 10 * time.Second
+
+// Options configures an Environment.
+type Options struct {
+	// CRDDirectoryPaths are the directories of CRDs to install in addition to the Cluster API ones,
+	// e.g. the fake providers CRDs.
+	CRDDirectoryPaths []string
+
+	// CRDs are the CRDs to install in addition to the Cluster API ones.
+	CRDs []*apiextensionsv1beta1.CustomResourceDefinition
+
+	// AddToScheme are the functions adding additional types to the manager scheme.
+	AddToScheme []func(*runtime.Scheme) error
+
+	// SetupWithManager are the functions registering additional controllers with the manager,
+	// e.g. the fake providers controllers.
+	SetupWithManager []func(manager.Manager) error
+}
+
+// Environment is a management cluster running the Cluster API controllers, and a workload cluster.
+type Environment struct {
+	// Config is the REST configuration of the management cluster.
+	Config *rest.Config
+
+	// Client is a client of the management cluster, reading directly from the API server.
+	Client client.Client
+
+	// Manager is the manager running the controllers.
+	Manager manager.Manager
+
+	// WorkloadConfig is the REST configuration of the workload cluster.
+	WorkloadConfig *rest.Config
+
+	// WorkloadClient is a client of the workload cluster.
+	WorkloadClient client.Client
+
+	management *envtest.Environment
+	workload   *envtest.Environment
+	stop       chan struct{}
+}
+
+// created is set once an Environment has been created in the process.
+var created int32
+
+// New starts a management and a workload cluster, and the Cluster API controllers.
+// It returns an error if an Environment has already been created in the process.
+func New(opts Options) (*Environment, error) {
+	if !atomic.CompareAndSwapInt32(&created, 0, 1) {
+		return nil, errors.New("only one Environment can be created per process")
+	}
+
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range append([]func(*runtime.Scheme) error{clientgoscheme.AddToScheme, clusterv1.AddToScheme}, opts.AddToScheme...) {
+		if err := addToScheme(scheme); err != nil {
+			return nil, errors.Wrap(err, "failed to set up scheme")
+		}
+	}
+
+	e := &Environment{
+		management: &envtest.Environment{
+			CRDs:              opts.CRDs,
+			CRDDirectoryPaths: append([]string{crdPath()}, opts.CRDDirectoryPaths...),
+		},
+		workload: &envtest.Environment{},
+		stop:     make(chan struct{}),
+	}
+
+	var err error
+	if e.Config, err = e.management.Start(); err != nil {
+		return nil, errors.Wrap(err, "failed to start the management cluster")
+	}
+	if e.WorkloadConfig, err = e.workload.Start(); err != nil {
+		_ = e.management.Stop()
+		return nil, errors.Wrap(err, "failed to start the workload cluster")
+	}
+
+	if err := e.setup(scheme, opts); err != nil {
+		_ = e.workload.Stop()
+		_ = e.management.Stop()
+		return nil, err
+	}
+
+	go func() {
+		if err := e.Manager.Start(e.stop); err != nil {
+			ctrl.Log.WithName("envtest").Error(err, "failed to start the manager")
+		}
+	}()
+	return e, nil
+}
+
+// setup creates the clients and the manager, and registers the controllers as main.go does.
+func (e *Environment) setup(scheme *runtime.Scheme, opts Options) error {
+	var err error
+	if e.Client, err = client.New(e.Config, client.Options{Scheme: scheme}); err != nil {
+		return errors.Wrap(err, "failed to create the management cluster client")
+	}
+	if e.WorkloadClient, err = client.New(e.WorkloadConfig, client.Options{Scheme: scheme}); err != nil {
+		return errors.Wrap(err, "failed to create the workload cluster client")
+	}
+
+	syncPeriod := defaultSyncPeriod
+	e.Manager, err = manager.New(e.Config, manager.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: "0",
+		SyncPeriod:         &syncPeriod,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create the manager")
+	}
+
+	// The Environment owns the process-wide event recorder, as main.go does for the controller manager.
+	record.InitFromRecorder(record.NewDeduplicatingRecorder(e.Manager.GetEventRecorderFor("cluster-api-controller-manager"), record.DefaultDeduplicationWindow))
+
+	// The controller metrics are registered on a registry of the Environment instead of the global one.
+	if err := controllers.SetupWithManager(e.Manager, controllers.ManagerOptions{MetricsRegistry: prometheus.NewRegistry()}); err != nil {
+		return err
+	}
+
+	for _, setup := range opts.SetupWithManager {
+		if err := setup(e.Manager); err != nil {
+			return errors.Wrap(err, "failed to set up additional controllers")
+		}
+	}
+	return nil
+}
+
+// Stop stops the controllers, and the management and workload clusters.
+func (e *Environment) Stop() error {
+	close(e.stop)
+	workloadErr := e.workload.Stop()
+	if err := e.management.Stop(); err != nil {
+		return errors.Wrap(err, "failed to stop the management cluster")
+	}
+	return errors.Wrap(workloadErr, "failed to stop the workload cluster")
+}
+
+// CreateKubeconfigSecret creates the Kubeconfig secret of the Cluster, pointing to the workload cluster,
+// so that the controllers reach the workload cluster as they would reach a real one.
+func (e *Environment) CreateKubeconfigSecret(ctx context.Context, cluster *clusterv1.Cluster) error {
+	kubeconfig, err := kubeconfigFor(cluster.Name, e.WorkloadConfig)
+	if err != nil {
+		return err
+	}
+
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
+		},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: kubeconfig,
+		},
+	}
+	return errors.Wrapf(e.Client.Create(ctx, s), "failed to create the Kubeconfig secret of Cluster %q in namespace %q",
+		cluster.Name, cluster.Namespace)
+}
+
+// kubeconfigFor returns a serialized Kubeconfig for the given REST configuration.
+func kubeconfigFor(name string, config *rest.Config) ([]byte, error) {
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters[name] = &clientcmdapi.Cluster{
+		Server:                   config.Host,
+		CertificateAuthorityData: config.CAData,
+		InsecureSkipTLSVerify:    config.Insecure,
+	}
+	kubeconfig.AuthInfos[name] = &clientcmdapi.AuthInfo{
+		ClientCertificateData: config.CertData,
+		ClientKeyData:         config.KeyData,
+		Token:                 config.BearerToken,
+		Username:              config.Username,
+		Password:              config.Password,
+	}
+	kubeconfig.Contexts[name] = &clientcmdapi.Context{
+		Cluster:  name,
+		AuthInfo: name,
+	}
+	kubeconfig.CurrentContext = name
+
+	data, err := clientcmd.Write(*kubeconfig)
+	return data, errors.Wrapf(err, "failed to serialize the Kubeconfig of %q", name)
+}
+
+// crdPath returns the directory containing the Cluster API CRDs.
+func crdPath() string {
+	_, file, _, _ := goruntime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "config", "crd", "bases")
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/test/helpers/envtest"
+	"sigs.k8s.io/cluster-api/test/infrastructure/fake"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/infrastructure/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+func TestEnvironmentReconcilesCluster(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	env, err := envtest.New(envtest.Options{
+		CRDDirectoryPaths: []string{fake.CRDPath()},
+		AddToScheme:       []func(*runtime.Scheme) error{fake.AddToScheme},
+		SetupWithManager: []func(manager.Manager) error{func(mgr manager.Manager) error {
+			return fake.SetupWithManager(mgr, controller.Options{}, true)
+		}},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	defer func() {
+		g.Expect(env.Stop()).To(Succeed())
+	}()
+
+	fakeCluster := &infrav1.FakeCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "smoke",
+			Namespace: metav1.NamespaceDefault,
+		},
+	}
+	g.Expect(env.Client.Create(ctx, fakeCluster)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "smoke",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{
+				APIVersion: infrav1.GroupVersion.String(),
+				Kind:       "FakeCluster",
+				Name:       fakeCluster.Name,
+				Namespace:  fakeCluster.Namespace,
+			},
+		},
+	}
+	g.Expect(env.Client.Create(ctx, cluster)).To(Succeed())
+	g.Expect(env.CreateKubeconfigSecret(ctx, cluster)).To(Succeed())
+
+	env.EventuallyClusterPhase(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}, 30*time.Second).
+		Should(Equal(clusterv1.ClusterPhaseProvisioned))
+}
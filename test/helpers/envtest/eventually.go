@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envtest
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EventuallyClusterPhase returns an assertion polling the phase of the Cluster, e.g.
+//
+//	env.EventuallyClusterPhase(ctx, key).Should(Equal(clusterv1.ClusterPhaseProvisioned))
+//
+// The intervals are the timeout and polling interval of gomega.Eventually.
+func (e *Environment) EventuallyClusterPhase(ctx context.Context, key client.ObjectKey, intervals ...interface{}) gomega.AsyncAssertion {
+	return gomega.Eventually(func() (clusterv1.ClusterPhase, error) {
+		cluster := &clusterv1.Cluster{}
+		if err := e.Client.Get(ctx, key, cluster); err != nil {
+			return "", err
+		}
+		return cluster.Status.GetTypedPhase(), nil
+	}, intervals...)
+}
+
+// EventuallyMachinePhase returns an assertion polling the phase of the Machine.
+func (e *Environment) EventuallyMachinePhase(ctx context.Context, key client.ObjectKey, intervals ...interface{}) gomega.AsyncAssertion {
+	return gomega.Eventually(func() (clusterv1.MachinePhase, error) {
+		machine := &clusterv1.Machine{}
+		if err := e.Client.Get(ctx, key, machine); err != nil {
+			return "", err
+		}
+		return machine.Status.GetTypedPhase(), nil
+	}, intervals...)
+}
+
+// EventuallyMachinePhases returns an assertion polling the number of Machines in each phase
+// among the Machines matching the labels, e.g. the Machines of a MachineDeployment.
+func (e *Environment) EventuallyMachinePhases(ctx context.Context, namespace string, labels map[string]string, intervals ...interface{}) gomega.AsyncAssertion {
+	return gomega.Eventually(func() (map[clusterv1.MachinePhase]int, error) {
+		machines := &clusterv1.MachineList{}
+		if err := e.Client.List(ctx, machines, client.InNamespace(namespace), client.MatchingLabels(labels)); err != nil {
+			return nil, err
+		}
+		phases := map[clusterv1.MachinePhase]int{}
+		for i := range machines.Items {
+			phases[machines.Items[i].Status.GetTypedPhase()]++
+		}
+		return phases, nil
+	}, intervals...)
+}
+
+// EventuallyMachineDeploymentStatus returns an assertion polling the status of the MachineDeployment, e.g.
+//
+//	env.EventuallyMachineDeploymentStatus(ctx, key).Should(MatchFields(IgnoreExtras, Fields{"ReadyReplicas": Equal(int32(3))}))
+func (e *Environment) EventuallyMachineDeploymentStatus(ctx context.Context, key client.ObjectKey, intervals ...interface{}) gomega.AsyncAssertion {
+	return gomega.Eventually(func() (clusterv1.MachineDeploymentStatus, error) {
+		deployment := &clusterv1.MachineDeployment{}
+		if err := e.Client.Get(ctx, key, deployment); err != nil {
+			return clusterv1.MachineDeploymentStatus{}, err
+		}
+		return deployment.Status, nil
+	}, intervals...)
+}
+
+// EventuallyWorkloadNodes returns an assertion polling the number of Nodes in the workload cluster.
+func (e *Environment) EventuallyWorkloadNodes(ctx context.Context, intervals ...interface{}) gomega.AsyncAssertion {
+	return gomega.Eventually(func() (int, error) {
+		nodes := &corev1.NodeList{}
+		if err := e.WorkloadClient.List(ctx, nodes); err != nil {
+			return 0, err
+		}
+		return len(nodes.Items), nil
+	}, intervals...)
+}
@@ -18,6 +18,7 @@ package v1alpha2
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 )
 
 const (
@@ -28,8 +29,44 @@ const (
 
 // DockerClusterSpec defines the desired state of DockerCluster.
 type DockerClusterSpec struct {
-	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// Network is the name of the docker network the cluster load balancer and the cluster machines
+	// are attached to. If not specified, the default bridge network is used.
+	// +optional
+	Network string `json:"network,omitempty"`
+
+	// ControlPlaneEndpoint is the endpoint used to communicate with the control plane.
+	// If the host is not specified, the IP of the load balancer container is used; this requires
+	// the load balancer to be enabled. If the port is not specified, 6443 is used.
+	// +optional
+	ControlPlaneEndpoint APIEndpoint `json:"controlPlaneEndpoint,omitempty"`
+
+	// LoadBalancer configures the load balancer in front of the control plane machines.
+	// +optional
+	LoadBalancer DockerLoadBalancer `json:"loadBalancer,omitempty"`
+}
+
+// DockerLoadBalancer configures the container hosting the load balancer of a DockerCluster.
+type DockerLoadBalancer struct {
+	// Image overrides the image of the load balancer container.
+	// The image must run nginx reading its configuration from /etc/nginx/nginx.conf.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Disabled skips the creation of the load balancer, e.g. for clusters with a single control plane machine.
+	// The control plane endpoint host must then resolve to the control plane machine, e.g. the name of its
+	// container on a user defined network, and the endpoint port must be the API server port.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// ListenAddress is the address of the docker host the load balancer port is published on;
+	// defaults to 0.0.0.0.
+	// +optional
+	ListenAddress string `json:"listenAddress,omitempty"`
+
+	// HostPort is the port of the docker host the load balancer port is published on;
+	// defaults to a random port.
+	// +optional
+	HostPort int32 `json:"hostPort,omitempty"`
 }
 
 // DockerClusterStatus defines the observed state of DockerCluster.
@@ -40,15 +77,29 @@ type DockerClusterStatus struct {
 	// APIEndpoints represents the endpoints to communicate with the control plane.
 	// +optional
 	APIEndpoints []APIEndpoint `json:"apiEndpoints,omitempty"`
+
+	// ErrorReason will be set in the event that there is a terminal problem
+	// reconciling the DockerCluster, like an invalid spec, and will contain
+	// a succinct value suitable for machine interpretation.
+	// +optional
+	ErrorReason *capierrors.ClusterStatusError `json:"errorReason,omitempty"`
+
+	// ErrorMessage will be set in the event that there is a terminal problem
+	// reconciling the DockerCluster and will contain a more verbose string
+	// suitable for logging and human consumption.
+	// +optional
+	ErrorMessage *string `json:"errorMessage,omitempty"`
 }
 
 // APIEndpoint represents a reachable Kubernetes API endpoint.
 type APIEndpoint struct {
 	// Host is the hostname on which the API server is serving.
-	Host string `json:"host"`
+	// +optional
+	Host string `json:"host,omitempty"`
 
 	// Port is the port on which the API server is serving.
-	Port int `json:"port"`
+	// +optional
+	Port int `json:"port,omitempty"`
 }
 
 // +kubebuilder:resource:path=dockerclusters,scope=Namespaced,categories=cluster-api
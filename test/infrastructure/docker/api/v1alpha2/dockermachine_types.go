@@ -17,7 +17,10 @@ limitations under the License.
 package v1alpha2
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 )
 
 const (
@@ -36,12 +39,115 @@ type DockerMachineSpec struct {
 	// running the machine
 	// +optional
 	CustomImage string `json:"customImage,omitempty"`
+
+	// Resources are the CPU and memory limits of the container hosting the machine.
+	// If not specified, the container is not limited.
+	// +optional
+	Resources *DockerMachineResources `json:"resources,omitempty"`
+
+	// ExtraMounts describes additional mount points for the container hosting the machine,
+	// e.g. a containerd image cache shared across machines.
+	// Host paths are paths on the docker host, not on the host running the CAPD controller.
+	// +optional
+	ExtraMounts []Mount `json:"extraMounts,omitempty"`
+
+	// ExtraPortMappings describes additional ports of the container hosting the machine
+	// to be published on the docker host.
+	// +optional
+	ExtraPortMappings []PortMapping `json:"extraPortMappings,omitempty"`
+
+	// Network is the name of the docker network the container hosting the machine is attached to.
+	// The machine must be able to reach the cluster load balancer, so if specified it must be the
+	// network of the DockerCluster; defaults to the network of the DockerCluster.
+	// +optional
+	Network string `json:"network,omitempty"`
+}
+
+// DockerMachineResources are the resource limits of the container hosting a machine.
+type DockerMachineResources struct {
+	// CPU is the maximum amount of CPU the container can use, e.g. "1500m" for one and a half CPUs.
+	// +optional
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+
+	// Memory is the maximum amount of memory the container can use, e.g. "2Gi".
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+}
+
+// Mount specifies a host volume to mount into a container.
+type Mount struct {
+	// ContainerPath is the path of the mount within the container.
+	ContainerPath string `json:"containerPath"`
+
+	// HostPath is the path of the mount on the docker host.
+	HostPath string `json:"hostPath"`
+
+	// ReadOnly specifies whether the mount is read-only.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// PortMappingProtocol is the protocol of a port mapping.
+// +kubebuilder:validation:Enum=TCP;UDP;SCTP
+type PortMappingProtocol string
+
+const (
+	// PortMappingProtocolTCP is the TCP protocol.
+	PortMappingProtocolTCP = PortMappingProtocol("TCP")
+
+	// PortMappingProtocolUDP is the UDP protocol.
+	PortMappingProtocolUDP = PortMappingProtocol("UDP")
+
+	// PortMappingProtocolSCTP is the SCTP protocol.
+	PortMappingProtocolSCTP = PortMappingProtocol("SCTP")
+)
+
+// PortMapping specifies a container port published on the docker host.
+type PortMapping struct {
+	// ContainerPort is the port within the container.
+	ContainerPort int32 `json:"containerPort"`
+
+	// HostPort is the port on the docker host. If not specified, a random port is used.
+	// +optional
+	HostPort int32 `json:"hostPort,omitempty"`
+
+	// ListenAddress is the address on the docker host the port is published on.
+	// If not specified, the port is published on all the addresses.
+	// +optional
+	ListenAddress string `json:"listenAddress,omitempty"`
+
+	// Protocol is the protocol of the port; defaults to TCP.
+	// +optional
+	Protocol PortMappingProtocol `json:"protocol,omitempty"`
 }
 
 // DockerMachineStatus defines the observed state of DockerMachine
 type DockerMachineStatus struct {
 	// Ready denotes that the machine (docker container) is ready
 	Ready bool `json:"ready"`
+
+	// Addresses contains the associated addresses for the docker container hosting the machine.
+	// +optional
+	Addresses []clusterv1.MachineAddress `json:"addresses,omitempty"`
+
+	// ErrorReason will be set in the event that there is a terminal problem
+	// reconciling the Machine and will contain a succinct value suitable
+	// for machine interpretation.
+	//
+	// This field should not be set for transitive errors that a controller
+	// faces that are expected to be fixed automatically over
+	// time (like service outages), but instead indicate that something is
+	// fundamentally wrong with the Machine's spec or the configuration of
+	// the controller, and that manual intervention is required.
+	// +optional
+	ErrorReason *capierrors.MachineStatusError `json:"errorReason,omitempty"`
+
+	// ErrorMessage will be set in the event that there is a terminal problem
+	// reconciling the Machine and will contain a more verbose string suitable
+	// for logging and human consumption. For bootstrap failures it contains the
+	// failing cloud-init command and the tail of its output.
+	// +optional
+	ErrorMessage *string `json:"errorMessage,omitempty"`
 }
 
 // +kubebuilder:resource:path=dockermachines,scope=Namespaced,categories=cluster-api
@@ -0,0 +1,54 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// bootCmd defines a cloud init action that replicates the behavior of the cloud init bootcmd module.
+// Commands are run like runcmd ones; cloud-init runs them earlier, before any other supported module.
+type bootCmd struct {
+	Cmds []Cmd `json:"bootcmd,"`
+}
+
+func newBootCmdAction() action {
+	return &bootCmd{}
+}
+
+// Unmarshal the bootCmd
+func (a *bootCmd) Unmarshal(userData []byte) error {
+	if err := yaml.Unmarshal(userData, a); err != nil {
+		return errors.Wrapf(err, "error parsing bootcmd action: %s", userData)
+	}
+	return nil
+}
+
+// Run the bootCmd
+func (a *bootCmd) Run(cmder exec.Cmder) ([]string, error) {
+	var lines []string
+	for _, c := range a.Cmds {
+		cmdLines, err := runCommand(cmder, nil, c.Cmd, c.Args...)
+		lines = append(lines, cmdLines...)
+		if err != nil {
+			return lines, err
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestBootCmd(t *testing.T) {
+	cloudData := `
+bootcmd:
+- [ modprobe, br_netfilter ]
+- "echo 1 > /proc/sys/net/ipv4/ip_forward"`
+	b := bootCmd{}
+	if err := b.Unmarshal([]byte(cloudData)); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCmds := []Cmd{
+		{Cmd: "modprobe", Args: []string{"br_netfilter"}},
+		{Cmd: "/bin/sh", Args: []string{"-c", "echo 1 > /proc/sys/net/ipv4/ip_forward"}},
+	}
+	if !reflect.DeepEqual(b.Cmds, expectedCmds) {
+		t.Fatalf("Expected %+v commands, found %+v", expectedCmds, b.Cmds)
+	}
+
+	lines, err := b.Run(fakeCmder{t: t})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedLines := []string{
+		fmt.Sprintf("%s modprobe br_netfilter", prompt),
+		"command [modprobe br_netfilter] completed",
+		fmt.Sprintf("%s /bin/sh -c echo 1 > /proc/sys/net/ipv4/ip_forward", prompt),
+		"command [/bin/sh -c echo 1 > /proc/sys/net/ipv4/ip_forward] completed",
+	}
+	if !reflect.DeepEqual(lines, expectedLines) {
+		t.Errorf("expected %s, got %s", expectedLines, lines)
+	}
+}
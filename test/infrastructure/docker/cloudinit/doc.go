@@ -19,5 +19,10 @@ Package cloudinit defines cloud init adapter for kind nodes.
 
 The Adapter supports a limited set of cloud init features, just what is necessary to test CPBPK;
 additionally, for sake of simplicity, the adapter is designed to work on existing kind node images.
+
+Supported modules are bootcmd, write_files, mounts, groups, users, ntp (validated, but not applied
+because containers use the host clock) and runcmd; they are run in the same order cloud-init runs them.
+Cloud configs can be gzip compressed, and can use the "## template: jinja" header to reference the
+local_hostname and instance_id instance data. Other modules are ignored, or rejected in strict mode.
 */
 package cloudinit
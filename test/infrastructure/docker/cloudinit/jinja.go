@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	jinjaHeader = "## template: jinja"
+
+	// cloudName is the name of the cloud exposed to jinja templates.
+	cloudName = "docker"
+)
+
+var (
+	jinjaVariableRegEx  = regexp.MustCompile(`{{\s*([a-zA-Z0-9_.]+)\s*}}`)
+	jinjaStatementRegEx = regexp.MustCompile(`{%|{#`)
+)
+
+// hasJinjaHeader returns true if the cloud config is a jinja template.
+func hasJinjaHeader(cloudConfig []byte) bool {
+	firstLine := bytes.SplitN(bytes.TrimLeft(cloudConfig, " \t\r\n"), []byte("\n"), 2)[0]
+	return string(bytes.TrimSpace(firstLine)) == jinjaHeader
+}
+
+// instanceData returns the subset of the cloud-init instance data available to jinja templates.
+// Like in cloud-init, the v1 keys are also available at the top level.
+func instanceData(hostname string) map[string]string {
+	data := map[string]string{}
+	for k, v := range map[string]string{
+		"local_hostname": hostname,
+		"instance_id":    hostname,
+		"cloud_name":     cloudName,
+		"platform":       cloudName,
+	} {
+		data[k] = v
+		data["v1."+k] = v
+		data["ds.meta_data."+k] = v
+	}
+	return data
+}
+
+// renderJinja renders the variables in a jinja template with the given values.
+// Only plain variable expressions are supported, statements and comments are rejected.
+// Unknown variables are rendered like cloud-init does unless strict is set, in which case they are rejected.
+func renderJinja(cloudConfig []byte, data map[string]string, strict bool) ([]byte, error) {
+	if loc := jinjaStatementRegEx.FindIndex(cloudConfig); loc != nil {
+		return nil, errors.Errorf("unsupported jinja statement or comment in cloud-config at offset %d", loc[0])
+	}
+
+	var missing []string
+	out := jinjaVariableRegEx.ReplaceAllFunc(cloudConfig, func(match []byte) []byte {
+		name := string(jinjaVariableRegEx.FindSubmatch(match)[1])
+		if v, ok := data[name]; ok {
+			return []byte(v)
+		}
+		missing = append(missing, name)
+		return []byte("CI_MISSING_JINJA_VAR/" + name)
+	})
+	if strict && len(missing) > 0 {
+		return nil, errors.Errorf("undefined jinja variables in cloud-config: %v", missing)
+	}
+	return out, nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"testing"
+)
+
+func TestHasJinjaHeader(t *testing.T) {
+	if !hasJinjaHeader([]byte("\n## template: jinja\n#cloud-config\n")) {
+		t.Error("expected the jinja header to be detected")
+	}
+	if hasJinjaHeader([]byte("#cloud-config\n## template: jinja\n")) {
+		t.Error("expected the jinja header to be detected only on the first line")
+	}
+}
+
+func TestRenderJinja(t *testing.T) {
+	var useCases = []struct {
+		name          string
+		cloudConfig   string
+		strict        bool
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:        "known variables",
+			cloudConfig: "name: {{ ds.meta_data.local_hostname }}\nid: {{v1.instance_id}}",
+			expected:    "name: my-node\nid: my-node",
+		},
+		{
+			name:        "unknown variables",
+			cloudConfig: "region: {{ v1.region }}",
+			expected:    "region: CI_MISSING_JINJA_VAR/v1.region",
+		},
+		{
+			name:          "unknown variables in strict mode",
+			cloudConfig:   "region: {{ v1.region }}",
+			strict:        true,
+			expectedError: true,
+		},
+		{
+			name:          "statements",
+			cloudConfig:   "{% if v1.region %}region: {{ v1.region }}{% endif %}",
+			expectedError: true,
+		},
+	}
+
+	for _, rt := range useCases {
+		t.Run(rt.name, func(t *testing.T) {
+			out, err := renderJinja([]byte(rt.cloudConfig), instanceData("my-node"), rt.strict)
+			if err == nil && rt.expectedError {
+				t.Fatal("expected error, got nil")
+			}
+			if err != nil {
+				if !rt.expectedError {
+					t.Fatalf("expected nil, got error %v", err)
+				}
+				return
+			}
+			if string(out) != rt.expected {
+				t.Errorf("expected %q, got %q", rt.expected, out)
+			}
+		})
+	}
+}
@@ -19,7 +19,9 @@ package cloudinit
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/ghodss/yaml"
@@ -29,21 +31,67 @@ import (
 
 const (
 	// Supported cloud config modules
+	bootcmd    = "bootcmd"
 	writefiles = "write_files"
+	mounts     = "mounts"
+	groups     = "groups"
+	users      = "users"
+	ntp        = "ntp"
 	runcmd     = "runcmd"
 )
 
-type actionFactory struct{}
+// moduleOrder is the order in which cloud-init runs the supported modules, independently of
+// the order they appear in the cloud config; see the cloud_init_modules and cloud_config_modules
+// sections of the default /etc/cloud/cloud.cfg. Unknown modules run last.
+var moduleOrder = []string{bootcmd, writefiles, mounts, groups, users, ntp, runcmd}
 
-func (a *actionFactory) action(name string) action {
+// Options configures how a cloud config is run.
+type Options struct {
+	// Strict rejects cloud configs using modules that are not supported by the adapter,
+	// instead of ignoring them.
+	Strict bool
+
+	// Hostname is the hostname of the node, exposed to cloud configs using the
+	// "## template: jinja" header as local_hostname and instance_id.
+	Hostname string
+}
+
+// UnsupportedModuleError is returned in strict mode when a cloud config uses a
+// module that is not supported by the adapter.
+type UnsupportedModuleError struct {
+	Module string
+}
+
+func (e *UnsupportedModuleError) Error() string {
+	return fmt.Sprintf("cloud config module %q is not supported", e.Module)
+}
+
+type actionFactory struct {
+	strict bool
+}
+
+func (a *actionFactory) action(name string) (action, error) {
 	switch name {
+	case bootcmd:
+		return newBootCmdAction(), nil
 	case writefiles:
-		return newWriteFilesAction()
+		return newWriteFilesAction(), nil
+	case mounts:
+		return newMountsAction(), nil
+	case groups:
+		return newGroupsAction(), nil
+	case users:
+		return newUsersAction(), nil
+	case ntp:
+		return newNTPAction(), nil
 	case runcmd:
-		return newRunCmdAction()
+		return newRunCmdAction(), nil
 	default:
+		if a.strict {
+			return nil, errors.WithStack(&UnsupportedModuleError{Module: name})
+		}
 		// TODO Add a logger during the refactor and log this unknown module
-		return newUnknown(name)
+		return newUnknown(name), nil
 	}
 }
 
@@ -53,14 +101,32 @@ type action interface {
 }
 
 // Run the given userData (a cloud config script) on the given node
-func Run(cloudConfig []byte, cmder exec.Cmder) ([]string, error) {
+func Run(cloudConfig []byte, cmder exec.Cmder, opts Options) ([]string, error) {
+	// cloud-init accepts gzip compressed user data
+	if isGzip(cloudConfig) {
+		data, err := gUnzipData(cloudConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decompress cloud-config")
+		}
+		cloudConfig = data
+	}
+
+	// render jinja templates, identified by their header
+	if hasJinjaHeader(cloudConfig) {
+		data, err := renderJinja(cloudConfig, instanceData(opts.Hostname), opts.Strict)
+		if err != nil {
+			return nil, err
+		}
+		cloudConfig = data
+	}
+
 	// validate cloudConfigScript is a valid yaml, as required by the cloud config specification
 	if err := yaml.Unmarshal(cloudConfig, &map[string]interface{}{}); err != nil {
 		return nil, errors.Wrapf(err, "cloud-config is not valid yaml")
 	}
 
 	// parse the cloud config yaml into a slice of cloud config actions.
-	actions, err := getActions(cloudConfig)
+	actions, err := getActions(cloudConfig, opts.Strict)
 	if err != nil {
 		return nil, err
 	}
@@ -68,9 +134,9 @@ func Run(cloudConfig []byte, cmder exec.Cmder) ([]string, error) {
 	// executes all the actions in order
 	var lines []string
 	for _, a := range actions {
-		actionLines, err := a.Run(cmder)
+		actionLines, err := a.action.Run(cmder)
 		if err != nil {
-			return actionLines, err
+			return append(lines, actionLines...), err
 		}
 		lines = append(lines, actionLines...)
 	}
@@ -78,15 +144,35 @@ func Run(cloudConfig []byte, cmder exec.Cmder) ([]string, error) {
 	return lines, nil
 }
 
-// getActions parses the cloud config yaml into a slice of actions to run.
-// Parsing manually is required because the order of the cloud config's actions must be maintained.
-func getActions(userData []byte) ([]action, error) {
+// namedAction is an action together with the cloud config module it was parsed from.
+type namedAction struct {
+	module string
+	action action
+}
+
+func moduleIndex(module string) int {
+	for i, m := range moduleOrder {
+		if m == module {
+			return i
+		}
+	}
+	return len(moduleOrder)
+}
+
+func isGzip(data []byte) bool {
+	return len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// getActions parses the cloud config yaml into a slice of actions to run, sorted in the order cloud-init runs them.
+// Parsing manually is required because the order of the cloud config's actions must be maintained for
+// modules that cloud-init runs in the same stage.
+func getActions(userData []byte, strict bool) ([]namedAction, error) {
 	actionRegEx := regexp.MustCompile(`^[a-zA-Z_]*:`)
 	lines := make([]string, 0)
-	actions := make([]action, 0)
-	actionFactory := &actionFactory{}
+	actions := make([]namedAction, 0)
+	actionFactory := &actionFactory{strict: strict}
 
-	var act action
+	var act *namedAction
 
 	// scans the file searching for keys/top level actions.
 	scanner := bufio.NewScanner(bytes.NewReader(userData))
@@ -97,16 +183,20 @@ func getActions(userData []byte) ([]action, error) {
 			// converts the file fragment scanned up to now into the current action, if any
 			if act != nil {
 				actionBlock := strings.Join(lines, "\n")
-				if err := act.Unmarshal([]byte(actionBlock)); err != nil {
+				if err := act.action.Unmarshal([]byte(actionBlock)); err != nil {
 					return nil, errors.WithStack(err)
 				}
-				actions = append(actions, act)
+				actions = append(actions, *act)
 				lines = lines[:0]
 			}
 
 			// creates the new action
-			actionName := strings.TrimSuffix(line, ":")
-			act = actionFactory.action(actionName)
+			actionName := strings.TrimSpace(strings.SplitN(line, ":", 2)[0])
+			a, err := actionFactory.action(actionName)
+			if err != nil {
+				return nil, err
+			}
+			act = &namedAction{module: actionName, action: a}
 		}
 
 		lines = append(lines, line)
@@ -115,11 +205,15 @@ func getActions(userData []byte) ([]action, error) {
 	// converts the last file fragment scanned into the current action, if any
 	if act != nil {
 		actionBlock := strings.Join(lines, "\n")
-		if err := act.Unmarshal([]byte(actionBlock)); err != nil {
+		if err := act.action.Unmarshal([]byte(actionBlock)); err != nil {
 			return nil, errors.WithStack(err)
 		}
-		actions = append(actions, act)
+		actions = append(actions, *act)
 	}
 
+	sort.SliceStable(actions, func(i, j int) bool {
+		return moduleIndex(actions[i].module) < moduleIndex(actions[j].module)
+	})
+
 	return actions, scanner.Err()
 }
@@ -18,8 +18,11 @@ package cloudinit
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/pkg/errors"
 )
 
 func TestRealUseCase(t *testing.T) {
@@ -138,7 +141,7 @@ write_files:
 	}
 
 	cmder := fakeCmder{t: t}
-	lines, err := Run(cloudData, cmder)
+	lines, err := Run(cloudData, cmder, Options{})
 	if err != nil {
 		t.Fatalf("Run returned unexpected errors %v", err)
 	}
@@ -155,3 +158,71 @@ write_files:
 		}
 	}
 }
+
+func TestRunModules(t *testing.T) {
+	cloudData := []byte(`## template: jinja
+#cloud-config
+runcmd:
+- kubeadm join --node-name {{ ds.meta_data.local_hostname }}
+ntp:
+  servers:
+  - time.google.com
+bootcmd:
+- [ modprobe, br_netfilter ]`)
+
+	expectedlinesStarts := []string{
+		// bootcmd runs first, independently of its position in the cloud config
+		fmt.Sprintf("%s modprobe br_netfilter", prompt),
+		"command [modprobe br_netfilter] completed",
+		fmt.Sprintf("%s # skipping ntp configuration", prompt),
+		fmt.Sprintf("%s /bin/sh -c kubeadm join --node-name my-node", prompt),
+	}
+
+	lines, err := Run(cloudData, fakeCmder{t: t}, Options{Hostname: "my-node"})
+	if err != nil {
+		t.Fatalf("Run returned unexpected errors %v", err)
+	}
+	if len(lines) < len(expectedlinesStarts) {
+		t.Fatalf("Expected at least %d lines, got %q", len(expectedlinesStarts), lines)
+	}
+	for i, s := range expectedlinesStarts {
+		if !strings.HasPrefix(lines[i], s) {
+			t.Fatalf("Expected line %d starting with %s, got %s", i, s, lines[i])
+		}
+	}
+
+	// gzip compressed cloud configs are supported
+	gzipped, err := gZipData(cloudData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzippedLines, err := Run(gzipped, fakeCmder{t: t}, Options{Hostname: "my-node"})
+	if err != nil {
+		t.Fatalf("Run returned unexpected errors %v", err)
+	}
+	if !reflect.DeepEqual(lines, gzippedLines) {
+		t.Fatalf("Expected %q, got %q", lines, gzippedLines)
+	}
+}
+
+func TestRunStrict(t *testing.T) {
+	cloudData := []byte(`#cloud-config
+disk_setup:
+  /dev/sdb:
+    table_type: gpt
+runcmd:
+- [ ls ]`)
+
+	if _, err := Run(cloudData, fakeCmder{t: t}, Options{}); err != nil {
+		t.Fatalf("Run returned unexpected errors %v", err)
+	}
+
+	_, err := Run(cloudData, fakeCmder{t: t}, Options{Strict: true})
+	unsupported, ok := errors.Cause(err).(*UnsupportedModuleError)
+	if !ok {
+		t.Fatalf("Expected an *UnsupportedModuleError, got %v", err)
+	}
+	if unsupported.Module != "disk_setup" {
+		t.Fatalf("Expected the unsupported module to be disk_setup, got %s", unsupported.Module)
+	}
+}
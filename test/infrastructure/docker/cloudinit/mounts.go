@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+const fstab = "/etc/fstab"
+
+// defaultMountFields are the values used by cloud-init for the fields omitted in a mounts entry,
+// in the fstab order: device, mount point, filesystem type, options, dump, pass.
+var defaultMountFields = []string{"", "", "auto", "defaults,nofail,x-systemd.requires=cloud-init.service", "0", "2"}
+
+// mountsAction defines a cloud init action that replicates the behavior of the cloud init mounts module.
+// Entries are lists of strings, numbers or nulls, where null stands for the default value.
+type mountsAction struct {
+	Mounts [][]interface{} `json:"mounts,"`
+}
+
+func newMountsAction() action {
+	return &mountsAction{}
+}
+
+// Unmarshal the mountsAction
+func (a *mountsAction) Unmarshal(userData []byte) error {
+	if err := yaml.Unmarshal(userData, a); err != nil {
+		return errors.Wrapf(err, "error parsing mounts action: %s", userData)
+	}
+	for _, m := range a.Mounts {
+		if len(m) > len(defaultMountFields) {
+			return errors.Errorf("invalid mounts action: entry %v has more than %d fields", m, len(defaultMountFields))
+		}
+	}
+	return nil
+}
+
+// Run the mountsAction
+// Entries are added to /etc/fstab and mounted; entries without a mount point and swap entries are skipped,
+// the latter because kind nodes run with swap disabled.
+func (a *mountsAction) Run(cmder exec.Cmder) ([]string, error) {
+	var lines []string
+	var entries []string
+	for _, m := range a.Mounts {
+		fields := mountFields(m)
+		if fields[0] == "" || fields[1] == "" || fields[1] == "none" {
+			lines = append(lines, fmt.Sprintf("%s # skipping mount entry %v without a mount point", prompt, fields))
+			continue
+		}
+		if fields[1] == "swap" || fields[2] == "swap" {
+			lines = append(lines, fmt.Sprintf("%s # skipping swap mount entry %v", prompt, fields))
+			continue
+		}
+
+		cmdLines, err := runCommand(cmder, nil, "mkdir", "-p", fields[1])
+		lines = append(lines, cmdLines...)
+		if err != nil {
+			return lines, err
+		}
+		entries = append(entries, strings.Join(fields, "\t")+"\n")
+	}
+	if len(entries) == 0 {
+		return lines, nil
+	}
+
+	cmdLines, err := runCommand(cmder, strings.NewReader(strings.Join(entries, "")), "/bin/sh", "-c", fmt.Sprintf("cat >> %s", fstab))
+	lines = append(lines, cmdLines...)
+	if err != nil {
+		return lines, err
+	}
+
+	cmdLines, err = runCommand(cmder, nil, "mount", "-a")
+	lines = append(lines, cmdLines...)
+	return lines, err
+}
+
+// mountFields returns the fstab fields for a mounts entry, filling the omitted ones with the defaults.
+func mountFields(m []interface{}) []string {
+	fields := make([]string, len(defaultMountFields))
+	copy(fields, defaultMountFields)
+	for i, f := range m {
+		if f != nil {
+			fields[i] = fmt.Sprint(f)
+		}
+	}
+
+	// like cloud-init, short device names refer to /dev and entries are tagged as managed by cloud config
+	if d := fields[0]; d != "" && !strings.ContainsAny(d, "/:=") {
+		fields[0] = "/dev/" + d
+	}
+	if fields[3] != "" && !strings.Contains(fields[3], "comment=cloudconfig") {
+		fields[3] += ",comment=cloudconfig"
+	}
+	return fields
+}
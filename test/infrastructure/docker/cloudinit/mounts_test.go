@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMounts(t *testing.T) {
+	cloudData := `
+mounts:
+- [ sdb, /data, ext4, "defaults", 0, 2 ]
+- [ "nfs:/export", /mnt/nfs ]
+- [ swap, none, swap, sw, 0, 0 ]
+- [ sdc, null ]`
+	m := &mountsAction{}
+	if err := m.Unmarshal([]byte(cloudData)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := m.Run(fakeCmder{t: t})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedLines := []string{
+		fmt.Sprintf("%s mkdir -p /data", prompt),
+		"command [mkdir -p /data] completed",
+		fmt.Sprintf("%s mkdir -p /mnt/nfs", prompt),
+		"command [mkdir -p /mnt/nfs] completed",
+		fmt.Sprintf("%s # skipping mount entry [/dev/swap none swap sw,comment=cloudconfig 0 0] without a mount point", prompt),
+		fmt.Sprintf("%s # skipping mount entry [/dev/sdc  auto defaults,nofail,x-systemd.requires=cloud-init.service,comment=cloudconfig 0 2] without a mount point", prompt),
+		fmt.Sprintf("%s /bin/sh -c cat >> /etc/fstab", prompt),
+		"command [/bin/sh -c cat >> /etc/fstab] completed",
+		fmt.Sprintf("%s mount -a", prompt),
+		"command [mount -a] completed",
+	}
+	if !reflect.DeepEqual(lines, expectedLines) {
+		t.Errorf("expected %q, got %q", expectedLines, lines)
+	}
+}
+
+func TestMountFields(t *testing.T) {
+	var useCases = []struct {
+		name     string
+		entry    []interface{}
+		expected []string
+	}{
+		{
+			name:     "defaults",
+			entry:    []interface{}{"xvdb", "/data"},
+			expected: []string{"/dev/xvdb", "/data", "auto", "defaults,nofail,x-systemd.requires=cloud-init.service,comment=cloudconfig", "0", "2"},
+		},
+		{
+			name:     "labels are not prefixed",
+			entry:    []interface{}{"LABEL=data", "/data", "xfs", "defaults", float64(0), float64(0)},
+			expected: []string{"LABEL=data", "/data", "xfs", "defaults,comment=cloudconfig", "0", "0"},
+		},
+	}
+
+	for _, rt := range useCases {
+		t.Run(rt.name, func(t *testing.T) {
+			if actual := mountFields(rt.entry); !reflect.DeepEqual(actual, rt.expected) {
+				t.Errorf("expected %q, got %q", rt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// ntpClients are the values accepted by the cloud init ntp module for ntp_client.
+var ntpClients = map[string]bool{
+	"":                  true,
+	"auto":              true,
+	"chrony":            true,
+	"ntp":               true,
+	"ntpdate":           true,
+	"systemd-timesyncd": true,
+}
+
+// ntpAction defines a cloud init action for the ntp module.
+// Docker containers share the host clock, so the configuration is validated but not applied.
+type ntpAction struct {
+	NTP *ntpConfig `json:"ntp,"`
+}
+
+type ntpConfig struct {
+	Enabled   *bool    `json:"enabled,omitempty"`
+	NTPClient string   `json:"ntp_client,omitempty"`
+	Servers   []string `json:"servers,omitempty"`
+	Pools     []string `json:"pools,omitempty"`
+}
+
+func newNTPAction() action {
+	return &ntpAction{}
+}
+
+// Unmarshal the ntpAction and validates its configuration
+func (a *ntpAction) Unmarshal(userData []byte) error {
+	if err := yaml.Unmarshal(userData, a); err != nil {
+		return errors.Wrapf(err, "error parsing ntp action: %s", userData)
+	}
+	if a.NTP == nil {
+		return nil
+	}
+	if !ntpClients[a.NTP.NTPClient] {
+		return errors.Errorf("invalid ntp action: unknown ntp_client %q", a.NTP.NTPClient)
+	}
+	for _, s := range append(a.NTP.Servers, a.NTP.Pools...) {
+		if s == "" {
+			return errors.New("invalid ntp action: servers and pools must not contain empty values")
+		}
+	}
+	return nil
+}
+
+// Run the ntpAction
+func (a *ntpAction) Run(_ exec.Cmder) ([]string, error) {
+	if a.NTP == nil || (a.NTP.Enabled != nil && !*a.NTP.Enabled) {
+		return nil, nil
+	}
+	return []string{
+		fmt.Sprintf("%s # skipping ntp configuration with servers %v and pools %v, the container uses the host clock", prompt, a.NTP.Servers, a.NTP.Pools),
+	}, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"testing"
+)
+
+func TestNTP(t *testing.T) {
+	var useCases = []struct {
+		name          string
+		cloudData     string
+		expectedLines int
+		expectedError bool
+	}{
+		{
+			name: "servers",
+			cloudData: `
+ntp:
+  enabled: true
+  servers:
+    - time.google.com`,
+			expectedLines: 1,
+		},
+		{
+			name: "disabled",
+			cloudData: `
+ntp:
+  enabled: false
+  servers:
+    - time.google.com`,
+			expectedLines: 0,
+		},
+		{
+			name: "empty server",
+			cloudData: `
+ntp:
+  servers:
+    - ""`,
+			expectedError: true,
+		},
+		{
+			name: "unknown client",
+			cloudData: `
+ntp:
+  ntp_client: timed`,
+			expectedError: true,
+		},
+	}
+
+	for _, rt := range useCases {
+		t.Run(rt.name, func(t *testing.T) {
+			a := &ntpAction{}
+			err := a.Unmarshal([]byte(rt.cloudData))
+			if err == nil && rt.expectedError {
+				t.Fatal("expected error, got nil")
+			}
+			if err != nil {
+				if !rt.expectedError {
+					t.Fatalf("expected nil, got error %v", err)
+				}
+				return
+			}
+
+			lines, err := a.Run(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(lines) != rt.expectedLines {
+				t.Errorf("expected %d lines, got %s", rt.expectedLines, lines)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package cloudinit
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/ghodss/yaml"
@@ -67,6 +68,20 @@ func (c *Cmd) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// CommandError is returned when a command run as part of the cloud config exits with an error.
+type CommandError struct {
+	// Command is the command line that failed.
+	Command string
+	// Output is the combined output of the failed command.
+	Output []string
+	// Err is the error returned when running the command.
+	Err error
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("error running %q: %v", e.Command, e.Err)
+}
+
 // runCmd defines a cloud init action that replicates the behavior of the cloud init rundcmd module
 type runCmd struct {
 	Cmds []Cmd `json:"runcmd,"`
@@ -91,26 +106,47 @@ func (a *runCmd) Run(cmder exec.Cmder) ([]string, error) {
 		// kubeadm in docker requires to ignore some errors, and this requires to modify the cmd generate by CABPK by default...
 		c = hackKubeadmIgnoreErrors(c)
 
-		// Add a line in the output that mimics the command being issues at the command line
-		lines = append(lines, fmt.Sprintf("%s %s %s", prompt, c.Cmd, strings.Join(c.Args, " ")))
-
-		// Run the command
-		cmd := cmder.Command(c.Cmd, c.Args...)
-		cmdLines, err := exec.CombinedOutputLines(cmd)
-
-		// Add The output lines received
+		cmdLines, err := runCommand(cmder, nil, c.Cmd, c.Args...)
 		lines = append(lines, cmdLines...)
-
-		// If the command failed
 		if err != nil {
-			// Add a line in the output with the error message and exit
-			lines = append(lines, fmt.Sprintf("%s %v", errorPrefix, err))
-			return lines, errors.Wrapf(errors.WithStack(err), "error running %+v", c)
+			return lines, err
 		}
 	}
 	return lines, nil
 }
 
+// runCommand runs a command on the node, feeding it stdin if not nil, and returns output lines
+// that mimic the command being issued at the command line followed by its output.
+// If the command fails, a *CommandError is returned.
+func runCommand(cmder exec.Cmder, stdin io.Reader, name string, args ...string) ([]string, error) {
+	command := strings.TrimSpace(fmt.Sprintf("%s %s", name, strings.Join(args, " ")))
+
+	// Add a line in the output that mimics the command being issues at the command line
+	lines := []string{fmt.Sprintf("%s %s", prompt, command)}
+
+	// Run the command
+	cmd := cmder.Command(name, args...)
+	if stdin != nil {
+		cmd.SetStdin(stdin)
+	}
+	cmdLines, err := exec.CombinedOutputLines(cmd)
+
+	// Add The output lines received
+	lines = append(lines, cmdLines...)
+
+	// If the command failed
+	if err != nil {
+		// Add a line in the output with the error message and exit
+		lines = append(lines, fmt.Sprintf("%s %v", errorPrefix, err))
+		return lines, errors.WithStack(&CommandError{
+			Command: command,
+			Output:  cmdLines,
+			Err:     err,
+		})
+	}
+	return lines, nil
+}
+
 func hackKubeadmIgnoreErrors(c Cmd) Cmd {
 	// case kubeadm commands are defined as a string
 	if c.Cmd == "/bin/sh" && len(c.Args) >= 2 {
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"testing"
 
+	pkgerrors "github.com/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 )
 
@@ -59,6 +60,7 @@ func TestRunCmdRun(t *testing.T) {
 		r             runCmd
 		expectedlines []string
 		expectedError bool
+		failedCommand string
 	}{
 		{
 			name: "two command pass",
@@ -89,6 +91,7 @@ func TestRunCmdRun(t *testing.T) {
 				// there should not be a second command!
 			},
 			expectedError: true,
+			failedCommand: "fail bar",
 		},
 		{
 			name: "second command fails",
@@ -105,6 +108,7 @@ func TestRunCmdRun(t *testing.T) {
 				fmt.Sprintf("%s command fail is failed", errorPrefix),
 			},
 			expectedError: true,
+			failedCommand: "fail qux",
 		},
 		{
 			name: "hack kubeadm ingore errors",
@@ -136,12 +140,24 @@ func TestRunCmdRun(t *testing.T) {
 			if !reflect.DeepEqual(rt.expectedlines, lines) {
 				t.Errorf("expected %s, got %s", rt.expectedlines, lines)
 			}
+
+			if rt.failedCommand != "" {
+				cmdErr, ok := pkgerrors.Cause(err).(*CommandError)
+				if !ok {
+					t.Fatalf("expected a *CommandError, got %T", pkgerrors.Cause(err))
+				}
+				if cmdErr.Command != rt.failedCommand {
+					t.Errorf("expected failed command %q, got %q", rt.failedCommand, cmdErr.Command)
+				}
+			}
 		})
 	}
 }
 
 type fakeCmder struct {
 	t *testing.T
+	// failing are additional command line prefixes that force fakeCmd to fail
+	failing []string
 }
 
 var _ exec.Cmder = &fakeCmder{}
@@ -149,6 +165,9 @@ var _ exec.Cmder = &fakeCmder{}
 func (c fakeCmder) Command(name string, arg ...string) exec.Cmd {
 	line := fmt.Sprintf("%s %s", name, strings.Join(arg, " "))
 	fail := strings.Contains(line, "fail")
+	for _, prefix := range c.failing {
+		fail = fail || strings.HasPrefix(line, prefix)
+	}
 	return &fakeCmd{line: line, fail: fail, t: c.t}
 }
 
@@ -18,8 +18,8 @@ package cloudinit
 
 import (
 	"encoding/json"
+	"strings"
 
-	"github.com/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 )
 
@@ -36,22 +36,20 @@ func newUnknown(module string) action {
 func (u *unknown) Unmarshal(data []byte) error {
 	// try unmarshalling to a slice of strings
 	var s1 []string
-	if err := json.Unmarshal(data, &s1); err != nil {
-		if _, ok := err.(*json.UnmarshalTypeError); !ok {
-			return errors.WithStack(err)
-		}
-	} else {
+	if err := json.Unmarshal(data, &s1); err == nil {
 		u.lines = s1
 		return nil
 	}
 
-	// If it's not a slice of strings it should be one string value
+	// If it's not a slice of strings it could be one string value
 	var s2 string
-	if err := json.Unmarshal(data, &s2); err != nil {
-		return errors.WithStack(err)
+	if err := json.Unmarshal(data, &s2); err == nil {
+		u.lines = []string{s2}
+		return nil
 	}
 
-	u.lines = []string{s2}
+	// Otherwise keep the raw cloud config block
+	u.lines = strings.Split(string(data), "\n")
 	return nil
 }
 
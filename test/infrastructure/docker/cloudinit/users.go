@@ -0,0 +1,323 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+const (
+	// defaultUser is the placeholder cloud-init uses for the distro's default user;
+	// kind node images do not define one, so it is ignored.
+	defaultUser = "default"
+
+	sudoersFile = "/etc/sudoers.d/90-cloud-init-users"
+)
+
+// stringList is a list of strings that can be given either as a yaml list or as a comma separated string.
+type stringList []string
+
+// UnmarshalJSON a stringList
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var s1 []string
+	if err := json.Unmarshal(data, &s1); err == nil {
+		*l = s1
+		return nil
+	}
+
+	var s2 string
+	if err := json.Unmarshal(data, &s2); err != nil {
+		return errors.WithStack(err)
+	}
+	*l = nil
+	for _, s := range strings.Split(s2, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*l = append(*l, s)
+		}
+	}
+	return nil
+}
+
+// user defines a user managed by the cloud init users module.
+type user struct {
+	Name              string     `json:"name,"`
+	Gecos             string     `json:"gecos,omitempty"`
+	Groups            stringList `json:"groups,omitempty"`
+	HomeDir           string     `json:"homedir,omitempty"`
+	Inactive          bool       `json:"inactive,omitempty"`
+	Shell             string     `json:"shell,omitempty"`
+	Passwd            string     `json:"passwd,omitempty"`
+	PrimaryGroup      string     `json:"primary_group,omitempty"`
+	LockPassword      *bool      `json:"lock_passwd,omitempty"`
+	Sudo              sudoRules  `json:"sudo,omitempty"`
+	SSHAuthorizedKeys []string   `json:"ssh_authorized_keys,omitempty"`
+}
+
+// UnmarshalJSON a user
+// It can be either an object or a string with the name of the user.
+func (u *user) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*u = user{Name: name}
+		return nil
+	}
+
+	type plain user
+	p := plain{}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return errors.WithStack(err)
+	}
+	*u = user(p)
+	return nil
+}
+
+func (u *user) homeDir() string {
+	if u.HomeDir != "" {
+		return u.HomeDir
+	}
+	if u.Name == "root" {
+		return "/root"
+	}
+	return path.Join("/home", u.Name)
+}
+
+// sudoRules are the sudo rules of a user; they can be given as a string, a list of strings or false.
+type sudoRules []string
+
+// UnmarshalJSON sudoRules
+func (r *sudoRules) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		if b {
+			return errors.New("sudo must be a string, a list of strings or false")
+		}
+		*r = nil
+		return nil
+	}
+	l := stringList{}
+	if err := json.Unmarshal(data, &l); err != nil {
+		return err
+	}
+	*r = sudoRules(l)
+	return nil
+}
+
+// usersAction defines a cloud init action that replicates the behavior of the cloud init users module.
+type usersAction struct {
+	Users []user `json:"users,"`
+}
+
+func newUsersAction() action {
+	return &usersAction{}
+}
+
+// Unmarshal the usersAction
+func (a *usersAction) Unmarshal(userData []byte) error {
+	if err := yaml.Unmarshal(userData, a); err != nil {
+		return errors.Wrapf(err, "error parsing users action: %s", userData)
+	}
+	for _, u := range a.Users {
+		if u.Name == "" {
+			return errors.New("invalid users action: name is required")
+		}
+	}
+	return nil
+}
+
+// Run the usersAction
+func (a *usersAction) Run(cmder exec.Cmder) ([]string, error) {
+	var lines []string
+	for _, u := range a.Users {
+		if u.Name == defaultUser {
+			continue
+		}
+
+		userLines, err := a.runUser(cmder, u)
+		lines = append(lines, userLines...)
+		if err != nil {
+			return lines, err
+		}
+	}
+	return lines, nil
+}
+
+func (a *usersAction) runUser(cmder exec.Cmder, u user) ([]string, error) {
+	var lines []string
+	run := func(stdin io.Reader, name string, args ...string) error {
+		cmdLines, err := runCommand(cmder, stdin, name, args...)
+		lines = append(lines, cmdLines...)
+		return err
+	}
+
+	// like cloud-init, existing users are not modified but still get their ssh keys and sudo rules
+	if cmder.Command("id", "-u", u.Name).Run() != nil {
+		args := []string{"--create-home"}
+		if u.Gecos != "" {
+			args = append(args, "--comment", u.Gecos)
+		}
+		if u.HomeDir != "" {
+			args = append(args, "--home-dir", u.HomeDir)
+		}
+		if u.Shell != "" {
+			args = append(args, "--shell", u.Shell)
+		}
+		if u.PrimaryGroup != "" {
+			args = append(args, "--gid", u.PrimaryGroup)
+		}
+		if len(u.Groups) > 0 {
+			args = append(args, "--groups", strings.Join(u.Groups, ","))
+		}
+		if u.Passwd != "" {
+			args = append(args, "--password", u.Passwd)
+		}
+		if u.Inactive {
+			args = append(args, "--expiredate", "1970-01-02")
+		}
+		args = append(args, u.Name)
+		if err := run(nil, "useradd", args...); err != nil {
+			return lines, err
+		}
+
+		// cloud-init locks passwords unless lock_passwd is false
+		if u.LockPassword == nil || *u.LockPassword {
+			if err := run(nil, "passwd", "--lock", u.Name); err != nil {
+				return lines, err
+			}
+		}
+	} else {
+		lines = append(lines, fmt.Sprintf("%s # user %s already exists, skipping creation", prompt, u.Name))
+	}
+
+	if len(u.Sudo) > 0 {
+		rules := make([]string, 0, len(u.Sudo))
+		for _, r := range u.Sudo {
+			rules = append(rules, fmt.Sprintf("%s %s\n", u.Name, r))
+		}
+		// the paths are passed as positional arguments, so that the shell never interprets them
+		script := `mkdir -p "$1" && cat >> "$2" && chmod 0440 "$2"`
+		if err := run(strings.NewReader(strings.Join(rules, "")), "/bin/sh", "-c", script, "sh", path.Dir(sudoersFile), sudoersFile); err != nil {
+			return lines, err
+		}
+	}
+
+	if len(u.SSHAuthorizedKeys) > 0 {
+		sshDir := path.Join(u.homeDir(), ".ssh")
+		authorizedKeys := path.Join(sshDir, "authorized_keys")
+		script := `mkdir -p "$1" && cat >> "$2" && chmod 0700 "$1" && chmod 0600 "$2" && chown -R "$3" "$1"`
+		if err := run(strings.NewReader(strings.Join(u.SSHAuthorizedKeys, "\n")+"\n"), "/bin/sh", "-c", script, "sh", sshDir, authorizedKeys, u.Name); err != nil {
+			return lines, err
+		}
+	}
+
+	return lines, nil
+}
+
+// groupsAction defines a cloud init action that replicates the behavior of the groups
+// configuration of the cloud init users module.
+type groupsAction struct {
+	Groups []group `json:"groups,"`
+}
+
+// group defines a group and its members; in the cloud config it can be either the name
+// of the group or a map from the name of the group to its members.
+type group struct {
+	Name    string
+	Members stringList
+}
+
+// UnmarshalJSON a group
+func (g *group) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*g = group{Name: name}
+		return nil
+	}
+
+	m := map[string]stringList{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return errors.WithStack(err)
+	}
+	if len(m) != 1 {
+		return errors.Errorf("expected a single group, got %d", len(m))
+	}
+	for name, members := range m {
+		*g = group{Name: name, Members: members}
+	}
+	return nil
+}
+
+func newGroupsAction() action {
+	return &groupsAction{}
+}
+
+// Unmarshal the groupsAction
+// Groups can also be given as a map of group names to members, which is flattened in a list
+// sorted by name.
+func (a *groupsAction) Unmarshal(userData []byte) error {
+	if err := yaml.Unmarshal(userData, a); err == nil {
+		return nil
+	}
+
+	m := struct {
+		Groups map[string]stringList `json:"groups,"`
+	}{}
+	if err := yaml.Unmarshal(userData, &m); err != nil {
+		return errors.Wrapf(err, "error parsing groups action: %s", userData)
+	}
+	a.Groups = nil
+	for name, members := range m.Groups {
+		a.Groups = append(a.Groups, group{Name: name, Members: members})
+	}
+	sort.Slice(a.Groups, func(i, j int) bool { return a.Groups[i].Name < a.Groups[j].Name })
+	return nil
+}
+
+// Run the groupsAction
+func (a *groupsAction) Run(cmder exec.Cmder) ([]string, error) {
+	var lines []string
+	for _, g := range a.Groups {
+		if cmder.Command("getent", "group", g.Name).Run() != nil {
+			cmdLines, err := runCommand(cmder, nil, "groupadd", g.Name)
+			lines = append(lines, cmdLines...)
+			if err != nil {
+				return lines, err
+			}
+		}
+		for _, m := range g.Members {
+			// like cloud-init, members that do not exist yet are skipped
+			if cmder.Command("id", "-u", m).Run() != nil {
+				lines = append(lines, fmt.Sprintf("%s # user %s does not exist, not adding it to group %s", prompt, m, g.Name))
+				continue
+			}
+			cmdLines, err := runCommand(cmder, nil, "usermod", "--append", "--groups", g.Name, m)
+			lines = append(lines, cmdLines...)
+			if err != nil {
+				return lines, err
+			}
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestUsersUnmarshal(t *testing.T) {
+	cloudData := `
+users:
+- default
+- name: alice
+  groups: docker, wheel
+  sudo: ALL=(ALL) NOPASSWD:ALL
+  lock_passwd: false
+  ssh_authorized_keys:
+  - ssh-rsa AAAA alice@example.com
+- name: bob
+  groups: [ docker ]
+  sudo: false`
+	a := &usersAction{}
+	if err := a.Unmarshal([]byte(cloudData)); err != nil {
+		t.Fatal(err)
+	}
+
+	lockPassword := false
+	expected := []user{
+		{Name: "default"},
+		{
+			Name:              "alice",
+			Groups:            stringList{"docker", "wheel"},
+			Sudo:              sudoRules{"ALL=(ALL) NOPASSWD:ALL"},
+			LockPassword:      &lockPassword,
+			SSHAuthorizedKeys: []string{"ssh-rsa AAAA alice@example.com"},
+		},
+		{Name: "bob", Groups: stringList{"docker"}},
+	}
+	if !reflect.DeepEqual(a.Users, expected) {
+		t.Errorf("expected %+v, got %+v", expected, a.Users)
+	}
+}
+
+func TestUsersRun(t *testing.T) {
+	var useCases = []struct {
+		name          string
+		a             usersAction
+		failing       []string
+		expectedlines []string
+		expectedError bool
+	}{
+		{
+			name: "new user",
+			a: usersAction{
+				Users: []user{
+					{Name: "default"},
+					{Name: "alice", Shell: "/bin/bash", Groups: stringList{"docker", "wheel"}},
+				},
+			},
+			failing: []string{"id -u alice"},
+			expectedlines: []string{
+				fmt.Sprintf("%s useradd --create-home --shell /bin/bash --groups docker,wheel alice", prompt),
+				"command [useradd --create-home --shell /bin/bash --groups docker,wheel alice] completed",
+				fmt.Sprintf("%s passwd --lock alice", prompt),
+				"command [passwd --lock alice] completed",
+			},
+		},
+		{
+			name: "existing user with ssh keys and sudo",
+			a: usersAction{
+				Users: []user{
+					{Name: "bob", Sudo: sudoRules{"ALL=(ALL) ALL"}, SSHAuthorizedKeys: []string{"ssh-rsa AAAA"}},
+				},
+			},
+			expectedlines: []string{
+				fmt.Sprintf("%s # user bob already exists, skipping creation", prompt),
+				fmt.Sprintf(`%s /bin/sh -c mkdir -p "$1" && cat >> "$2" && chmod 0440 "$2" sh /etc/sudoers.d /etc/sudoers.d/90-cloud-init-users`, prompt),
+				`command [/bin/sh -c mkdir -p "$1" && cat >> "$2" && chmod 0440 "$2" sh /etc/sudoers.d /etc/sudoers.d/90-cloud-init-users] completed`,
+				fmt.Sprintf(`%s /bin/sh -c mkdir -p "$1" && cat >> "$2" && chmod 0700 "$1" && chmod 0600 "$2" && chown -R "$3" "$1" sh /home/bob/.ssh /home/bob/.ssh/authorized_keys bob`, prompt),
+				`command [/bin/sh -c mkdir -p "$1" && cat >> "$2" && chmod 0700 "$1" && chmod 0600 "$2" && chown -R "$3" "$1" sh /home/bob/.ssh /home/bob/.ssh/authorized_keys bob] completed`,
+			},
+		},
+		{
+			name: "home dir with shell syntax",
+			a: usersAction{
+				Users: []user{
+					{Name: "carol", HomeDir: "/home/$(reboot)", SSHAuthorizedKeys: []string{"ssh-rsa AAAA"}},
+				},
+			},
+			expectedlines: []string{
+				fmt.Sprintf("%s # user carol already exists, skipping creation", prompt),
+				fmt.Sprintf(`%s /bin/sh -c mkdir -p "$1" && cat >> "$2" && chmod 0700 "$1" && chmod 0600 "$2" && chown -R "$3" "$1" sh /home/$(reboot)/.ssh /home/$(reboot)/.ssh/authorized_keys carol`, prompt),
+				`command [/bin/sh -c mkdir -p "$1" && cat >> "$2" && chmod 0700 "$1" && chmod 0600 "$2" && chown -R "$3" "$1" sh /home/$(reboot)/.ssh /home/$(reboot)/.ssh/authorized_keys carol] completed`,
+			},
+		},
+		{
+			name: "useradd fails",
+			a: usersAction{
+				Users: []user{
+					{Name: "alice"},
+				},
+			},
+			failing: []string{"id -u alice", "useradd"},
+			expectedlines: []string{
+				fmt.Sprintf("%s useradd --create-home alice", prompt),
+				fmt.Sprintf("%s command fail is failed", errorPrefix),
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, rt := range useCases {
+		t.Run(rt.name, func(t *testing.T) {
+			lines, err := rt.a.Run(fakeCmder{t: t, failing: rt.failing})
+
+			if err == nil && rt.expectedError {
+				t.Error("expected error, got nil")
+			}
+			if err != nil && !rt.expectedError {
+				t.Errorf("expected nil, got error %v", err)
+			}
+
+			if !reflect.DeepEqual(rt.expectedlines, lines) {
+				t.Errorf("expected %q, got %q", rt.expectedlines, lines)
+			}
+		})
+	}
+}
+
+func TestGroups(t *testing.T) {
+	var useCases = []struct {
+		name      string
+		cloudData string
+		expected  []group
+	}{
+		{
+			name: "list",
+			cloudData: `
+groups:
+- admins
+- cloud-users: [ alice, bob ]`,
+			expected: []group{
+				{Name: "admins"},
+				{Name: "cloud-users", Members: stringList{"alice", "bob"}},
+			},
+		},
+		{
+			name: "map",
+			cloudData: `
+groups:
+  cloud-users: alice, bob
+  admins: []`,
+			expected: []group{
+				{Name: "admins", Members: stringList{}},
+				{Name: "cloud-users", Members: stringList{"alice", "bob"}},
+			},
+		},
+	}
+
+	for _, rt := range useCases {
+		t.Run(rt.name, func(t *testing.T) {
+			a := &groupsAction{}
+			if err := a.Unmarshal([]byte(rt.cloudData)); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(a.Groups, rt.expected) {
+				t.Errorf("expected %+v, got %+v", rt.expected, a.Groups)
+			}
+		})
+	}
+
+	a := groupsAction{Groups: []group{{Name: "cloud-users", Members: stringList{"alice", "bob"}}}}
+	lines, err := a.Run(fakeCmder{t: t, failing: []string{"getent group cloud-users", "id -u bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedLines := []string{
+		fmt.Sprintf("%s groupadd cloud-users", prompt),
+		"command [groupadd cloud-users] completed",
+		fmt.Sprintf("%s usermod --append --groups cloud-users alice", prompt),
+		"command [usermod --append --groups cloud-users alice] completed",
+		fmt.Sprintf("%s # user bob does not exist, not adding it to group cloud-users", prompt),
+	}
+	if !reflect.DeepEqual(lines, expectedLines) {
+		t.Errorf("expected %q, got %q", expectedLines, lines)
+	}
+}
@@ -152,6 +152,7 @@ func fixEncoding(e string) []string {
 }
 
 func fixContent(content string, encodings []string) (string, error) {
+	// encodings are applied in order, e.g. gzip+base64 content is first base64 decoded and then decompressed
 	for _, e := range encodings {
 		switch e {
 		case "application/base64":
@@ -159,17 +160,16 @@ func fixContent(content string, encodings []string) (string, error) {
 			if err != nil {
 				return content, errors.WithStack(err)
 			}
-			return string(rByte), nil
+			content = string(rByte)
 		case "application/x-gzip":
 			rByte, err := gUnzipData([]byte(content))
 			if err != nil {
 				return content, err
 			}
-			return string(rByte), nil
+			content = string(rByte)
 		case "text/plain":
-			return content, nil
 		default:
-			return content, errors.Errorf("Unknown bootstrap data encoding: %q", content)
+			return content, errors.Errorf("Unknown bootstrap data encoding: %q", e)
 		}
 	}
 	return content, nil
@@ -19,6 +19,7 @@ package cloudinit
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/base64"
 	"fmt"
 	"reflect"
 	"testing"
@@ -196,6 +197,12 @@ func TestFixContent(t *testing.T) {
 			encoding:        "gzip",
 			expectedContent: v,
 		},
+		{
+			name:            "gzip+base64 data",
+			content:         base64.StdEncoding.EncodeToString(gv),
+			encoding:        "gzip+base64",
+			expectedContent: v,
+		},
 	}
 
 	for _, rt := range useCases {
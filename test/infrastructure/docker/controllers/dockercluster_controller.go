@@ -26,6 +26,7 @@ import (
 	"sigs.k8s.io/cluster-api/test/infrastructure/docker/docker"
 	"sigs.k8s.io/cluster-api/test/infrastructure/docker/third_party/forked/loadbalancer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -47,6 +48,8 @@ type DockerClusterReconciler struct {
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=dockerclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=dockerclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=dockermachines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;machines,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a DockerCluster object and makes changes based on the state read
 // and what is in the DockerCluster.Spec
@@ -76,7 +79,7 @@ func (r *DockerClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 	log = log.WithValues("cluster", cluster.Name)
 
 	// Create a helper for managing a docker container hosting the loadbalancer.
-	externalLoadBalancer, err := docker.NewLoadBalancer(cluster.Name, log)
+	externalLoadBalancer, err := docker.NewLoadBalancer(cluster.Name, loadBalancerOptions(dockerCluster), log)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the externalLoadBalancer")
 	}
@@ -102,33 +105,58 @@ func (r *DockerClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 	}
 
 	// Handle non-deleted clusters
-	return reconcileNormal(dockerCluster, externalLoadBalancer)
+	return reconcileNormal(dockerCluster, externalLoadBalancer, log)
 }
 
-func reconcileNormal(dockerCluster *infrav1.DockerCluster, externalLoadBalancer *docker.LoadBalancer) (ctrl.Result, error) {
+func reconcileNormal(dockerCluster *infrav1.DockerCluster, externalLoadBalancer *docker.LoadBalancer, log logr.Logger) (ctrl.Result, error) {
 	// If the DockerCluster doesn't have finalizer, add it.
 	if !util.Contains(dockerCluster.Finalizers, infrav1.ClusterFinalizer) {
 		dockerCluster.Finalizers = append(dockerCluster.Finalizers, infrav1.ClusterFinalizer)
 	}
 
-	//Create the docker container hosting the load balancer
-	if err := externalLoadBalancer.Create(); err != nil {
-		return ctrl.Result{}, errors.Wrap(err, "failed to create load balancer")
+	// an invalid spec requires the user to fix it, so report it and wait for the DockerCluster to change
+	if err := validateDockerCluster(dockerCluster); err != nil {
+		log.Error(err, "DockerCluster configuration is not valid")
+		reason := capierrors.InvalidConfigurationClusterError
+		message := err.Error()
+		dockerCluster.Status.ErrorReason = &reason
+		dockerCluster.Status.ErrorMessage = &message
+		return ctrl.Result{}, nil
 	}
+	dockerCluster.Status.ErrorReason = nil
+	dockerCluster.Status.ErrorMessage = nil
 
-	// Set APIEndpoints with the load balancer IP so the Cluster API Cluster Controller can pull it
-	lbip4, err := externalLoadBalancer.IP()
-	if err != nil {
-		return ctrl.Result{}, errors.Wrap(err, "failed to get ip for the load balancer")
+	endpoint := infrav1.APIEndpoint{
+		Host: dockerCluster.Spec.ControlPlaneEndpoint.Host,
+		Port: dockerCluster.Spec.ControlPlaneEndpoint.Port,
+	}
+	if endpoint.Port == 0 {
+		endpoint.Port = loadbalancer.ControlPlanePort
 	}
 
-	dockerCluster.Status.APIEndpoints = []infrav1.APIEndpoint{
-		{
-			Host: lbip4,
-			Port: loadbalancer.ControlPlanePort,
-		},
+	if !dockerCluster.Spec.LoadBalancer.Disabled {
+		//Create the docker container hosting the load balancer
+		if err := externalLoadBalancer.Create(); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to create load balancer")
+		}
+
+		// Make sure the load balancer configuration matches the existing control plane machines
+		if err := externalLoadBalancer.UpdateConfiguration(); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to update load balancer configuration")
+		}
+
+		if endpoint.Host == "" {
+			lbip4, err := externalLoadBalancer.IP()
+			if err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "failed to get ip for the load balancer")
+			}
+			endpoint.Host = lbip4
+		}
 	}
 
+	// Set APIEndpoints so the Cluster API Cluster Controller can pull it
+	dockerCluster.Status.APIEndpoints = []infrav1.APIEndpoint{endpoint}
+
 	// Mark the dockerCluster ready
 	dockerCluster.Status.Ready = true
 
@@ -147,6 +175,37 @@ func reconcileDelete(dockerCluster *infrav1.DockerCluster, externalLoadBalancer
 	return ctrl.Result{}, nil
 }
 
+// validateDockerCluster returns an error if the DockerCluster spec is not valid.
+func validateDockerCluster(dockerCluster *infrav1.DockerCluster) error {
+	spec := dockerCluster.Spec
+	if spec.ControlPlaneEndpoint.Port < 0 || spec.ControlPlaneEndpoint.Port > 65535 {
+		return errors.Errorf("invalid control plane endpoint port %d", spec.ControlPlaneEndpoint.Port)
+	}
+	if spec.LoadBalancer.HostPort < 0 || spec.LoadBalancer.HostPort > 65535 {
+		return errors.Errorf("invalid load balancer host port %d", spec.LoadBalancer.HostPort)
+	}
+	if spec.LoadBalancer.Disabled {
+		if spec.ControlPlaneEndpoint.Host == "" {
+			return errors.New("the control plane endpoint host is required when the load balancer is disabled")
+		}
+		if port := spec.ControlPlaneEndpoint.Port; port != 0 && port != loadbalancer.ControlPlanePort {
+			return errors.Errorf("the control plane endpoint port must be %d when the load balancer is disabled", loadbalancer.ControlPlanePort)
+		}
+	}
+	return nil
+}
+
+// loadBalancerOptions returns the settings of the load balancer container from the DockerCluster spec.
+func loadBalancerOptions(dockerCluster *infrav1.DockerCluster) docker.LoadBalancerOptions {
+	return docker.LoadBalancerOptions{
+		Image:         dockerCluster.Spec.LoadBalancer.Image,
+		Network:       dockerCluster.Spec.Network,
+		Port:          int32(dockerCluster.Spec.ControlPlaneEndpoint.Port),
+		ListenAddress: dockerCluster.Spec.LoadBalancer.ListenAddress,
+		HostPort:      dockerCluster.Spec.LoadBalancer.HostPort,
+	}
+}
+
 // SetupWithManager will add watches for this controller
 func (r *DockerClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -157,5 +216,51 @@ func (r *DockerClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				ToRequests: util.ClusterToInfrastructureMapFunc(infrav1.GroupVersion.WithKind("DockerCluster")),
 			},
 		).
+		Watches(
+			&source.Kind{Type: &infrav1.DockerMachine{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(r.DockerMachineToDockerCluster),
+			},
+		).
 		Complete(r)
 }
+
+// DockerMachineToDockerCluster is a handler.ToRequestsFunc to be used to enqueue requests for
+// reconciliation of the DockerCluster of control plane DockerMachines, so that the load balancer
+// configuration follows the control plane machines as they come and go.
+func (r *DockerClusterReconciler) DockerMachineToDockerCluster(o handler.MapObject) []ctrl.Request {
+	m, ok := o.Object.(*infrav1.DockerMachine)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a DockerMachine but got a %T", o.Object), "failed to get DockerCluster for DockerMachine")
+		return nil
+	}
+	log := r.Log.WithValues("DockerMachine", m.Name, "Namespace", m.Namespace)
+
+	machine, err := util.GetOwnerMachine(context.TODO(), r.Client, m.ObjectMeta)
+	switch {
+	case apierrors.IsNotFound(err) || machine == nil:
+		return nil
+	case err != nil:
+		log.Error(err, "failed to get owning machine")
+		return nil
+	}
+	if !util.IsControlPlaneMachine(machine) {
+		return nil
+	}
+
+	cluster, err := util.GetClusterFromMetadata(context.TODO(), r.Client, machine.ObjectMeta)
+	switch {
+	case apierrors.IsNotFound(err) || cluster == nil:
+		return nil
+	case err != nil:
+		log.Error(err, "failed to get owning cluster")
+		return nil
+	}
+
+	ref := cluster.Spec.InfrastructureRef
+	if ref == nil || ref.Kind != "DockerCluster" || ref.Name == "" {
+		return nil
+	}
+	name := client.ObjectKey{Namespace: cluster.Namespace, Name: ref.Name}
+	return []ctrl.Request{{NamespacedName: name}}
+}
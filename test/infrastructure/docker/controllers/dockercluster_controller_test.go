@@ -0,0 +1,173 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/klogr"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/docker/api/v1alpha2"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func TestDockerClusterReconciler_DockerMachineToDockerCluster(t *testing.T) {
+	clusterName := "my-cluster"
+	cluster := newCluster(clusterName)
+	cluster.Spec.InfrastructureRef = &v1.ObjectReference{
+		Kind: "DockerCluster",
+		Name: "my-docker-cluster",
+	}
+
+	controlPlaneDockerMachine := newDockerMachine("my-docker-machine-0")
+	controlPlaneMachine := newMachine(clusterName, "my-machine-0", controlPlaneDockerMachine)
+	controlPlaneMachine.Labels[clusterv1.MachineControlPlaneLabelName] = "true"
+	controlPlaneDockerMachine.OwnerReferences = machineOwnerReferences(controlPlaneMachine)
+
+	workerDockerMachine := newDockerMachine("my-docker-machine-1")
+	workerMachine := newMachine(clusterName, "my-machine-1", workerDockerMachine)
+	workerDockerMachine.OwnerReferences = machineOwnerReferences(workerMachine)
+
+	orphanDockerMachine := newDockerMachine("my-docker-machine-2")
+
+	c := fake.NewFakeClientWithScheme(setupScheme(), []runtime.Object{
+		cluster,
+		controlPlaneMachine,
+		controlPlaneDockerMachine,
+		workerMachine,
+		workerDockerMachine,
+		orphanDockerMachine,
+	}...)
+	r := DockerClusterReconciler{
+		Client: c,
+		Log:    klogr.New(),
+	}
+
+	tests := []struct {
+		name          string
+		dockerMachine *infrav1.DockerMachine
+		expected      []string
+	}{
+		{
+			name:          "control plane machine",
+			dockerMachine: controlPlaneDockerMachine,
+			expected:      []string{"my-docker-cluster"},
+		},
+		{
+			name:          "worker machine",
+			dockerMachine: workerDockerMachine,
+		},
+		{
+			name:          "machine without owner",
+			dockerMachine: orphanDockerMachine,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := r.DockerMachineToDockerCluster(handler.MapObject{Object: tc.dockerMachine})
+			if len(out) != len(tc.expected) {
+				t.Fatalf("expected %d requests, got %d", len(tc.expected), len(out))
+			}
+			for i := range out {
+				if out[i].Name != tc.expected[i] {
+					t.Errorf("expected request for %s, got %s", tc.expected[i], out[i].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateDockerCluster(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      infrav1.DockerClusterSpec
+		expectErr bool
+	}{
+		{
+			name: "empty spec",
+		},
+		{
+			name: "load balancer settings",
+			spec: infrav1.DockerClusterSpec{
+				ControlPlaneEndpoint: infrav1.APIEndpoint{Port: 7443},
+				LoadBalancer: infrav1.DockerLoadBalancer{
+					Image:         "nginx:1.17-alpine",
+					ListenAddress: "127.0.0.1",
+					HostPort:      7443,
+				},
+			},
+		},
+		{
+			name: "load balancer disabled",
+			spec: infrav1.DockerClusterSpec{
+				ControlPlaneEndpoint: infrav1.APIEndpoint{Host: "my-cluster-controlplane-0"},
+				LoadBalancer:         infrav1.DockerLoadBalancer{Disabled: true},
+			},
+		},
+		{
+			name: "load balancer disabled without endpoint host",
+			spec: infrav1.DockerClusterSpec{
+				LoadBalancer: infrav1.DockerLoadBalancer{Disabled: true},
+			},
+			expectErr: true,
+		},
+		{
+			name: "load balancer disabled with a port other than the API server port",
+			spec: infrav1.DockerClusterSpec{
+				ControlPlaneEndpoint: infrav1.APIEndpoint{Host: "my-cluster-controlplane-0", Port: 7443},
+				LoadBalancer:         infrav1.DockerLoadBalancer{Disabled: true},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid port",
+			spec: infrav1.DockerClusterSpec{
+				ControlPlaneEndpoint: infrav1.APIEndpoint{Port: 70000},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dockerCluster := newDockerCluster("my-cluster", "my-docker-cluster")
+			dockerCluster.Spec = tc.spec
+			err := validateDockerCluster(dockerCluster)
+			if tc.expectErr && err == nil {
+				t.Fatal("expected an error")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func machineOwnerReferences(machine *clusterv1.Machine) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Machine",
+			Name:       machine.Name,
+		},
+	}
+}
@@ -19,14 +19,18 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/docker/api/v1alpha2"
+	"sigs.k8s.io/cluster-api/test/infrastructure/docker/cloudinit"
 	"sigs.k8s.io/cluster-api/test/infrastructure/docker/docker"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -34,16 +38,24 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/container/cri"
 )
 
 const (
 	machineControllerName = "DockerMachine-controller"
+
+	// bootstrapErrorOutputLines is the number of trailing output lines of a failed bootstrap
+	// command that are included in DockerMachine.Status.ErrorMessage.
+	bootstrapErrorOutputLines = 10
 )
 
 // DockerMachineReconciler reconciles a DockerMachine object
 type DockerMachineReconciler struct {
 	client.Client
 	Log logr.Logger
+
+	// StrictCloudInit rejects bootstrap data using cloud-init modules that are not supported by CAPD.
+	StrictCloudInit bool
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=dockermachines,verbs=get;list;watch;create;update;patch;delete
@@ -118,7 +130,7 @@ func (r *DockerMachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 	// NB. the machine controller has to manage the cluster load balancer because the current implementation of the
 	// docker load balancer does not support auto-discovery of control plane nodes, so CAPD should take care of
 	// updating the cluster load balancer configuration when control plane machines are added/removed
-	externalLoadBalancer, err := docker.NewLoadBalancer(cluster.Name, log)
+	externalLoadBalancer, err := docker.NewLoadBalancer(cluster.Name, loadBalancerOptions(dockerCluster), log)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the externalLoadBalancer")
 	}
@@ -140,21 +152,30 @@ func (r *DockerMachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 
 	// Handle deleted machines
 	if !dockerMachine.ObjectMeta.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(machine, dockerMachine, externalMachine, externalLoadBalancer)
+		return r.reconcileDelete(machine, dockerMachine, dockerCluster, externalMachine, externalLoadBalancer)
 	}
 
 	// Handle non-deleted machines
-	return r.reconcileNormal(machine, dockerMachine, externalMachine, externalLoadBalancer, log)
+	return r.reconcileNormal(machine, dockerMachine, dockerCluster, externalMachine, externalLoadBalancer, log)
 }
 
-func (r *DockerMachineReconciler) reconcileNormal(machine *clusterv1.Machine, dockerMachine *infrav1.DockerMachine, externalMachine *docker.Machine, externalLoadBalancer *docker.LoadBalancer, log logr.Logger) (ctrl.Result, error) {
+func (r *DockerMachineReconciler) reconcileNormal(machine *clusterv1.Machine, dockerMachine *infrav1.DockerMachine, dockerCluster *infrav1.DockerCluster, externalMachine *docker.Machine, externalLoadBalancer *docker.LoadBalancer, log logr.Logger) (ctrl.Result, error) {
 	// If the DockerMachine doesn't have finalizer, add it.
 	if !util.Contains(dockerMachine.Finalizers, infrav1.MachineFinalizer) {
 		dockerMachine.Finalizers = append(dockerMachine.Finalizers, infrav1.MachineFinalizer)
 	}
 
-	// if the machine is already provisioned, return
+	// if the machine has failed, there is nothing more to do until it is deleted
+	if dockerMachine.Status.ErrorReason != nil || dockerMachine.Status.ErrorMessage != nil {
+		log.Info("DockerMachine has failed, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+
+	// if the machine is already provisioned, make sure its addresses are reported and return
 	if dockerMachine.Spec.ProviderID != nil {
+		if err := setMachineAddress(dockerMachine, externalMachine); err != nil {
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{}, nil
 	}
 
@@ -170,12 +191,27 @@ func (r *DockerMachineReconciler) reconcileNormal(machine *clusterv1.Machine, do
 		role = constants.ControlPlaneNodeRoleValue
 	}
 
-	if err := externalMachine.Create(role, machine.Spec.Version); err != nil {
+	// an invalid machine spec is not going to fix itself, so report it and stop reconciling the machine
+	opts, err := machineOptions(dockerMachine, dockerCluster)
+	if err != nil {
+		log.Error(err, "DockerMachine configuration is not valid")
+		setMachineError(dockerMachine, capierrors.InvalidConfigurationMachineError, err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	if err := externalMachine.Create(role, machine.Spec.Version, opts); err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "failed to create worker DockerMachine")
 	}
 
+	// report the container addresses as soon as the container exists, so they are available
+	// for troubleshooting even if the bootstrap fails
+	if err := setMachineAddress(dockerMachine, externalMachine); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// if the machine is a control plane added, update the load balancer configuration
-	if util.IsControlPlaneMachine(machine) {
+	// NB. this is done before bootstrapping the machine, so kubeadm can reach the control plane through the load balancer
+	if util.IsControlPlaneMachine(machine) && !dockerCluster.Spec.LoadBalancer.Disabled {
 		if err := externalLoadBalancer.UpdateConfiguration(); err != nil {
 			return ctrl.Result{}, errors.Wrap(err, "failed to update DockerCluster.loadbalancer configuration")
 		}
@@ -184,7 +220,20 @@ func (r *DockerMachineReconciler) reconcileNormal(machine *clusterv1.Machine, do
 	// exec bootstrap
 	// NB. this step is necessary to mimic the behaviour of cloud-init that is embedded in the base images
 	// for other cloud providers
-	if err := externalMachine.ExecBootstrap(*machine.Spec.Bootstrap.Data); err != nil {
+	if err := externalMachine.ExecBootstrap(*machine.Spec.Bootstrap.Data, r.StrictCloudInit); err != nil {
+		// a failed bootstrap command leaves the node in an unknown state, and unsupported bootstrap data is
+		// not going to change, so running the bootstrap again is not going to help; report the failure and
+		// stop reconciling the machine.
+		switch cause := errors.Cause(err).(type) {
+		case *cloudinit.CommandError:
+			log.Error(err, "DockerMachine bootstrap failed")
+			setMachineError(dockerMachine, capierrors.CreateMachineError, bootstrapErrorMessage(cause))
+			return ctrl.Result{}, nil
+		case *cloudinit.UnsupportedModuleError:
+			log.Error(err, "DockerMachine bootstrap data is not supported")
+			setMachineError(dockerMachine, capierrors.InvalidConfigurationMachineError, cause.Error())
+			return ctrl.Result{}, nil
+		}
 		return ctrl.Result{}, errors.Wrap(err, "failed to exec DockerMachine bootstrap")
 	}
 
@@ -204,7 +253,7 @@ func (r *DockerMachineReconciler) reconcileNormal(machine *clusterv1.Machine, do
 	return ctrl.Result{}, nil
 }
 
-func (r *DockerMachineReconciler) reconcileDelete(machine *clusterv1.Machine, dockerMachine *infrav1.DockerMachine, externalMachine *docker.Machine, externalLoadBalancer *docker.LoadBalancer) (ctrl.Result, error) {
+func (r *DockerMachineReconciler) reconcileDelete(machine *clusterv1.Machine, dockerMachine *infrav1.DockerMachine, dockerCluster *infrav1.DockerCluster, externalMachine *docker.Machine, externalLoadBalancer *docker.LoadBalancer) (ctrl.Result, error) {
 	// if the deleted machine is a control-plane node, exec kubeadm reset so the etcd member hosted
 	// on the machine gets removed in a controlled way
 	if util.IsControlPlaneMachine(machine) {
@@ -219,7 +268,7 @@ func (r *DockerMachineReconciler) reconcileDelete(machine *clusterv1.Machine, do
 	}
 
 	// if the deleted machine is a control-plane node, remove it from the load balancer configuration;
-	if util.IsControlPlaneMachine(machine) {
+	if util.IsControlPlaneMachine(machine) && !dockerCluster.Spec.LoadBalancer.Disabled {
 		if err := externalLoadBalancer.UpdateConfiguration(); err != nil {
 			return ctrl.Result{}, errors.Wrap(err, "failed to update DockerCluster.loadbalancer configuration")
 		}
@@ -231,6 +280,107 @@ func (r *DockerMachineReconciler) reconcileDelete(machine *clusterv1.Machine, do
 	return ctrl.Result{}, nil
 }
 
+// setMachineAddress sets the DockerMachine addresses from the docker container hosting the machine.
+func setMachineAddress(dockerMachine *infrav1.DockerMachine, externalMachine *docker.Machine) error {
+	address, err := externalMachine.Address()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the DockerMachine address")
+	}
+
+	dockerMachine.Status.Addresses = []clusterv1.MachineAddress{
+		{
+			Type:    clusterv1.MachineHostName,
+			Address: externalMachine.ContainerName(),
+		},
+		{
+			Type:    clusterv1.MachineInternalIP,
+			Address: address,
+		},
+	}
+	return nil
+}
+
+// machineOptions returns the settings of the docker container hosting the machine from the DockerMachine spec.
+func machineOptions(dockerMachine *infrav1.DockerMachine, dockerCluster *infrav1.DockerCluster) (docker.MachineOptions, error) {
+	spec := dockerMachine.Spec
+	opts := docker.MachineOptions{
+		Network: dockerCluster.Spec.Network,
+	}
+
+	if spec.Network != "" && spec.Network != dockerCluster.Spec.Network &&
+		!(spec.Network == docker.DefaultNetwork && dockerCluster.Spec.Network == "") {
+		return opts, errors.Errorf("network %q does not match the network of DockerCluster %s", spec.Network, dockerCluster.Name)
+	}
+
+	if spec.Resources != nil {
+		if cpu := spec.Resources.CPU; cpu != nil {
+			if cpu.Sign() <= 0 {
+				return opts, errors.Errorf("invalid CPU limit %s: must be greater than zero", cpu)
+			}
+			opts.CPUs = strconv.FormatFloat(float64(cpu.MilliValue())/1000, 'f', -1, 64)
+		}
+		if memory := spec.Resources.Memory; memory != nil {
+			if memory.Sign() <= 0 {
+				return opts, errors.Errorf("invalid memory limit %s: must be greater than zero", memory)
+			}
+			opts.Memory = memory.Value()
+		}
+	}
+
+	for _, m := range spec.ExtraMounts {
+		if m.ContainerPath == "" || m.HostPath == "" {
+			return opts, errors.Errorf("invalid mount %s:%s: both the host and the container path are required", m.HostPath, m.ContainerPath)
+		}
+		opts.Mounts = append(opts.Mounts, cri.Mount{
+			ContainerPath: m.ContainerPath,
+			HostPath:      m.HostPath,
+			Readonly:      m.ReadOnly,
+		})
+	}
+
+	for _, p := range spec.ExtraPortMappings {
+		if p.ContainerPort <= 0 {
+			return opts, errors.Errorf("invalid port mapping for container port %d: must be greater than zero", p.ContainerPort)
+		}
+		mapping := cri.PortMapping{
+			ContainerPort: p.ContainerPort,
+			HostPort:      p.HostPort,
+			ListenAddress: p.ListenAddress,
+		}
+		switch p.Protocol {
+		case "", infrav1.PortMappingProtocolTCP:
+			mapping.Protocol = cri.PortMappingProtocolTCP
+		case infrav1.PortMappingProtocolUDP:
+			mapping.Protocol = cri.PortMappingProtocolUDP
+		case infrav1.PortMappingProtocolSCTP:
+			mapping.Protocol = cri.PortMappingProtocolSCTP
+		default:
+			return opts, errors.Errorf("invalid protocol %q for container port %d", p.Protocol, p.ContainerPort)
+		}
+		opts.PortMappings = append(opts.PortMappings, mapping)
+	}
+
+	return opts, nil
+}
+
+// setMachineError marks the DockerMachine as failed with a terminal error.
+func setMachineError(dockerMachine *infrav1.DockerMachine, reason capierrors.MachineStatusError, message string) {
+	dockerMachine.Status.ErrorReason = &reason
+	dockerMachine.Status.ErrorMessage = &message
+}
+
+// bootstrapErrorMessage describes a failed bootstrap command, including the tail of its output.
+func bootstrapErrorMessage(err *cloudinit.CommandError) string {
+	output := err.Output
+	if len(output) > bootstrapErrorOutputLines {
+		output = output[len(output)-bootstrapErrorOutputLines:]
+	}
+	if len(output) == 0 {
+		return fmt.Sprintf("bootstrap command %q failed: %v", err.Command, err.Err)
+	}
+	return fmt.Sprintf("bootstrap command %q failed: %v\n%s", err.Command, err.Err, strings.Join(output, "\n"))
+}
+
 // SetupWithManager will add watches for this controller
 func (r *DockerMachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -17,17 +17,25 @@ limitations under the License.
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog"
 	"k8s.io/klog/klogr"
 	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/docker/api/v1alpha2"
+	"sigs.k8s.io/cluster-api/test/infrastructure/docker/cloudinit"
+	"sigs.k8s.io/cluster-api/test/infrastructure/docker/docker"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/kind/pkg/container/cri"
 )
 
 func init() {
@@ -81,6 +89,147 @@ func TestDockerMachineReconciler_DockerClusterToDockerMachines(t *testing.T) {
 	}
 }
 
+func TestBootstrapErrorMessage(t *testing.T) {
+	output := make([]string, bootstrapErrorOutputLines+5)
+	for i := range output {
+		output[i] = fmt.Sprintf("line %d", i)
+	}
+
+	tests := []struct {
+		name     string
+		err      *cloudinit.CommandError
+		expected string
+	}{
+		{
+			name: "without output",
+			err: &cloudinit.CommandError{
+				Command: "kubeadm init",
+				Err:     errors.New("exit status 1"),
+			},
+			expected: `bootstrap command "kubeadm init" failed: exit status 1`,
+		},
+		{
+			name: "with output longer than the limit",
+			err: &cloudinit.CommandError{
+				Command: "kubeadm init",
+				Output:  output,
+				Err:     errors.New("exit status 1"),
+			},
+			expected: "bootstrap command \"kubeadm init\" failed: exit status 1\n" + strings.Join(output[5:], "\n"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := bootstrapErrorMessage(tc.err); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMachineOptions(t *testing.T) {
+	cpu := resource.MustParse("1500m")
+	memory := resource.MustParse("2Gi")
+	zero := resource.MustParse("0")
+
+	tests := []struct {
+		name           string
+		spec           infrav1.DockerMachineSpec
+		clusterNetwork string
+		expected       docker.MachineOptions
+		expectErr      bool
+	}{
+		{
+			name:     "empty spec",
+			expected: docker.MachineOptions{},
+		},
+		{
+			name: "resources, mounts and port mappings",
+			spec: infrav1.DockerMachineSpec{
+				Resources: &infrav1.DockerMachineResources{CPU: &cpu, Memory: &memory},
+				ExtraMounts: []infrav1.Mount{
+					{ContainerPath: "/var/lib/containerd", HostPath: "/tmp/containerd-cache", ReadOnly: true},
+				},
+				ExtraPortMappings: []infrav1.PortMapping{
+					{ContainerPort: 80, HostPort: 8080, ListenAddress: "127.0.0.1"},
+					{ContainerPort: 53, Protocol: infrav1.PortMappingProtocolUDP},
+				},
+			},
+			expected: docker.MachineOptions{
+				CPUs:   "1.5",
+				Memory: 2 * 1024 * 1024 * 1024,
+				Mounts: []cri.Mount{
+					{ContainerPath: "/var/lib/containerd", HostPath: "/tmp/containerd-cache", Readonly: true},
+				},
+				PortMappings: []cri.PortMapping{
+					{ContainerPort: 80, HostPort: 8080, ListenAddress: "127.0.0.1", Protocol: cri.PortMappingProtocolTCP},
+					{ContainerPort: 53, Protocol: cri.PortMappingProtocolUDP},
+				},
+			},
+		},
+		{
+			name:           "network inherited from the cluster",
+			clusterNetwork: "capd",
+			expected:       docker.MachineOptions{Network: "capd"},
+		},
+		{
+			name:           "network matching the cluster",
+			spec:           infrav1.DockerMachineSpec{Network: "capd"},
+			clusterNetwork: "capd",
+			expected:       docker.MachineOptions{Network: "capd"},
+		},
+		{
+			name:     "default network",
+			spec:     infrav1.DockerMachineSpec{Network: docker.DefaultNetwork},
+			expected: docker.MachineOptions{},
+		},
+		{
+			name:           "network not matching the cluster",
+			spec:           infrav1.DockerMachineSpec{Network: "other"},
+			clusterNetwork: "capd",
+			expectErr:      true,
+		},
+		{
+			name: "zero CPU limit",
+			spec: infrav1.DockerMachineSpec{
+				Resources: &infrav1.DockerMachineResources{CPU: &zero},
+			},
+			expectErr: true,
+		},
+		{
+			name: "mount without host path",
+			spec: infrav1.DockerMachineSpec{
+				ExtraMounts: []infrav1.Mount{{ContainerPath: "/data"}},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dockerMachine := newDockerMachine("my-docker-machine")
+			dockerMachine.Spec = tc.spec
+			dockerCluster := newDockerCluster("my-cluster", "my-docker-cluster")
+			dockerCluster.Spec.Network = tc.clusterNetwork
+
+			actual, err := machineOptions(dockerMachine, dockerCluster)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, actual)
+			}
+		})
+	}
+}
+
 func contains(haystack []string, needle string) bool {
 	for _, straw := range haystack {
 		if straw == needle {
@@ -17,6 +17,7 @@ limitations under the License.
 package docker
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/go-logr/logr"
@@ -27,15 +28,36 @@ import (
 	"sigs.k8s.io/kind/pkg/container/docker"
 )
 
+// LoadBalancerOptions are the settings of the docker container hosting a load balancer.
+type LoadBalancerOptions struct {
+	// Image is the image of the container; defaults to the nginx image used by kind.
+	Image string
+
+	// Network is the docker network the container is attached to; defaults to the bridge network.
+	Network string
+
+	// Port is the port the load balancer listens on; defaults to the API server port.
+	Port int32
+
+	// ListenAddress is the address of the docker host the load balancer port is published on;
+	// defaults to 0.0.0.0.
+	ListenAddress string
+
+	// HostPort is the port of the docker host the load balancer port is published on;
+	// defaults to a random port.
+	HostPort int32
+}
+
 // LoadBalancer manages the load balancer for a specific docker cluster.
 type LoadBalancer struct {
 	log       logr.Logger
 	name      string
+	opts      LoadBalancerOptions
 	container *nodes.Node
 }
 
 // NewLoadBalancer returns a new helper for managing a docker loadbalancer with a given name.
-func NewLoadBalancer(name string, logger logr.Logger) (*LoadBalancer, error) {
+func NewLoadBalancer(name string, opts LoadBalancerOptions, logger logr.Logger) (*LoadBalancer, error) {
 	if name == "" {
 		return nil, errors.New("name is required when creating a docker.LoadBalancer")
 	}
@@ -43,6 +65,16 @@ func NewLoadBalancer(name string, logger logr.Logger) (*LoadBalancer, error) {
 		return nil, errors.New("logger is required when creating a docker.LoadBalancer")
 	}
 
+	if opts.Image == "" {
+		opts.Image = loadbalancer.Image
+	}
+	if opts.Port == 0 {
+		opts.Port = loadbalancer.ControlPlanePort
+	}
+	if opts.ListenAddress == "" {
+		opts.ListenAddress = "0.0.0.0"
+	}
+
 	container, err := getContainer(
 		withLabel(clusterLabel(name)),
 		withLabel(roleLabel(constants.ExternalLoadBalancerNodeRoleValue)),
@@ -53,6 +85,7 @@ func NewLoadBalancer(name string, logger logr.Logger) (*LoadBalancer, error) {
 
 	return &LoadBalancer{
 		name:      name,
+		opts:      opts,
 		container: container,
 		log:       logger,
 	}, nil
@@ -68,13 +101,16 @@ func (s *LoadBalancer) Create() error {
 	// Create if not exists.
 	if s.container == nil {
 		var err error
-		s.log.Info("Creating load balancer container")
-		s.container, err = nodes.CreateExternalLoadBalancerNode(
+		s.log.Info("Creating load balancer container", "image", s.opts.Image, "network", networkName(s.opts.Network))
+		s.container, err = createNodeWithPort(
 			s.containerName(),
-			loadbalancer.Image,
+			s.opts.Image,
 			clusterLabel(s.name),
-			"0.0.0.0",
-			0,
+			constants.ExternalLoadBalancerNodeRoleValue,
+			s.opts.ListenAddress,
+			s.opts.HostPort,
+			s.opts.Port,
+			MachineOptions{Network: s.opts.Network},
 		)
 		if err != nil {
 			return errors.WithStack(err)
@@ -84,7 +120,8 @@ func (s *LoadBalancer) Create() error {
 	return nil
 }
 
-// UpdateConfiguration updates the external load balancer configuration with new control plane nodes.
+// UpdateConfiguration updates the external load balancer configuration with the current control plane nodes.
+// The load balancer is reloaded only if its configuration changed.
 func (s *LoadBalancer) UpdateConfiguration() error {
 	if s.container == nil {
 		return errors.New("unable to configure load balancer: load balancer container does not exists")
@@ -98,6 +135,11 @@ func (s *LoadBalancer) UpdateConfiguration() error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	if len(controlPlaneNodes) == 0 {
+		// nginx refuses a configuration without backends, so keep the current one until a control plane node shows up
+		s.log.Info("No control plane nodes, skipping load balancer configuration update")
+		return nil
+	}
 
 	var backendServers = map[string]string{}
 	for _, n := range controlPlaneNodes {
@@ -105,19 +147,24 @@ func (s *LoadBalancer) UpdateConfiguration() error {
 		if err != nil {
 			return errors.Wrapf(err, "failed to get IP for container %s", n.Name())
 		}
-		backendServers[n.Name()] = fmt.Sprintf("%s:%d", controlPlaneIPv4, 6443)
+		backendServers[n.Name()] = fmt.Sprintf("%s:%d", controlPlaneIPv4, apiServerPort)
 	}
 
 	// create loadbalancer config data
 	loadbalancerConfig, err := loadbalancer.Config(&loadbalancer.ConfigData{
-		ControlPlanePort: loadbalancer.ControlPlanePort,
+		ControlPlanePort: int(s.opts.Port),
 		BackendServers:   backendServers,
 	})
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	s.log.Info("Updating load balancer configuration")
+	var currentConfig bytes.Buffer
+	if err := s.container.Command("cat", loadbalancer.ConfigPath).SetStdout(&currentConfig).Run(); err == nil && currentConfig.String() == loadbalancerConfig {
+		return nil
+	}
+
+	s.log.Info("Updating load balancer configuration", "backends", len(backendServers))
 	if err := s.container.WriteFile(loadbalancer.ConfigPath, loadbalancerConfig); err != nil {
 		return errors.WithStack(err)
 	}
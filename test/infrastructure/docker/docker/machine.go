@@ -86,8 +86,25 @@ func machineContainerName(cluster, machine string) string {
 	return fmt.Sprintf("%s-%s", cluster, machine)
 }
 
+// Address returns the IP address of the docker container hosting this machine,
+// preferring the IPv4 address if the container has one.
+func (m *Machine) Address() (string, error) {
+	if m.container == nil {
+		return "", errors.New("unable to get address. the container hosting this machine does not exists")
+	}
+
+	ipv4, ipv6, err := m.container.IP()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the IP address of container %s", m.ContainerName())
+	}
+	if ipv4 != "" {
+		return ipv4, nil
+	}
+	return ipv6, nil
+}
+
 // Create creates a docker container hosting a Kubernetes node.
-func (m *Machine) Create(role string, version *string) error {
+func (m *Machine) Create(role string, version *string, opts MachineOptions) error {
 	// Create if not exists.
 	if m.container == nil {
 		var err error
@@ -99,24 +116,22 @@ func (m *Machine) Create(role string, version *string) error {
 
 		switch role {
 		case constants.ControlPlaneNodeRoleValue:
-			m.log.Info("Creating control plane machine container")
-			m.container, err = nodes.CreateControlPlaneNode(
+			m.log.Info("Creating control plane machine container", "network", networkName(opts.Network))
+			m.container, err = createControlPlaneNode(
 				m.ContainerName(),
 				machineImage,
 				clusterLabel(m.cluster),
 				"127.0.0.1",
-				0,
-				nil,
-				nil,
+				opts,
 			)
 		case constants.WorkerNodeRoleValue:
-			m.log.Info("Creating worker machine container")
-			m.container, err = nodes.CreateWorkerNode(
+			m.log.Info("Creating worker machine container", "network", networkName(opts.Network))
+			m.container, err = createNode(
 				m.ContainerName(),
 				machineImage,
 				clusterLabel(m.cluster),
-				nil,
-				nil,
+				constants.WorkerNodeRoleValue,
+				opts,
 			)
 		default:
 			return errors.Errorf("unable to create machine for role %s", role)
@@ -128,8 +143,9 @@ func (m *Machine) Create(role string, version *string) error {
 	return nil
 }
 
-// ExecBootstrap runs bootstrap on a node, this is generally `kubeadm <init|join>`
-func (m *Machine) ExecBootstrap(data string) error {
+// ExecBootstrap runs bootstrap on a node, this is generally `kubeadm <init|join>`.
+// In strict mode, bootstrap data using cloud-init modules not supported by CAPD is rejected.
+func (m *Machine) ExecBootstrap(data string, strict bool) error {
 	if m.container == nil {
 		return errors.New("unable to set ExecBootstrap. the container hosting this machine does not exists")
 	}
@@ -140,10 +156,13 @@ func (m *Machine) ExecBootstrap(data string) error {
 	}
 
 	m.log.Info("Running machine bootstrap scripts")
-	lines, err := cloudinit.Run(cloudConfig, m.container.Cmder())
+	lines, err := cloudinit.Run(cloudConfig, m.container.Cmder(), cloudinit.Options{
+		Strict:   strict,
+		Hostname: m.ContainerName(),
+	})
 	if err != nil {
 		m.log.Error(err, strings.Join(lines, "\n"))
-		return errors.Wrap(err, "failed to join a control plane node with kubeadm")
+		return errors.Wrap(err, "failed to run machine bootstrap scripts")
 	}
 
 	return nil
@@ -0,0 +1,203 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/container/cri"
+	"sigs.k8s.io/kind/pkg/container/docker"
+)
+
+const (
+	// DefaultNetwork is the docker network containers are attached to when no network is specified.
+	DefaultNetwork = "bridge"
+
+	// apiServerPort is the port the API server listens on in control plane containers.
+	apiServerPort = 6443
+)
+
+// MachineOptions are the settings of the docker container hosting a machine.
+type MachineOptions struct {
+	// Network is the docker network the container is attached to; defaults to the bridge network.
+	Network string
+
+	// CPUs is the number of CPUs the container can use, in the format of `docker run --cpus`.
+	CPUs string
+
+	// Memory is the amount of memory in bytes the container can use.
+	Memory int64
+
+	// Mounts are the additional host paths mounted into the container.
+	Mounts []cri.Mount
+
+	// PortMappings are the additional container ports published on the docker host.
+	PortMappings []cri.PortMapping
+}
+
+// runArgs returns the `docker run` arguments applying the options.
+func (o MachineOptions) runArgs() []string {
+	args := []string{"--network", networkName(o.Network)}
+	if o.CPUs != "" {
+		args = append(args, "--cpus", o.CPUs)
+	}
+	if o.Memory > 0 {
+		// Setting the swap limit to the memory limit prevents the container from using swap,
+		// which the kubelet does not support.
+		memory := fmt.Sprintf("%d", o.Memory)
+		args = append(args, "--memory", memory, "--memory-swap", memory)
+	}
+	return args
+}
+
+func networkName(network string) string {
+	if network == "" {
+		return DefaultNetwork
+	}
+	return network
+}
+
+// createNode runs the container for a node with the given role.
+// This is adapted from kind's createNode, adding the ability to attach the container
+// to a given network and to limit its resources.
+func createNode(name, image, clusterLabel, role string, opts MachineOptions, extraArgs ...string) (*nodes.Node, error) {
+	runArgs := []string{
+		"--detach", // run the container detached
+		"--tty",    // allocate a tty for entrypoint logs
+		// running containers in a container requires privileged
+		"--privileged",
+		"--security-opt", "seccomp=unconfined", // also ignore seccomp
+		// runtime temporary storage
+		"--tmpfs", "/tmp", // various things depend on working /tmp
+		"--tmpfs", "/run", // systemd wants a writable /run
+		// runtime persistent storage
+		"--volume", "/var",
+		// some k8s things want to read /lib/modules
+		"--volume", "/lib/modules:/lib/modules:ro",
+		"--hostname", name, // make hostname match container name
+		"--name", name, // ... and set the container name
+		// label the node with the cluster ID
+		"--label", clusterLabel,
+		// label the node with the role ID
+		"--label", roleLabel(role),
+	}
+	runArgs = append(runArgs, opts.runArgs()...)
+
+	// pass proxy environment variables to be used by node's docker daemon
+	proxyEnvs, err := proxyEnvs(networkName(opts.Network))
+	if err != nil {
+		return nil, errors.Wrap(err, "proxy setup error")
+	}
+	for key, val := range proxyEnvs {
+		runArgs = append(runArgs, "-e", fmt.Sprintf("%s=%s", key, val))
+	}
+
+	// adds node specific args
+	runArgs = append(runArgs, extraArgs...)
+
+	if docker.UsernsRemap() {
+		// We need this argument in order to make this command work
+		// in systems that have userns-remap enabled on the docker daemon
+		runArgs = append(runArgs, "--userns=host")
+	}
+
+	err = docker.Run(
+		image,
+		docker.WithRunArgs(runArgs...),
+		docker.WithMounts(opts.Mounts),
+		docker.WithPortMappings(opts.PortMappings),
+	)
+
+	// we should return a handle so the caller can clean it up
+	node := nodes.FromName(name)
+	if err != nil {
+		return node, errors.Wrap(err, "docker run error")
+	}
+	return node, nil
+}
+
+// createControlPlaneNode creates a control plane node, publishing the API server
+// on a random port of the given listen address.
+func createControlPlaneNode(name, image, clusterLabel, listenAddress string, opts MachineOptions) (*nodes.Node, error) {
+	return createNodeWithPort(name, image, clusterLabel, constants.ControlPlaneNodeRoleValue, listenAddress, 0, apiServerPort, opts)
+}
+
+// createNodeWithPort creates a node publishing containerPort on the given port of the given listen address,
+// or on a random port if port is 0.
+func createNodeWithPort(name, image, clusterLabel, role, listenAddress string, port, containerPort int32, opts MachineOptions) (*nodes.Node, error) {
+	if port == 0 {
+		p, err := getPort()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get port")
+		}
+		port = p
+	}
+
+	opts.PortMappings = append(opts.PortMappings, cri.PortMapping{
+		ListenAddress: listenAddress,
+		HostPort:      port,
+		ContainerPort: containerPort,
+	})
+	return createNode(name, image, clusterLabel, role, opts, "--expose", fmt.Sprintf("%d", port))
+}
+
+// getPort returns a free TCP port on the host.
+func getPort() (int32, error) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return int32(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+// proxyEnvs returns the host proxy settings to be passed to the nodes, adding the
+// subnets of the given network to NO_PROXY.
+func proxyEnvs(network string) (map[string]string, error) {
+	envs := map[string]string{}
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"} {
+		val := os.Getenv(name)
+		if val == "" {
+			val = os.Getenv(strings.ToLower(name))
+		}
+		if val != "" {
+			envs[name] = val
+			envs[strings.ToLower(name)] = val
+		}
+	}
+	if len(envs) == 0 {
+		return envs, nil
+	}
+
+	lines, err := docker.NetworkInspect([]string{network}, `{{range (index (index . "IPAM") "Config")}}{{index . "Subnet"}} {{end}}`)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the subnets of network %s", network)
+	}
+	if len(lines) == 0 {
+		return nil, errors.Errorf("failed to get the subnets of network %s", network)
+	}
+	noProxy := strings.Join(append(strings.Fields(lines[0]), envs["NO_PROXY"]), ",")
+	envs["NO_PROXY"] = noProxy
+	envs["no_proxy"] = noProxy
+	return envs, nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMachineOptionsRunArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     MachineOptions
+		expected []string
+	}{
+		{
+			name:     "defaults",
+			expected: []string{"--network", "bridge"},
+		},
+		{
+			name: "network and resource limits",
+			opts: MachineOptions{
+				Network: "capd",
+				CPUs:    "1.5",
+				Memory:  1024,
+			},
+			expected: []string{"--network", "capd", "--cpus", "1.5", "--memory", "1024", "--memory-swap", "1024"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.opts.runArgs(); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -50,11 +50,14 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var syncPeriod time.Duration
+	var strictCloudInit bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
+	flag.BoolVar(&strictCloudInit, "strict-cloud-init", false,
+		"Reject bootstrap data using cloud-init modules that are not supported by the docker provider, instead of ignoring them.")
 	flag.Parse()
 
 	ctrl.SetLogger(klogr.New())
@@ -72,8 +75,9 @@ func main() {
 	}
 
 	if err := (&controllers.DockerMachineReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("DockerMachine"),
+		Client:          mgr.GetClient(),
+		Log:             ctrl.Log.WithName("controllers").WithName("DockerMachine"),
+		StrictCloudInit: strictCloudInit,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "reconciler")
 		os.Exit(1)
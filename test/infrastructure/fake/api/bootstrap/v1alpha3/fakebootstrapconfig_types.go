@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/infrastructure/v1alpha3"
+)
+
+// FakeBootstrapConfigSpec defines the desired state of FakeBootstrapConfig.
+type FakeBootstrapConfigSpec struct {
+	infrav1.FakeBehavior `json:",inline"`
+}
+
+// FakeBootstrapConfigStatus defines the observed state of FakeBootstrapConfig.
+type FakeBootstrapConfigStatus struct {
+	// Ready indicates the BootstrapData field is ready to be consumed.
+	Ready bool `json:"ready,omitempty"`
+
+	// BootstrapData is a placeholder cloud-config naming the FakeBootstrapConfig.
+	// +optional
+	BootstrapData []byte `json:"bootstrapData,omitempty"`
+
+	// ErrorReason is set from the injected failure, if any.
+	// +optional
+	ErrorReason string `json:"errorReason,omitempty"`
+
+	// ErrorMessage is set from the injected failure, if any.
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// +kubebuilder:resource:path=fakebootstrapconfigs,scope=Namespaced,categories=cluster-api
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:object:root=true
+
+// FakeBootstrapConfig is the Schema for the fakebootstrapconfigs API
+type FakeBootstrapConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FakeBootstrapConfigSpec   `json:"spec,omitempty"`
+	Status FakeBootstrapConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FakeBootstrapConfigList contains a list of FakeBootstrapConfig
+type FakeBootstrapConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FakeBootstrapConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FakeBootstrapConfig{}, &FakeBootstrapConfigList{})
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FakeBootstrapConfigTemplateSpec defines the desired state of FakeBootstrapConfigTemplate.
+type FakeBootstrapConfigTemplateSpec struct {
+	Template FakeBootstrapConfigTemplateResource `json:"template"`
+}
+
+// FakeBootstrapConfigTemplateResource defines the Template structure.
+type FakeBootstrapConfigTemplateResource struct {
+	Spec FakeBootstrapConfigSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:resource:path=fakebootstrapconfigtemplates,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:object:root=true
+
+// FakeBootstrapConfigTemplate is the Schema for the fakebootstrapconfigtemplates API
+type FakeBootstrapConfigTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FakeBootstrapConfigTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FakeBootstrapConfigTemplateList contains a list of FakeBootstrapConfigTemplate
+type FakeBootstrapConfigTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FakeBootstrapConfigTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FakeBootstrapConfigTemplate{}, &FakeBootstrapConfigTemplateList{})
+}
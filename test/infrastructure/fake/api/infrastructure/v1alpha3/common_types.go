@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FakeBehavior configures how the fake controllers reconcile an object.
+type FakeBehavior struct {
+	// ProvisioningDelay is how long after its creation the object is marked ready.
+	// If not specified, the object is marked ready right away.
+	// +optional
+	ProvisioningDelay *metav1.Duration `json:"provisioningDelay,omitempty"`
+
+	// Failure, if set, is reported as a terminal failure in the object status and the
+	// object is never marked ready. It can be set at any time to inject a failure.
+	// +optional
+	Failure *FakeFailure `json:"failure,omitempty"`
+}
+
+// FakeFailure is a terminal failure reported by a fake object.
+type FakeFailure struct {
+	// Reason is reported as the errorReason of the object status.
+	Reason string `json:"reason"`
+
+	// Message is reported as the errorMessage of the object status.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// APIEndpoint represents a reachable Kubernetes API endpoint.
+type APIEndpoint struct {
+	// Host is the hostname on which the API server is serving.
+	Host string `json:"host"`
+
+	// Port is the port on which the API server is serving.
+	Port int `json:"port"`
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FakeClusterSpec defines the desired state of FakeCluster.
+type FakeClusterSpec struct {
+	FakeBehavior `json:",inline"`
+
+	// APIEndpoint is the control plane endpoint reported once the FakeCluster is ready,
+	// e.g. the address of an envtest API server acting as the workload cluster.
+	// Defaults to 127.0.0.1:6443.
+	// +optional
+	APIEndpoint *APIEndpoint `json:"apiEndpoint,omitempty"`
+}
+
+// FakeClusterStatus defines the observed state of FakeCluster.
+type FakeClusterStatus struct {
+	// Ready denotes that the fake cluster infrastructure is ready.
+	Ready bool `json:"ready"`
+
+	// APIEndpoints represents the endpoints to communicate with the control plane.
+	// +optional
+	APIEndpoints []APIEndpoint `json:"apiEndpoints,omitempty"`
+
+	// ErrorReason is set from the injected failure, if any.
+	// +optional
+	ErrorReason *string `json:"errorReason,omitempty"`
+
+	// ErrorMessage is set from the injected failure, if any.
+	// +optional
+	ErrorMessage *string `json:"errorMessage,omitempty"`
+}
+
+// +kubebuilder:resource:path=fakeclusters,scope=Namespaced,categories=cluster-api
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:object:root=true
+
+// FakeCluster is the Schema for the fakeclusters API
+type FakeCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FakeClusterSpec   `json:"spec,omitempty"`
+	Status FakeClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FakeClusterList contains a list of FakeCluster
+type FakeClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FakeCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FakeCluster{}, &FakeClusterList{})
+}
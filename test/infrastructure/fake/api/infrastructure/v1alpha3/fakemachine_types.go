@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+)
+
+// FakeMachineSpec defines the desired state of FakeMachine.
+type FakeMachineSpec struct {
+	FakeBehavior `json:",inline"`
+
+	// ProviderID is set to fake:////<namespace>-<name> once the FakeMachine is ready.
+	// +optional
+	ProviderID *string `json:"providerID,omitempty"`
+}
+
+// FakeMachineStatus defines the observed state of FakeMachine.
+type FakeMachineStatus struct {
+	// Ready denotes that the fake machine is ready.
+	Ready bool `json:"ready"`
+
+	// Addresses contains the addresses of the fake machine.
+	// +optional
+	Addresses []clusterv1.MachineAddress `json:"addresses,omitempty"`
+
+	// ErrorReason is set from the injected failure, if any.
+	// +optional
+	ErrorReason *capierrors.MachineStatusError `json:"errorReason,omitempty"`
+
+	// ErrorMessage is set from the injected failure, if any.
+	// +optional
+	ErrorMessage *string `json:"errorMessage,omitempty"`
+}
+
+// +kubebuilder:resource:path=fakemachines,scope=Namespaced,categories=cluster-api
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:object:root=true
+
+// FakeMachine is the Schema for the fakemachines API
+type FakeMachine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FakeMachineSpec   `json:"spec,omitempty"`
+	Status FakeMachineStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FakeMachineList contains a list of FakeMachine
+type FakeMachineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FakeMachine `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FakeMachine{}, &FakeMachineList{})
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/infrastructure/v1alpha3"
+)
+
+// provisioningWait returns how long to wait before the object is provisioned, according to its
+// provisioning delay; zero means the object is provisioned.
+func provisioningWait(obj metav1.Object, behavior infrav1.FakeBehavior, now time.Time) time.Duration {
+	if behavior.ProvisioningDelay == nil {
+		return 0
+	}
+	readyAt := obj.GetCreationTimestamp().Add(behavior.ProvisioningDelay.Duration)
+	if wait := readyAt.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// nowOrDefault returns the current time from now, or from time.Now if now is nil.
+func nowOrDefault(now func() time.Time) time.Time {
+	if now == nil {
+		return time.Now()
+	}
+	return now()
+}
@@ -0,0 +1,23 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controllers implements the controllers of the fake infrastructure and bootstrap providers.
+//
+// The controllers do not provision anything: they mark the fake objects ready after the provisioning
+// delay in their spec, report the failures injected in their spec, and optionally create a Node for
+// each ready FakeMachine in the workload cluster, e.g. an envtest API server, so that the Cluster API
+// controllers can be exercised end-to-end without containers or cloud resources.
+package controllers
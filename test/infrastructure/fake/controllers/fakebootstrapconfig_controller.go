@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/bootstrap/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+// FakeBootstrapConfigReconciler reconciles a FakeBootstrapConfig object
+type FakeBootstrapConfigReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+
+	now func() time.Time
+}
+
+// SetupWithManager registers the reconciler with the manager, watching FakeBootstrapConfigs.
+func (r *FakeBootstrapConfigReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&bootstrapv1.FakeBootstrapConfig{}).
+		WithOptions(options).
+		Complete(r)
+}
+
+// Reconcile reconciles the FakeBootstrapConfig with the given name, and marks it ready once its provisioning delay has elapsed.
+func (r *FakeBootstrapConfigReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("fake-bootstrap-config", req.NamespacedName)
+
+	config := &bootstrapv1.FakeBootstrapConfig{}
+	if err := r.Client.Get(ctx, req.NamespacedName, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// There is nothing to clean up for deleted FakeBootstrapConfigs.
+	if !config.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	patchHelper, err := patch.NewHelper(config, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, config); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+		}
+	}()
+
+	if failure := config.Spec.Failure; failure != nil {
+		log.Info("Reporting injected failure", "reason", failure.Reason)
+		config.Status.ErrorReason = failure.Reason
+		config.Status.ErrorMessage = failure.Message
+		return ctrl.Result{}, nil
+	}
+
+	if wait := provisioningWait(config, config.Spec.FakeBehavior, nowOrDefault(r.now)); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	config.Status.BootstrapData = []byte(fmt.Sprintf("#cloud-config\n# fake bootstrap data for %s/%s\n", config.Namespace, config.Name))
+	config.Status.Ready = true
+	return ctrl.Result{}, nil
+}
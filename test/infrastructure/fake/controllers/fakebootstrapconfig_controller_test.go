@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/bootstrap/v1alpha3"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/infrastructure/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestFakeBootstrapConfigReconciler(t *testing.T) {
+	tests := []struct {
+		name        string
+		failure     *infrav1.FakeFailure
+		expectReady bool
+	}{
+		{
+			name:        "ready",
+			expectReady: true,
+		},
+		{
+			name:    "injected failure",
+			failure: &infrav1.FakeFailure{Reason: "BootstrapError", Message: "boom"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &bootstrapv1.FakeBootstrapConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+				Spec: bootstrapv1.FakeBootstrapConfigSpec{
+					FakeBehavior: infrav1.FakeBehavior{Failure: tc.failure},
+				},
+			}
+			c := fake.NewFakeClientWithScheme(setupScheme(t), config)
+			r := &FakeBootstrapConfigReconciler{Client: c, Log: log.Log}
+
+			key := client.ObjectKey{Namespace: config.Namespace, Name: config.Name}
+			if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			actual := &bootstrapv1.FakeBootstrapConfig{}
+			if err := c.Get(context.Background(), key, actual); err != nil {
+				t.Fatal(err)
+			}
+			if actual.Status.Ready != tc.expectReady {
+				t.Errorf("expected ready to be %t", tc.expectReady)
+			}
+			if tc.expectReady && len(actual.Status.BootstrapData) == 0 {
+				t.Error("expected bootstrap data")
+			}
+			if tc.failure != nil && actual.Status.ErrorReason != tc.failure.Reason {
+				t.Errorf("expected error reason %q, got %q", tc.failure.Reason, actual.Status.ErrorReason)
+			}
+		})
+	}
+}
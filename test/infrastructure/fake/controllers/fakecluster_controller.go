@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/infrastructure/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+// defaultAPIEndpoint is the control plane endpoint reported by FakeClusters not specifying one.
+var defaultAPIEndpoint = infrav1.APIEndpoint{Host: "127.0.0.1", Port: 6443}
+
+// FakeClusterReconciler reconciles a FakeCluster object
+type FakeClusterReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+
+	now func() time.Time
+}
+
+// SetupWithManager registers the reconciler with the manager, watching FakeClusters.
+func (r *FakeClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.FakeCluster{}).
+		WithOptions(options).
+		Complete(r)
+}
+
+// Reconcile reconciles the FakeCluster with the given name, and marks it ready once its provisioning delay has elapsed.
+func (r *FakeClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("fake-cluster", req.NamespacedName)
+
+	fakeCluster := &infrav1.FakeCluster{}
+	if err := r.Client.Get(ctx, req.NamespacedName, fakeCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// There is nothing to clean up for deleted FakeClusters.
+	if !fakeCluster.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	patchHelper, err := patch.NewHelper(fakeCluster, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, fakeCluster); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+		}
+	}()
+
+	if failure := fakeCluster.Spec.Failure; failure != nil {
+		log.Info("Reporting injected failure", "reason", failure.Reason)
+		fakeCluster.Status.ErrorReason = &failure.Reason
+		fakeCluster.Status.ErrorMessage = &failure.Message
+		return ctrl.Result{}, nil
+	}
+
+	if wait := provisioningWait(fakeCluster, fakeCluster.Spec.FakeBehavior, nowOrDefault(r.now)); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	endpoint := defaultAPIEndpoint
+	if fakeCluster.Spec.APIEndpoint != nil {
+		endpoint = *fakeCluster.Spec.APIEndpoint
+	}
+	fakeCluster.Status.APIEndpoints = []infrav1.APIEndpoint{endpoint}
+	fakeCluster.Status.Ready = true
+	return ctrl.Result{}, nil
+}
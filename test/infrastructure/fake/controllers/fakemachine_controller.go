@@ -0,0 +1,206 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/infrastructure/v1alpha3"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// FakeMachineReconciler reconciles a FakeMachine object
+type FakeMachineReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+
+	// CreateNodes enables the creation of a Node for each ready FakeMachine in the workload
+	// cluster, which is accessed through the kubeconfig secret of the Cluster.
+	CreateNodes bool
+
+	now func() time.Time
+}
+
+// SetupWithManager registers the reconciler with the manager, watching FakeMachines and the Machines that reference them.
+func (r *FakeMachineReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.FakeMachine{}).
+		Watches(
+			&source.Kind{Type: &clusterv1.Machine{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: util.MachineToInfrastructureMapFunc(infrav1.GroupVersion.WithKind("FakeMachine")),
+			},
+		).
+		WithOptions(options).
+		Complete(r)
+}
+
+// Reconcile reconciles the FakeMachine with the given name, and marks it ready once its provisioning delay has elapsed, and creates its Node
+// in the workload cluster if CreateNodes is set.
+func (r *FakeMachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("fake-machine", req.NamespacedName)
+
+	fakeMachine := &infrav1.FakeMachine{}
+	if err := r.Client.Get(ctx, req.NamespacedName, fakeMachine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// The Machine controller deletes the Node of deleted Machines, so there is nothing to clean up.
+	if !fakeMachine.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Like real infrastructure providers, wait for the Machine and its bootstrap data.
+	machine, err := util.GetOwnerMachine(ctx, r.Client, fakeMachine.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if machine == nil {
+		log.Info("Waiting for Machine Controller to set OwnerRef on FakeMachine")
+		return ctrl.Result{}, nil
+	}
+	if machine.Spec.Bootstrap.Data == nil {
+		log.Info("Waiting for the Bootstrap provider controller to set bootstrap data")
+		return ctrl.Result{}, nil
+	}
+
+	patchHelper, err := patch.NewHelper(fakeMachine, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, fakeMachine); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+		}
+	}()
+
+	if failure := fakeMachine.Spec.Failure; failure != nil {
+		log.Info("Reporting injected failure", "reason", failure.Reason)
+		reason := capierrors.MachineStatusError(failure.Reason)
+		fakeMachine.Status.ErrorReason = &reason
+		fakeMachine.Status.ErrorMessage = &failure.Message
+		return ctrl.Result{}, nil
+	}
+
+	if wait := provisioningWait(fakeMachine, fakeMachine.Spec.FakeBehavior, nowOrDefault(r.now)); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	providerID := fmt.Sprintf("fake:////%s", nodeName(fakeMachine))
+	fakeMachine.Spec.ProviderID = &providerID
+	fakeMachine.Status.Addresses = []clusterv1.MachineAddress{
+		{
+			Type:    clusterv1.MachineHostName,
+			Address: fakeMachine.Name,
+		},
+	}
+	fakeMachine.Status.Ready = true
+
+	if r.CreateNodes {
+		if err := r.reconcileNode(ctx, machine, fakeMachine); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileNode makes sure a ready Node with the provider ID of the FakeMachine exists in the workload cluster.
+func (r *FakeMachineReconciler) reconcileNode(ctx context.Context, machine *clusterv1.Machine, fakeMachine *infrav1.FakeMachine) error {
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, machine.ObjectMeta)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the Cluster of FakeMachine %q in namespace %q", fakeMachine.Name, fakeMachine.Namespace)
+	}
+
+	clusterClient, err := remote.NewClusterClient(ctx, r.Client, cluster)
+	if err != nil {
+		return err
+	}
+	coreClient, err := clusterClient.CoreV1()
+	if err != nil {
+		return err
+	}
+
+	node, err := coreClient.Nodes().Get(nodeName(fakeMachine), metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		node, err = coreClient.Nodes().Create(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName(fakeMachine),
+			},
+			Spec: corev1.NodeSpec{
+				ProviderID: *fakeMachine.Spec.ProviderID,
+			},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create Node for FakeMachine %q in namespace %q", fakeMachine.Name, fakeMachine.Namespace)
+		}
+	case err != nil:
+		return errors.Wrapf(err, "failed to get Node for FakeMachine %q in namespace %q", fakeMachine.Name, fakeMachine.Namespace)
+	}
+
+	if util.IsNodeReady(node) {
+		return nil
+	}
+	node.Status.Conditions = []corev1.NodeCondition{
+		{
+			Type:               corev1.NodeReady,
+			Status:             corev1.ConditionTrue,
+			Reason:             "FakeMachineReady",
+			LastHeartbeatTime:  metav1.Now(),
+			LastTransitionTime: metav1.Now(),
+		},
+	}
+	node.Status.Addresses = []corev1.NodeAddress{
+		{
+			Type:    corev1.NodeHostName,
+			Address: fakeMachine.Name,
+		},
+	}
+	if _, err := coreClient.Nodes().UpdateStatus(node); err != nil {
+		return errors.Wrapf(err, "failed to mark Node for FakeMachine %q in namespace %q ready", fakeMachine.Name, fakeMachine.Namespace)
+	}
+	return nil
+}
+
+// nodeName returns the name of the Node of the FakeMachine, which is also used in its provider ID. Nodes are
+// cluster scoped, so the name includes the namespace of the FakeMachine for FakeMachines of different namespaces
+// not to share a Node or a provider ID.
+func nodeName(fakeMachine *infrav1.FakeMachine) string {
+	return fmt.Sprintf("%s-%s", fakeMachine.Namespace, fakeMachine.Name)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/bootstrap/v1alpha3"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/infrastructure/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func setupScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		clusterv1.AddToScheme,
+		infrav1.AddToScheme,
+		bootstrapv1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return scheme
+}
+
+func TestFakeMachineReconciler(t *testing.T) {
+	created := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		bootstrapData *string
+		behavior      infrav1.FakeBehavior
+		expectReady   bool
+		expectRequeue bool
+		expectError   bool
+	}{
+		{
+			name: "waits for bootstrap data",
+		},
+		{
+			name:          "ready without delay",
+			bootstrapData: pointer.StringPtr("data"),
+			expectReady:   true,
+		},
+		{
+			name:          "waits for the provisioning delay",
+			bootstrapData: pointer.StringPtr("data"),
+			behavior: infrav1.FakeBehavior{
+				ProvisioningDelay: &metav1.Duration{Duration: 2 * time.Minute},
+			},
+			expectRequeue: true,
+		},
+		{
+			name:          "ready after the provisioning delay",
+			bootstrapData: pointer.StringPtr("data"),
+			behavior: infrav1.FakeBehavior{
+				ProvisioningDelay: &metav1.Duration{Duration: 30 * time.Second},
+			},
+			expectReady: true,
+		},
+		{
+			name:          "injected failure",
+			bootstrapData: pointer.StringPtr("data"),
+			behavior: infrav1.FakeBehavior{
+				Failure: &infrav1.FakeFailure{Reason: string(capierrors.CreateMachineError), Message: "boom"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "default"},
+				Spec: clusterv1.MachineSpec{
+					Bootstrap: clusterv1.Bootstrap{Data: tc.bootstrapData},
+				},
+			}
+			fakeMachine := &infrav1.FakeMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "fake-machine",
+					Namespace:         "default",
+					CreationTimestamp: metav1.NewTime(created),
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: clusterv1.GroupVersion.String(), Kind: "Machine", Name: machine.Name},
+					},
+				},
+				Spec: infrav1.FakeMachineSpec{FakeBehavior: tc.behavior},
+			}
+
+			c := fake.NewFakeClientWithScheme(setupScheme(t), machine, fakeMachine)
+			r := &FakeMachineReconciler{
+				Client: c,
+				Log:    log.Log,
+				now:    func() time.Time { return created.Add(time.Minute) },
+			}
+
+			key := client.ObjectKey{Namespace: fakeMachine.Namespace, Name: fakeMachine.Name}
+			result, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.expectRequeue && result.RequeueAfter != time.Minute {
+				t.Errorf("expected a requeue after %v, got %v", time.Minute, result.RequeueAfter)
+			}
+
+			actual := &infrav1.FakeMachine{}
+			if err := c.Get(context.Background(), key, actual); err != nil {
+				t.Fatal(err)
+			}
+			if actual.Status.Ready != tc.expectReady {
+				t.Errorf("expected ready to be %t", tc.expectReady)
+			}
+			if tc.expectReady && (actual.Spec.ProviderID == nil || *actual.Spec.ProviderID != "fake:////default-fake-machine") {
+				t.Errorf("expected provider ID fake:////default-fake-machine, got %v", actual.Spec.ProviderID)
+			}
+			if tc.expectError != (actual.Status.ErrorReason != nil) {
+				t.Errorf("expected error reason to be set: %t, got %v", tc.expectError, actual.Status.ErrorReason)
+			}
+		})
+	}
+}
+
+func TestFakeMachineReconcilerNamespacesProviderID(t *testing.T) {
+	var objs []runtime.Object
+	for _, namespace := range []string{"a", "b"} {
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: namespace},
+			Spec: clusterv1.MachineSpec{
+				Bootstrap: clusterv1.Bootstrap{Data: pointer.StringPtr("data")},
+			},
+		}
+		fakeMachine := &infrav1.FakeMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fake-machine",
+				Namespace: namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: clusterv1.GroupVersion.String(), Kind: "Machine", Name: machine.Name},
+				},
+			},
+		}
+		objs = append(objs, machine, fakeMachine)
+	}
+
+	c := fake.NewFakeClientWithScheme(setupScheme(t), objs...)
+	r := &FakeMachineReconciler{
+		Client: c,
+		Log:    log.Log,
+	}
+
+	providerIDs := map[string]string{}
+	for _, namespace := range []string{"a", "b"} {
+		key := client.ObjectKey{Namespace: namespace, Name: "fake-machine"}
+		if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		actual := &infrav1.FakeMachine{}
+		if err := c.Get(context.Background(), key, actual); err != nil {
+			t.Fatal(err)
+		}
+		if actual.Spec.ProviderID == nil {
+			t.Fatalf("expected provider ID to be set for FakeMachine in namespace %q", namespace)
+		}
+		providerIDs[namespace] = *actual.Spec.ProviderID
+	}
+
+	if providerIDs["a"] != "fake:////a-fake-machine" {
+		t.Errorf("expected provider ID fake:////a-fake-machine, got %q", providerIDs["a"])
+	}
+	if providerIDs["a"] == providerIDs["b"] {
+		t.Errorf("expected FakeMachines of different namespaces to have different provider IDs, got %q", providerIDs["a"])
+	}
+}
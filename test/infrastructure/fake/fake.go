@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake provides an in-memory infrastructure and bootstrap provider for controller integration tests.
+//
+// FakeCluster, FakeMachine, FakeMachineTemplate, FakeBootstrapConfig and FakeBootstrapConfigTemplate
+// follow the provider contracts without provisioning anything, so MachineDeployment rollouts and scale
+// tests can run against envtest in CI:
+//
+//	testEnv := &envtest.Environment{
+//		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases"), fake.CRDPath()},
+//	}
+//	...
+//	Expect(fake.AddToScheme(scheme.Scheme)).To(Succeed())
+//	Expect(fake.SetupWithManager(mgr, controller.Options{}, true)).To(Succeed())
+package fake
+
+import (
+	"path/filepath"
+	"runtime"
+
+	"github.com/pkg/errors"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+	bootstrapv1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/bootstrap/v1alpha3"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/fake/api/infrastructure/v1alpha3"
+	"sigs.k8s.io/cluster-api/test/infrastructure/fake/controllers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+// CRDPath returns the directory containing the CRDs of the fake providers,
+// to be added to envtest.Environment.CRDDirectoryPaths.
+func CRDPath() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "config", "crd", "bases")
+}
+
+// AddToScheme adds the fake infrastructure and bootstrap types to the scheme.
+func AddToScheme(scheme *kruntime.Scheme) error {
+	if err := infrav1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	return bootstrapv1.AddToScheme(scheme)
+}
+
+// SetupWithManager registers the fake infrastructure and bootstrap controllers with the manager.
+// If createNodes is true, a Node is created in the workload cluster of each ready FakeMachine.
+func SetupWithManager(mgr ctrl.Manager, options controller.Options, createNodes bool) error {
+	log := ctrl.Log.WithName("controllers")
+	if err := (&controllers.FakeClusterReconciler{
+		Client: mgr.GetClient(),
+		Log:    log.WithName("FakeCluster"),
+	}).SetupWithManager(mgr, options); err != nil {
+		return errors.Wrap(err, "failed to set up the FakeCluster controller")
+	}
+	if err := (&controllers.FakeMachineReconciler{
+		Client:      mgr.GetClient(),
+		Log:         log.WithName("FakeMachine"),
+		CreateNodes: createNodes,
+	}).SetupWithManager(mgr, options); err != nil {
+		return errors.Wrap(err, "failed to set up the FakeMachine controller")
+	}
+	if err := (&controllers.FakeBootstrapConfigReconciler{
+		Client: mgr.GetClient(),
+		Log:    log.WithName("FakeBootstrapConfig"),
+	}).SetupWithManager(mgr, options); err != nil {
+		return errors.Wrap(err, "failed to set up the FakeBootstrapConfig controller")
+	}
+	return nil
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package certs
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -31,6 +34,34 @@ func NewPrivateKey() (*rsa.PrivateKey, error) {
 	return pk, errors.WithStack(err)
 }
 
+// NewSigner creates a private key using the given algorithm.
+func NewSigner(algorithm KeyAlgorithm) (crypto.Signer, error) {
+	switch algorithm {
+	case "", RSA:
+		return NewPrivateKey()
+	case ECDSAP256:
+		pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		return pk, errors.WithStack(err)
+	default:
+		return nil, errors.Errorf("unsupported key algorithm %q", algorithm)
+	}
+}
+
+// KeyAlgorithmOf returns the algorithm of the given private key.
+func KeyAlgorithmOf(key crypto.Signer) (KeyAlgorithm, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return RSA, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve == elliptic.P256() {
+			return ECDSAP256, nil
+		}
+		return "", errors.Errorf("unsupported elliptic curve %q", k.Curve.Params().Name)
+	default:
+		return "", errors.Errorf("unsupported private key type %T", key)
+	}
+}
+
 // EncodeCertPEM returns PEM-endcoded certificate data.
 func EncodeCertPEM(cert *x509.Certificate) []byte {
 	block := pem.Block{
@@ -41,17 +72,39 @@ func EncodeCertPEM(cert *x509.Certificate) []byte {
 }
 
 // EncodePrivateKeyPEM returns PEM-encoded private key data.
-func EncodePrivateKeyPEM(key *rsa.PrivateKey) []byte {
-	block := pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(key),
+// RSA keys are encoded as PKCS#1, ECDSA keys as SEC 1 and any other key as PKCS#8.
+func EncodePrivateKeyPEM(key crypto.Signer) ([]byte, error) {
+	var block pem.Block
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		block = pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(k),
+		}
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		block = pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: der,
+		}
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		block = pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: der,
+		}
 	}
-
-	return pem.EncodeToMemory(&block)
+	return pem.EncodeToMemory(&block), nil
 }
 
 // EncodePublicKeyPEM returns PEM-encoded public key data.
-func EncodePublicKeyPEM(key *rsa.PublicKey) ([]byte, error) {
+func EncodePublicKeyPEM(key crypto.PublicKey) ([]byte, error) {
 	der, err := x509.MarshalPKIXPublicKey(key)
 	if err != nil {
 		return []byte{}, errors.WithStack(err)
@@ -76,11 +129,27 @@ func DecodeCertPEM(encoded []byte) (*x509.Certificate, error) {
 
 // DecodePrivateKeyPEM attempts to return a decoded key or nil
 // if the encoded input does not contain a private key.
-func DecodePrivateKeyPEM(encoded []byte) (*rsa.PrivateKey, error) {
+// PKCS#1 RSA, SEC 1 EC and PKCS#8 encoded keys are supported.
+func DecodePrivateKeyPEM(encoded []byte) (crypto.Signer, error) {
 	block, _ := pem.Decode(encoded)
 	if block == nil {
 		return nil, nil
 	}
 
-	return x509.ParsePKCS1PrivateKey(block.Bytes)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
 }
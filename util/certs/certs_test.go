@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPrivateKeyPEMRoundTrip(t *testing.T) {
+	for _, algorithm := range []KeyAlgorithm{RSA, ECDSAP256} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			key, err := NewSigner(algorithm)
+			if err != nil {
+				t.Fatalf("NewSigner() error = %v", err)
+			}
+
+			got, err := KeyAlgorithmOf(key)
+			if err != nil {
+				t.Fatalf("KeyAlgorithmOf() error = %v", err)
+			}
+			if got != algorithm {
+				t.Fatalf("expected key algorithm %q, got %q", algorithm, got)
+			}
+
+			encoded, err := EncodePrivateKeyPEM(key)
+			if err != nil {
+				t.Fatalf("EncodePrivateKeyPEM() error = %v", err)
+			}
+			decoded, err := DecodePrivateKeyPEM(encoded)
+			if err != nil {
+				t.Fatalf("DecodePrivateKeyPEM() error = %v", err)
+			}
+			if !reflect.DeepEqual(key, decoded) {
+				t.Fatal("expected decoded key to match the original key")
+			}
+		})
+	}
+}
+
+func TestDecodePrivateKeyPEM_PKCS8(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := DecodePrivateKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("DecodePrivateKeyPEM() error = %v", err)
+	}
+	if !reflect.DeepEqual(key, decoded) {
+		t.Fatal("expected decoded key to match the original key")
+	}
+
+	if decoded, err := DecodePrivateKeyPEM([]byte("not a key")); decoded != nil || err != nil {
+		t.Fatalf("expected nil key and error for input without PEM data, got %v, %v", decoded, err)
+	}
+}
+
+func TestNewSignedCert(t *testing.T) {
+	for _, algorithm := range []KeyAlgorithm{RSA, ECDSAP256} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			caKey, err := NewSigner(algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			caCert := newTestCACert(t, caKey)
+
+			key, err := NewSigner(algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := &Config{
+				CommonName: "test",
+				Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			}
+			cert, err := cfg.NewSignedCert(key, caCert, caKey)
+			if err != nil {
+				t.Fatalf("NewSignedCert() error = %v", err)
+			}
+			if err := cert.CheckSignatureFrom(caCert); err != nil {
+				t.Fatalf("expected certificate to be signed by the CA: %v", err)
+			}
+			if algorithm == ECDSAP256 && cert.KeyUsage&x509.KeyUsageKeyEncipherment != 0 {
+				t.Fatal("expected ECDSA certificate not to allow key encipherment")
+			}
+		})
+	}
+}
+
+func newTestCACert(t *testing.T, key crypto.Signer) *x509.Certificate {
+	tmpl := x509.Certificate{
+		SerialNumber:          new(big.Int).SetInt64(0),
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	b, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
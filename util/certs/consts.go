@@ -25,3 +25,14 @@ const (
 	// DefaultCertDuration is the default lifespan used when creating certificates.
 	DefaultCertDuration = time.Hour * 24 * 365
 )
+
+// KeyAlgorithm is the algorithm used to generate a private key.
+type KeyAlgorithm string
+
+const (
+	// RSA generates RSA keys of DefaultRSAKeySize bits.
+	RSA KeyAlgorithm = "RSA"
+
+	// ECDSAP256 generates ECDSA keys on the NIST P-256 curve.
+	ECDSAP256 KeyAlgorithm = "ECDSA-P256"
+)
@@ -18,9 +18,10 @@ package kubeconfig
 
 import (
 	"context"
-	"crypto/rsa"
+	"crypto"
 	"crypto/x509"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -52,14 +53,20 @@ func FromSecret(c client.Client, cluster *clusterv1.Cluster) ([]byte, error) {
 }
 
 // New creates a new Kubeconfig using the cluster name and specified endpoint.
-func New(clusterName, endpoint string, caCert *x509.Certificate, caKey *rsa.PrivateKey) (*api.Config, error) {
+// The client key is generated using the same algorithm as the CA key.
+func New(clusterName, endpoint string, caCert *x509.Certificate, caKey crypto.Signer) (*api.Config, error) {
 	cfg := &certs.Config{
 		CommonName:   "kubernetes-admin",
 		Organization: []string{"system:masters"},
 		Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
 	}
 
-	clientKey, err := certs.NewPrivateKey()
+	algorithm, err := certs.KeyAlgorithmOf(caKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to determine CA key algorithm")
+	}
+
+	clientKey, err := certs.NewSigner(algorithm)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create private key")
 	}
@@ -69,6 +76,11 @@ func New(clusterName, endpoint string, caCert *x509.Certificate, caKey *rsa.Priv
 		return nil, errors.Wrap(err, "unable to sign certificate")
 	}
 
+	clientKeyData, err := certs.EncodePrivateKeyPEM(clientKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to encode private key")
+	}
+
 	userName := "kubernetes-admin"
 	contextName := fmt.Sprintf("%s@%s", userName, clusterName)
 
@@ -87,7 +99,7 @@ func New(clusterName, endpoint string, caCert *x509.Certificate, caKey *rsa.Priv
 		},
 		AuthInfos: map[string]*api.AuthInfo{
 			userName: {
-				ClientKeyData:         certs.EncodePrivateKeyPEM(clientKey),
+				ClientKeyData:         clientKeyData,
 				ClientCertificateData: certs.EncodeCertPEM(clientCert),
 			},
 		},
@@ -97,55 +109,106 @@ func New(clusterName, endpoint string, caCert *x509.Certificate, caKey *rsa.Priv
 
 // CreateSecret creates the Kubeconfig secret for the given cluster.
 func CreateSecret(ctx context.Context, c client.Client, cluster *clusterv1.Cluster) error {
+	out, err := generateKubeconfig(c, cluster)
+	if err != nil {
+		return err
+	}
+
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+					Name:       cluster.Name,
+					UID:        cluster.UID,
+				},
+			},
+		},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: out,
+		},
+	}
+	return c.Create(ctx, s)
+}
+
+// RegenerateSecret replaces the Kubeconfig stored in the given secret
+// with a new one signed by the cluster CA.
+func RegenerateSecret(ctx context.Context, c client.Client, cluster *clusterv1.Cluster, configSecret *corev1.Secret) error {
+	out, err := generateKubeconfig(c, cluster)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(configSecret.DeepCopy())
+	if configSecret.Data == nil {
+		configSecret.Data = map[string][]byte{}
+	}
+	configSecret.Data[secret.KubeconfigDataName] = out
+	return c.Patch(ctx, configSecret, patch)
+}
+
+// ClientCertificateExpiry returns the expiration time of the client certificate
+// used by the current context of the given Kubeconfig.
+func ClientCertificateExpiry(data []byte) (time.Time, error) {
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to parse kubeconfig")
+	}
+
+	currentContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return time.Time{}, errors.Errorf("current context %q not found in kubeconfig", config.CurrentContext)
+	}
+	authInfo, ok := config.AuthInfos[currentContext.AuthInfo]
+	if !ok {
+		return time.Time{}, errors.Errorf("user %q not found in kubeconfig", currentContext.AuthInfo)
+	}
+
+	cert, err := certs.DecodeCertPEM(authInfo.ClientCertificateData)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to decode client certificate")
+	} else if cert == nil {
+		return time.Time{}, errors.New("client certificate not found in kubeconfig")
+	}
+	return cert.NotAfter, nil
+}
+
+// generateKubeconfig returns a new serialized Kubeconfig for the given cluster, signed by the cluster CA.
+func generateKubeconfig(c client.Client, cluster *clusterv1.Cluster) ([]byte, error) {
 	clusterCA, err := secret.Get(c, cluster, secret.ClusterCA)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			return ErrDependentCertificateNotFound
+			return nil, ErrDependentCertificateNotFound
 		}
-		return err
+		return nil, err
 	}
 
 	cert, err := certs.DecodeCertPEM(clusterCA.Data[secret.TLSCrtDataName])
 	if err != nil {
-		return errors.Wrap(err, "failed to decode CA Cert")
+		return nil, errors.Wrap(err, "failed to decode CA Cert")
 	} else if cert == nil {
-		return errors.New("certificate not found in config")
+		return nil, errors.New("certificate not found in config")
 	}
 
 	key, err := certs.DecodePrivateKeyPEM(clusterCA.Data[secret.TLSKeyDataName])
 	if err != nil {
-		return errors.Wrap(err, "failed to decode private key")
+		return nil, errors.Wrap(err, "failed to decode private key")
 	} else if key == nil {
-		return errors.New("CA private key not found")
+		return nil, errors.New("CA private key not found")
 	}
 
 	server := fmt.Sprintf("https://%s:%d", cluster.Status.APIEndpoints[0].Host, cluster.Status.APIEndpoints[0].Port)
 	cfg, err := New(cluster.Name, server, cert, key)
 	if err != nil {
-		return errors.Wrap(err, "failed to generate a kubeconfig")
+		return nil, errors.Wrap(err, "failed to generate a kubeconfig")
 	}
 
 	out, err := clientcmd.Write(*cfg)
 	if err != nil {
-		return errors.Wrap(err, "failed to serialize config to yaml")
+		return nil, errors.Wrap(err, "failed to serialize config to yaml")
 	}
-
-	s := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
-			Namespace: cluster.Namespace,
-			OwnerReferences: []metav1.OwnerReference{
-				{
-					APIVersion: clusterv1.GroupVersion.String(),
-					Kind:       "Cluster",
-					Name:       cluster.Name,
-					UID:        cluster.UID,
-				},
-			},
-		},
-		Data: map[string][]byte{
-			secret.KubeconfigDataName: out,
-		},
-	}
-	return c.Create(ctx, s)
+	return out, nil
 }
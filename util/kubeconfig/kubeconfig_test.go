@@ -17,13 +17,22 @@ limitations under the License.
 package kubeconfig
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -73,3 +82,136 @@ func TestGetKubeConfigSecret(t *testing.T) {
 		t.Fatalf("Expected found secret to be equal to input")
 	}
 }
+
+func TestClientCertificateExpiry(t *testing.T) {
+	expiry, err := ClientCertificateExpiry([]byte(validKubeConfig))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := time.Date(2020, time.January, 10, 18, 0, 42, 0, time.UTC)
+	if !expiry.Equal(expected) {
+		t.Fatalf("Expected expiry %s, got %s", expected, expiry)
+	}
+
+	if _, err := ClientCertificateExpiry([]byte("not a kubeconfig")); err == nil {
+		t.Fatal("Expected error for an invalid kubeconfig, got nil")
+	}
+}
+
+func TestCreateAndRegenerateSecret(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test1", Namespace: "test", UID: types.UID("uid")},
+		Status: clusterv1.ClusterStatus{
+			APIEndpoints: []clusterv1.APIEndpoint{{Host: "1.2.3.4", Port: 6443}},
+		},
+	}
+	c := fake.NewFakeClient(newCASecret(t, cluster))
+
+	if err := CreateSecret(context.Background(), c, cluster); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	configSecret, err := secret.Get(c, cluster, secret.Kubeconfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	created := configSecret.Data[secret.KubeconfigDataName]
+	if _, err := ClientCertificateExpiry(created); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := RegenerateSecret(context.Background(), c, cluster, configSecret); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	regenerated := &corev1.Secret{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "test", Name: "test1-kubeconfig"}, regenerated); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reflect.DeepEqual(created, regenerated.Data[secret.KubeconfigDataName]) {
+		t.Fatal("Expected the kubeconfig to be regenerated")
+	}
+	if _, err := ClientCertificateExpiry(regenerated.Data[secret.KubeconfigDataName]); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestNew_ECDSA(t *testing.T) {
+	caKey, err := certs.NewSigner(certs.ECDSAP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber:          new(big.Int).SetInt64(0),
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	b, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, caKey.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := New("test1", "https://1.2.3.4:6443", caCert, caKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	clientKey, err := certs.DecodePrivateKeyPEM(cfg.AuthInfos["kubernetes-admin"].ClientKeyData)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	algorithm, err := certs.KeyAlgorithmOf(clientKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if algorithm != certs.ECDSAP256 {
+		t.Fatalf("Expected client key algorithm %q, got %q", certs.ECDSAP256, algorithm)
+	}
+}
+
+func newCASecret(t *testing.T, cluster *clusterv1.Cluster) *corev1.Secret {
+	key, err := certs.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber:          new(big.Int).SetInt64(0),
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	b, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyData, err := certs.EncodePrivateKeyPEM(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.ClusterCA),
+			Namespace: cluster.Namespace,
+		},
+		Data: map[string][]byte{
+			secret.TLSCrtDataName: certs.EncodeCertPEM(cert),
+			secret.TLSKeyDataName: keyData,
+		},
+	}
+}
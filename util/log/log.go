@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package log provides helpers to carry a request-scoped logger through a context,
+// so that the helpers called by the reconcilers log with the keys of the object being reconciled.
+package log
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// Keys used to identify objects in log lines.
+const (
+	NamespaceKey         = "namespace"
+	ClusterKey           = "cluster"
+	MachineKey           = "machine"
+	MachineSetKey        = "machineSet"
+	MachineDeploymentKey = "machineDeployment"
+)
+
+type loggerKey struct{}
+
+// IntoContext returns a copy of ctx carrying the logger.
+func IntoContext(ctx context.Context, log logr.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// FromContext returns the logger carried by ctx, or the controller-runtime root logger if there is none.
+// Additional key/value pairs are added to the returned logger.
+func FromContext(ctx context.Context, keysAndValues ...interface{}) logr.Logger {
+	log, ok := ctx.Value(loggerKey{}).(logr.Logger)
+	if !ok {
+		log = ctrl.Log
+	}
+	if len(keysAndValues) > 0 {
+		log = log.WithValues(keysAndValues...)
+	}
+	return log
+}
+
+// NewJSONLogger returns a logger writing one JSON object per line to stderr,
+// which includes the messages logged at a V-level up to verbosity.
+func NewJSONLogger(verbosity int) logr.Logger {
+	level := zap.NewAtomicLevelAt(zapcore.Level(-verbosity))
+	return ctrlzap.New(func(o *ctrlzap.Options) {
+		o.Level = &level
+	})
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type recordingLogger struct {
+	logr.Logger
+	values []interface{}
+}
+
+func (l *recordingLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return &recordingLogger{
+		Logger: l.Logger,
+		values: append(append([]interface{}{}, l.values...), keysAndValues...),
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if log := FromContext(context.Background()); log != ctrl.Log {
+		t.Errorf("expected the root logger for a context without logger, got %v", log)
+	}
+
+	root := &recordingLogger{Logger: ctrl.Log}
+	ctx := IntoContext(context.Background(), root.WithValues(NamespaceKey, "default"))
+
+	log, ok := FromContext(ctx, MachineKey, "machine-1").(*recordingLogger)
+	if !ok {
+		t.Fatalf("expected the logger of the context")
+	}
+	expected := []interface{}{NamespaceKey, "default", MachineKey, "machine-1"}
+	if len(log.values) != len(expected) {
+		t.Fatalf("expected values %v, got %v", expected, log.values)
+	}
+	for i := range expected {
+		if log.values[i] != expected[i] {
+			t.Errorf("expected values %v, got %v", expected, log.values)
+		}
+	}
+}
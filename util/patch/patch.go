@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -81,7 +82,10 @@ func NewHelper(resource runtime.Object, crClient client.Client) (*Helper, error)
 }
 
 // Patch will attempt to patch the given resource and its status
-func (h *Helper) Patch(ctx context.Context, resource runtime.Object) error {
+func (h *Helper) Patch(ctx context.Context, resource runtime.Object) (reterr error) {
+	ctx, span := tracing.Start(ctx, "patch.Helper.Patch")
+	defer func() { tracing.End(ctx, span, reterr) }()
+
 	if resource == nil {
 		return errors.Errorf("expected non-nil resource")
 	}
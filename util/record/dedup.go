@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+// DefaultDeduplicationWindow is the interval during which the repeats of an event are dropped.
+const DefaultDeduplicationWindow = 10 * time.Minute
+
+// maxDeduplicationEntries bounds the number of events remembered by a deduplicating recorder.
+const maxDeduplicationEntries = 4096
+
+type eventKey struct {
+	kind      string
+	uid       types.UID
+	namespace string
+	name      string
+	eventType string
+	reason    string
+	message   string
+}
+
+type deduplicatingRecorder struct {
+	record.EventRecorder
+
+	window time.Duration
+	now    func() time.Time
+
+	lock sync.Mutex
+	seen map[eventKey]time.Time
+}
+
+// NewDeduplicatingRecorder returns a recorder which drops the events identical to one recorded
+// for the same object during the window, so that a condition hit on every reconcile is reported
+// once per window instead of once per reconcile.
+func NewDeduplicatingRecorder(recorder record.EventRecorder, window time.Duration) record.EventRecorder {
+	return &deduplicatingRecorder{
+		EventRecorder: recorder,
+		window:        window,
+		now:           time.Now,
+		seen:          map[eventKey]time.Time{},
+	}
+}
+
+func (r *deduplicatingRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if r.isRepeat(object, eventtype, reason, message) {
+		return
+	}
+	r.EventRecorder.Event(object, eventtype, reason, message)
+}
+
+func (r *deduplicatingRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *deduplicatingRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	if r.isRepeat(object, eventtype, reason, message) {
+		return
+	}
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s", message)
+}
+
+// isRepeat returns whether the event has already been recorded during the window,
+// and remembers it otherwise.
+func (r *deduplicatingRecorder) isRepeat(object runtime.Object, eventtype, reason, message string) bool {
+	key := eventKey{
+		kind:      fmt.Sprintf("%T", object),
+		eventType: eventtype,
+		reason:    reason,
+		message:   message,
+	}
+	if accessor, err := meta.Accessor(object); err == nil {
+		key.uid = accessor.GetUID()
+		key.namespace = accessor.GetNamespace()
+		key.name = accessor.GetName()
+	}
+
+	now := r.now()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if last, ok := r.seen[key]; ok && now.Sub(last) < r.window {
+		return true
+	}
+	if len(r.seen) >= maxDeduplicationEntries {
+		for k, last := range r.seen {
+			if now.Sub(last) >= r.window {
+				delete(r.seen, k)
+			}
+		}
+		if len(r.seen) >= maxDeduplicationEntries {
+			r.seen = map[eventKey]time.Time{}
+		}
+	}
+	r.seen[key] = now
+	return false
+}
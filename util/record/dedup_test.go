@@ -0,0 +1,50 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDeduplicatingRecorder(t *testing.T) {
+	fake := record.NewFakeRecorder(10)
+	now := time.Now()
+	recorder := NewDeduplicatingRecorder(fake, time.Minute).(*deduplicatingRecorder)
+	recorder.now = func() time.Time { return now }
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1", UID: "uid-1"}}
+	other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-2", UID: "uid-2"}}
+
+	recorder.Eventf(pod, corev1.EventTypeWarning, FailedDeleteReason, "Failed to delete %q", "a")
+	recorder.Eventf(pod, corev1.EventTypeWarning, FailedDeleteReason, "Failed to delete %q", "a")
+	recorder.Eventf(pod, corev1.EventTypeWarning, FailedDeleteReason, "Failed to delete %q", "b")
+	recorder.Eventf(other, corev1.EventTypeWarning, FailedDeleteReason, "Failed to delete %q", "a")
+	if got := len(fake.Events); got != 3 {
+		t.Errorf("expected the repeated event to be dropped, got %d events", got)
+	}
+
+	now = now.Add(time.Minute)
+	recorder.Eventf(pod, corev1.EventTypeWarning, FailedDeleteReason, "Failed to delete %q", "a")
+	if got := len(fake.Events); got != 4 {
+		t.Errorf("expected the event to be recorded again after the window, got %d events", got)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+// Reasons of the events emitted by the Cluster API controllers.
+// Reasons are CamelCase, and start with Successful or Failed for the outcome of an action.
+const (
+	// SuccessfulCreateReason and FailedCreateReason report the creation of a child object.
+	SuccessfulCreateReason = "SuccessfulCreate"
+	FailedCreateReason     = "FailedCreate"
+
+	// SuccessfulDeleteReason and FailedDeleteReason report the deletion of a child or external object.
+	SuccessfulDeleteReason = "SuccessfulDelete"
+	FailedDeleteReason     = "FailedDelete"
+
+	// SuccessfulScaleReason and FailedScaleReason report a change of the replicas of a MachineSet.
+	SuccessfulScaleReason = "SuccessfulScale"
+	FailedScaleReason     = "FailedScale"
+
+	// SuccessfulAdoptReason and FailedAdoptReason report the adoption of an orphan object.
+	SuccessfulAdoptReason = "SuccessfulAdopt"
+	FailedAdoptReason     = "FailedAdopt"
+
+	// SuccessfulDrainNodeReason and FailedDrainNodeReason report the drain of the Node of a Machine being deleted.
+	SuccessfulDrainNodeReason = "SuccessfulDrainNode"
+	FailedDrainNodeReason     = "FailedDrainNode"
+
+	// SuccessfulDeleteNodeReason and FailedDeleteNodeReason report the deletion of the Node of a Machine.
+	SuccessfulDeleteNodeReason = "SuccessfulDeleteNode"
+	FailedDeleteNodeReason     = "FailedDeleteNode"
+
+	// SuccessfulSetNodeRefReason and FailedSetNodeRefReason report the lookup of the Node of a Machine.
+	SuccessfulSetNodeRefReason = "SuccessfulSetNodeRef"
+	FailedSetNodeRefReason     = "FailedSetNodeRef"
+
+	// SuccessfulUpdateNodeReason and FailedUpdateNodeReason report the sync of the labels, annotations and taints of the Node of a Machine.
+	SuccessfulUpdateNodeReason = "SuccessfulUpdateNode"
+	FailedUpdateNodeReason     = "FailedUpdateNode"
+
+	// SuccessfulRotateKubeconfigReason and FailedRotateKubeconfigReason report the rotation of the Kubeconfig of a Cluster.
+	SuccessfulRotateKubeconfigReason = "SuccessfulRotateKubeconfig"
+	FailedRotateKubeconfigReason     = "FailedRotateKubeconfig"
+
+	// KubeconfigExpiringSoonReason warns that a user provided Kubeconfig is about to expire.
+	KubeconfigExpiringSoonReason = "KubeconfigExpiringSoon"
+
+	// ClusterCAExpiringSoonReason warns that the CA of a Cluster is about to expire.
+	ClusterCAExpiringSoonReason = "ClusterCAExpiringSoon"
+
+	// InvalidAutoscalerAnnotationsReason warns that the cluster autoscaler annotations of a MachineSet or
+	// MachineDeployment are malformed.
+	InvalidAutoscalerAnnotationsReason = "InvalidAutoscalerAnnotations"
+
+	// ProviderFailedReason reports the failure set by a provider on a bootstrap or infrastructure object.
+	ProviderFailedReason = "ProviderFailed"
+)
@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing provides helpers to trace the reconcile loops of the Cluster API controllers with OpenTelemetry.
+// Spans are no-ops until Setup installs an exporter.
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/trace/stdout"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/codes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TracerName is the name of the tracer used by the Cluster API controllers.
+	TracerName = "sigs.k8s.io/cluster-api"
+
+	// TraceParentAnnotation holds the W3C traceparent of the reconcile that first claimed an object,
+	// so that the reconciles of its provider can be linked to it.
+	TraceParentAnnotation = "cluster.x-k8s.io/traceparent"
+)
+
+// Exporters supported by Setup.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Setup installs the global trace provider, sending every span to the given exporter.
+// The endpoint is the address of the collector for the otlp exporter, and is ignored otherwise.
+// The returned function flushes the pending spans and must be called before exiting.
+func Setup(exporter, endpoint, serviceName string) (func(), error) {
+	var (
+		processor sdktrace.SpanProcessor
+		stop      = func() {}
+	)
+	switch exporter {
+	case ExporterNone:
+		return stop, nil
+	case ExporterStdout:
+		exp, err := stdout.NewExporter(stdout.Options{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create stdout trace exporter")
+		}
+		processor = sdktrace.NewSimpleSpanProcessor(exp)
+	case ExporterOTLP:
+		exp, err := otlp.NewExporter(otlp.WithInsecure(), otlp.WithAddress(endpoint))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create otlp trace exporter for %q", endpoint)
+		}
+		bsp, err := sdktrace.NewBatchSpanProcessor(exp)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create batch span processor")
+		}
+		processor = bsp
+		stop = func() {
+			_ = exp.Stop()
+		}
+	default:
+		return nil, errors.Errorf("unknown trace exporter %q, must be one of %s, %s or %s", exporter, ExporterNone, ExporterStdout, ExporterOTLP)
+	}
+
+	provider, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithResourceAttributes(key.String("service.name", serviceName)),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create trace provider")
+	}
+	provider.RegisterSpanProcessor(processor)
+	global.SetTraceProvider(provider)
+
+	return func() {
+		// Unregistering the processor flushes the spans it has queued.
+		provider.UnregisterSpanProcessor(processor)
+		stop()
+	}, nil
+}
+
+// Start starts a span named after the operation, as a child of the span carried by ctx if any.
+// The key/value pairs, as passed to a logger, are recorded as attributes of the span.
+func Start(ctx context.Context, name string, keysAndValues ...string) (context.Context, trace.Span) {
+	return global.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attributes(keysAndValues)...))
+}
+
+// End records err on the span, if not nil, and ends it.
+func End(ctx context.Context, span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Unknown))
+	}
+	span.End()
+}
+
+// Link starts a span like Start, linked to the span recorded in the annotations of obj by Inject, if any.
+func Link(ctx context.Context, name string, obj metav1.Object, keysAndValues ...string) (context.Context, trace.Span) {
+	opts := []trace.StartOption{trace.WithAttributes(attributes(keysAndValues)...)}
+	sc := trace.TraceContext{}.Extract(context.Background(), annotationSupplier{obj})
+	if remote := trace.RemoteSpanContextFromContext(sc); remote.IsValid() {
+		opts = append(opts, trace.LinkedTo(remote))
+	}
+	return global.Tracer(TracerName).Start(ctx, name, opts...)
+}
+
+func attributes(keysAndValues []string) []core.KeyValue {
+	attrs := make([]core.KeyValue, 0, len(keysAndValues)/2)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		attrs = append(attrs, key.String(keysAndValues[i], keysAndValues[i+1]))
+	}
+	return attrs
+}
+
+// Inject records the span carried by ctx in the annotations of obj, unless obj already has one
+// or there is no span being recorded.
+// Only the first reconcile claiming an object is recorded: rewriting the annotation on every
+// reconcile would trigger a new reconcile of the owner of obj each time.
+// It returns whether obj was changed.
+func Inject(ctx context.Context, obj metav1.Object) bool {
+	if _, ok := obj.GetAnnotations()[TraceParentAnnotation]; ok {
+		return false
+	}
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return false
+	}
+	trace.TraceContext{}.Inject(ctx, annotationSupplier{obj})
+	return true
+}
+
+// annotationSupplier stores the W3C trace context headers in the annotations of an object.
+type annotationSupplier struct {
+	obj metav1.Object
+}
+
+func (s annotationSupplier) Get(k string) string {
+	if !strings.EqualFold(k, "traceparent") {
+		return ""
+	}
+	return s.obj.GetAnnotations()[TraceParentAnnotation]
+}
+
+func (s annotationSupplier) Set(k, v string) {
+	if !strings.EqualFold(k, "traceparent") {
+		return
+	}
+	annotations := s.obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[TraceParentAnnotation] = v
+	s.obj.SetAnnotations(annotations)
+}
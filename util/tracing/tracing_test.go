@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/api/global"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type recordingSyncer struct {
+	spans []*export.SpanData
+}
+
+func (s *recordingSyncer) ExportSpan(_ context.Context, span *export.SpanData) {
+	s.spans = append(s.spans, span)
+}
+
+func TestInjectAndLink(t *testing.T) {
+	syncer := &recordingSyncer{}
+	provider, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithSyncer(syncer),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	global.SetTraceProvider(provider)
+
+	obj := &corev1.ConfigMap{}
+	if Inject(context.Background(), obj) {
+		t.Errorf("expected no trace context to be injected without a span")
+	}
+
+	ctx, parent := Start(context.Background(), "parent")
+	if !Inject(ctx, obj) {
+		t.Fatalf("expected the trace context to be injected")
+	}
+	traceParent := obj.Annotations[TraceParentAnnotation]
+	if traceParent == "" {
+		t.Fatalf("expected the %s annotation to be set", TraceParentAnnotation)
+	}
+	parent.End()
+
+	ctx, other := Start(context.Background(), "other")
+	if Inject(ctx, obj) || obj.Annotations[TraceParentAnnotation] != traceParent {
+		t.Errorf("expected the existing trace context to be kept")
+	}
+	other.End()
+
+	_, linked := Link(context.Background(), "linked", obj)
+	linked.End()
+
+	span := syncer.spans[len(syncer.spans)-1]
+	if len(span.Links) != 1 {
+		t.Fatalf("expected the span to be linked to the injected span, got links %v", span.Links)
+	}
+	if span.Links[0].TraceID != parent.SpanContext().TraceID || span.Links[0].SpanID != parent.SpanContext().SpanID {
+		t.Errorf("expected a link to %v, got %v", parent.SpanContext(), span.Links[0].SpanContext)
+	}
+}
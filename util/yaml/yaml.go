@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 func ExtractClusterReferences(out *ParseOutput, c *clusterv1.Cluster) (res []*unstructured.Unstructured) {
@@ -150,6 +151,51 @@ func Parse(input ParseInput) (*ParseOutput, error) {
 	return output, nil
 }
 
+// ToUnstructured converts a multi-document YAML into a list of unstructured objects.
+// Empty documents are skipped.
+func ToUnstructured(data []byte) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read yaml")
+		}
+		obj := map[string]interface{}{}
+		if err := sigsyaml.Unmarshal(doc, &obj); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal yaml")
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: obj}
+		if u.GetKind() == "" {
+			return nil, errors.Errorf("object %q has no kind", u.GetName())
+		}
+		objs = append(objs, u)
+	}
+	return objs, nil
+}
+
+// FromUnstructured converts a list of unstructured objects into a multi-document YAML.
+func FromUnstructured(objs []*unstructured.Unstructured) ([]byte, error) {
+	var out bytes.Buffer
+	for i, obj := range objs {
+		data, err := sigsyaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal %s %q", obj.GetKind(), obj.GetName())
+		}
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(data)
+	}
+	return out.Bytes(), nil
+}
+
 type yamlDecoder struct {
 	reader  *yaml.YAMLReader
 	decoder runtime.Decoder
@@ -316,3 +316,32 @@ func createTempFile(contents string) (string, error) {
 	f.WriteString(contents)
 	return f.Name(), nil
 }
+
+func TestToUnstructured(t *testing.T) {
+	objs, err := ToUnstructured([]byte(validCluster + "\n" + validMachines1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(objs) != 3 {
+		t.Fatalf("expected 3 objects, got %d", len(objs))
+	}
+	if objs[0].GetKind() != "Cluster" || objs[2].GetName() != "machine2" {
+		t.Errorf("unexpected objects: %v", objs)
+	}
+
+	out, err := FromUnstructured(objs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	roundTrip, err := ToUnstructured(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roundTrip) != 3 || roundTrip[1].GetName() != "machine1" {
+		t.Errorf("unexpected objects after round trip: %v", roundTrip)
+	}
+
+	if _, err := ToUnstructured([]byte("metadata:\n  name: nokind\n")); err == nil {
+		t.Errorf("expected an error for an object without kind")
+	}
+}